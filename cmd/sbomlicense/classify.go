@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// classifyResult are the licenses found in a file.
+type classifyResult struct {
+	File    string          `json:"file"`
+	Matches []license.Match `json:"matches"`
+}
+
+// runClassify runs the classify command, which identifies the licenses in license text files.
+func runClassify(args []string) int {
+	flags := flag.NewFlagSet("classify", flag.ContinueOnError)
+	var (
+		verbose = flags.Bool("v", false, "Verbose output (debug mode)")
+		format  = flags.String("format", "text", "Output `format`: text or json")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s classify [OPTIONS] <file>...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Identify the licenses in license text files, such as LICENSE or COPYING, by\n")
+		fmt.Fprintf(os.Stderr, "comparing them with the texts of the SPDX License List.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitInvalidArgs
+	}
+
+	logger := setupLogger(*verbose)
+
+	if flags.NArg() == 0 {
+		logger.Error("at least one file is required")
+		flags.Usage()
+		return exitInvalidArgs
+	}
+	if *format != "text" && *format != "json" {
+		logger.Error("invalid format", "format", *format)
+		return exitInvalidArgs
+	}
+
+	results := make([]classifyResult, 0, flags.NArg())
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Error("failed to read file", "file", path, "error", err)
+			return exitRuntimeError
+		}
+		matches := license.Classify(string(data))
+		logger.Debug("classified file", "file", path, "licenses", len(matches))
+		results = append(results, classifyResult{File: path, Matches: matches})
+	}
+
+	var err error
+	if *format == "json" {
+		err = writeJSON(os.Stdout, results)
+	} else {
+		err = writeClassifyText(os.Stdout, results)
+	}
+	if err != nil {
+		logger.Error("failed to write output", "error", err)
+		return exitRuntimeError
+	}
+	return exitSuccess
+}
+
+// writeClassifyText writes the licenses found in the files as a human-readable table.
+func writeClassifyText(w io.Writer, results []classifyResult) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // two spaces between columns
+	if _, err := fmt.Fprintf(table, "FILE\tLICENSE\tCONFIDENCE\tLINES\tMODIFIED\n"); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if len(result.Matches) == 0 {
+			if _, err := fmt.Fprintf(table, "%s\t-\t\t\t\n", result.File); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, match := range result.Matches {
+			modified := "no"
+			if match.Modified {
+				modified = "yes"
+			}
+			confidence := match.Confidence * 100 //nolint:mnd // percent
+			if _, err := fmt.Fprintf(table, "%s\t%s\t%.0f%%\t%d-%d\t%s\n",
+				result.File, match.ID, confidence, match.StartLine, match.EndLine, modified); err != nil {
+				return err
+			}
+		}
+	}
+	return table.Flush()
+}
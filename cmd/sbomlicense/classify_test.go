@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// TestRunClassify tests the classify command with text and JSON output.
+func TestRunClassify(t *testing.T) {
+	// Note: Cannot use t.Parallel() because the command writes to os.Stdout
+
+	mit, _ := license.Text("MIT")
+	apache, _ := license.Text("Apache-2.0")
+	licensePath := writeTestFile(t, "LICENSE", "Copyright (c) 2024 Jane Doe\n\n"+mit+"\n---\n\n"+apache)
+	readmePath := writeTestFile(t, "README.md", "# Example\n\nSee LICENSE.\n")
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runClassify([]string{licensePath, readmePath})
+	})
+	if exitCode != exitSuccess {
+		t.Errorf("runClassify() exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	if !strings.Contains(output, "FILE") ||
+		!strings.Contains(output, "MIT         100%        3-19") ||
+		!strings.Contains(output, "Apache-2.0  100%        23-198") ||
+		!strings.Contains(output, readmePath+"  -") {
+		t.Errorf("runClassify() output = %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runClassify([]string{"-format", "json", licensePath})
+	})
+	if exitCode != exitSuccess {
+		t.Errorf("runClassify() with -format json exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	var results []classifyResult
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatalf("runClassify() output is not JSON: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Matches) != 2 || results[0].Matches[1].ID != "Apache-2.0" {
+		t.Errorf("runClassify() results = %+v", results)
+	}
+}
+
+// TestRunClassify_InvalidArgs tests the classify command with invalid arguments.
+func TestRunClassify_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestFile(t, "LICENSE", "MIT")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no file", args: []string{}, want: exitInvalidArgs},
+		{name: "invalid format", args: []string{"-format", "xml", path}, want: exitInvalidArgs},
+		{name: "missing file", args: []string{path + ".missing"}, want: exitRuntimeError},
+		{name: "help", args: []string{"-h"}, want: exitSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := runClassify(tt.args); got != tt.want {
+				t.Errorf("runClassify(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
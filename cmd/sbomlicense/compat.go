@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/boringbin/sbomlicense/internal/compat"
+	"github.com/boringbin/sbomlicense/internal/enricher"
+)
+
+// runCompat runs the compat command, which checks the dependency licenses of an enriched SBOM against the
+// license the project is distributed under.
+func runCompat(args []string) int {
+	flags := flag.NewFlagSet("compat", flag.ContinueOnError)
+	var (
+		verbose        = flags.Bool("v", false, "Verbose output (debug mode)")
+		projectLicense = flags.String(
+			"license",
+			"",
+			"SPDX `expression` the project is distributed under (default: license of the SBOM's root component)",
+		)
+		format     = flags.String("format", "text", "Output `format`: text or json")
+		matrixPath = flags.String("matrix", "", "Use the compatibility matrix JSON `file` instead of the embedded one")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s compat [OPTIONS] <sbom-file>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Check whether the dependency licenses of an enriched SBOM are compatible with the\n")
+		fmt.Fprintf(os.Stderr, "project license. Exits with code %d if a dependency is incompatible.\n\n",
+			exitLicenseCheckFailed)
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitInvalidArgs
+	}
+
+	logger := setupLogger(*verbose)
+
+	if flags.NArg() != 1 {
+		logger.Error("exactly one SBOM file is required")
+		flags.Usage()
+		return exitInvalidArgs
+	}
+	if *format != "text" && *format != "json" {
+		logger.Error("invalid format", "format", *format)
+		return exitInvalidArgs
+	}
+
+	// Load the compatibility matrix
+	matrix, err := loadMatrix(*matrixPath)
+	if err != nil {
+		logger.Error("failed to load compatibility matrix", "error", err)
+		return exitInvalidArgs
+	}
+
+	// Read the SBOM
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		logger.Error("failed to read file", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+	inventory, err := enricher.ReadInventory(data)
+	if err != nil {
+		logger.Error("failed to read SBOM", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+
+	// Determine the project license
+	project := *projectLicense
+	if project == "" && inventory.Root != nil {
+		project = inventory.Root.License
+		logger.Debug("using license of the root component", "name", inventory.Root.Name, "license", project)
+	}
+	if project == "" {
+		logger.Error("project license is unknown: the SBOM has no licensed root component, use -license")
+		return exitInvalidArgs
+	}
+
+	result, err := matrix.Check(project, inventory.Items)
+	if err != nil {
+		logger.Error("invalid project license", "license", project, "error", err)
+		return exitInvalidArgs
+	}
+
+	if *format == "json" {
+		err = writeJSON(os.Stdout, result)
+	} else {
+		err = writeCompatText(os.Stdout, result)
+	}
+	if err != nil {
+		logger.Error("failed to write output", "error", err)
+		return exitRuntimeError
+	}
+
+	if !result.Compatible {
+		return exitLicenseCheckFailed
+	}
+	return exitSuccess
+}
+
+// loadMatrix loads the compatibility matrix from path, or the embedded matrix if path is empty.
+func loadMatrix(path string) (*compat.Matrix, error) {
+	if path == "" {
+		return compat.DefaultMatrix()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read matrix: %w", err)
+	}
+	return compat.ParseMatrix(data)
+}
+
+// writeCompatText writes the compatibility result as a human-readable table.
+func writeCompatText(w io.Writer, result *compat.Result) error {
+	var err error
+	out := w
+	printf := func(format string, a ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(out, format, a...)
+		}
+	}
+
+	printf("Project license: %s\n\n", result.ProjectLicense)
+	if len(result.Findings) > 0 {
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // two spaces between columns
+		out = table
+		printf("VERDICT\tNAME\tVERSION\tLICENSE\tREASON\n")
+		for _, finding := range result.Findings {
+			printf("%s\t%s\t%s\t%s\t%s\n", finding.Verdict, finding.Name, finding.Version, finding.License, finding.Reason)
+		}
+		if err == nil {
+			err = table.Flush()
+		}
+		out = w
+		printf("\n")
+	}
+	printf("%d compatible, %d conditional, %d unknown, %d incompatible\n",
+		result.Counts[compat.VerdictCompatible],
+		result.Counts[compat.VerdictConditional],
+		result.Counts[compat.VerdictUnknown],
+		result.Counts[compat.VerdictIncompatible],
+	)
+	return err
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/compat"
+)
+
+// compatTestSBOM is an enriched CycloneDX SBOM of an Apache-2.0 project.
+const compatTestSBOM = `{"bomFormat": "CycloneDX", "specVersion": "1.6",
+	"metadata": {"component": {"bom-ref": "app", "name": "app", "licenses": [{"license": {"id": "Apache-2.0"}}]}},
+	"components": [
+		{"bom-ref": "a", "name": "a", "version": "1.0.0", "licenses": [{"license": {"id": "MIT"}}]},
+		{"bom-ref": "b", "name": "b", "version": "2.0.0", "licenses": [{"expression": "GPL-2.0-only"}]}
+	]}`
+
+// writeTestFile writes content to a file in a temporary directory and returns its path.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// captureStdout runs fn with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+
+	fn()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+	return <-done
+}
+
+// TestRunCompat tests the compat command with the root component's license and with -license.
+func TestRunCompat(t *testing.T) {
+	// Note: Cannot use t.Parallel() because the command writes to os.Stdout
+
+	path := writeTestFile(t, "sbom.json", compatTestSBOM)
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runCompat([]string{path})
+	})
+	if exitCode != exitLicenseCheckFailed {
+		t.Errorf("runCompat() exit code = %d, want %d", exitCode, exitLicenseCheckFailed)
+	}
+	if !strings.Contains(output, "Project license: Apache-2.0") ||
+		!strings.Contains(output, "incompatible  b") ||
+		!strings.Contains(output, "1 compatible, 0 conditional, 0 unknown, 1 incompatible") {
+		t.Errorf("runCompat() output = %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runCompat([]string{"-license", "GPL-2.0-or-later", "-format", "json", path})
+	})
+	if exitCode != exitSuccess {
+		t.Errorf("runCompat() with -license exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	var result compat.Result
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("runCompat() JSON output invalid: %v", err)
+	}
+	if result.ProjectLicense != "GPL-2.0-or-later" || !result.Compatible || len(result.Findings) != 1 ||
+		result.Findings[0].Verdict != compat.VerdictConditional {
+		t.Errorf("runCompat() result = %+v, want b conditional", result)
+	}
+}
+
+// TestRunCompat_InvalidArgs tests the compat command with invalid arguments.
+func TestRunCompat_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestFile(t, "sbom.json", compatTestSBOM)
+	noRoot := writeTestFile(t, "no-root.json", `{"bomFormat": "CycloneDX", "specVersion": "1.6", "components": []}`)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no file", args: nil},
+		{name: "invalid format", args: []string{"-format", "xml", path}},
+		{name: "unknown project license", args: []string{"-license", "LicenseRef-acme", path}},
+		{name: "no root component", args: []string{noRoot}},
+		{name: "missing matrix", args: []string{"-matrix", filepath.Join(t.TempDir(), "missing.json"), path}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if exitCode := runCompat(tt.args); exitCode != exitInvalidArgs {
+				t.Errorf("runCompat() exit code = %d, want %d", exitCode, exitInvalidArgs)
+			}
+		})
+	}
+}
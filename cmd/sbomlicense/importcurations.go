@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+)
+
+// runImportCurations runs the import-curations command, which converts the curations of other tools into a
+// curations file.
+func runImportCurations(args []string) int {
+	flags := flag.NewFlagSet("import-curations", flag.ContinueOnError)
+	var (
+		verbose = flags.Bool("v", false, "Verbose output (debug mode)")
+		from    = flags.String("from", "", "Input `format`: ort or clearlydefined (required)")
+		output  = flags.String("o", "", "Write the curations file to this `path` (default: stdout)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s import-curations -from <format> [OPTIONS] <file>...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Convert OSS Review Toolkit curations.yml files or ClearlyDefined curation YAML\n")
+		fmt.Fprintf(os.Stderr, "files into a curations file. Curations that cannot be translated are reported.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitInvalidArgs
+	}
+
+	logger := setupLogger(*verbose)
+
+	if flags.NArg() == 0 {
+		logger.Error("at least one curations file is required")
+		flags.Usage()
+		return exitInvalidArgs
+	}
+	var importer func(data []byte) (*curation.Import, error)
+	switch *from {
+	case "ort":
+		importer = curation.ImportORT
+	case "clearlydefined":
+		importer = curation.ImportClearlyDefined
+	default:
+		logger.Error("invalid input format", "format", *from)
+		return exitInvalidArgs
+	}
+
+	var (
+		file    curation.File
+		skipped int
+	)
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Error("failed to read file", "file", path, "error", err)
+			return exitRuntimeError
+		}
+		result, err := importer(data)
+		if err != nil {
+			logger.Error("failed to import curations", "file", path, "error", err)
+			return exitRuntimeError
+		}
+		logger.Debug("imported curations", "file", path, "curations", len(result.Curations))
+
+		file.Curations = append(file.Curations, result.Curations...)
+		for _, s := range result.Skipped {
+			fmt.Fprintf(os.Stderr, "%s: skipped %s: %s\n", path, s.ID, s.Reason)
+		}
+		skipped += len(result.Skipped)
+	}
+	fmt.Fprintf(os.Stderr, "%d curations imported, %d skipped\n", len(file.Curations), skipped)
+
+	data, err := yaml.Marshal(&file)
+	if err != nil {
+		logger.Error("failed to encode curations", "error", err)
+		return exitRuntimeError
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, reportFileMode)
+	}
+	if err != nil {
+		logger.Error("failed to write curations", "error", err)
+		return exitRuntimeError
+	}
+	return exitSuccess
+}
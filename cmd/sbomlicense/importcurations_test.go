@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+)
+
+// TestRunImportCurations tests that imported curations are written as a valid curations file.
+func TestRunImportCurations(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestFile(t, "curations.yml", `
+- id: "NPM::left-pad:1.3.0"
+  curations:
+    concluded_license: "MIT"
+- id: "Unmanaged::project:1.0"
+  curations:
+    concluded_license: "MIT"
+`)
+	output := filepath.Join(t.TempDir(), "curations.yaml")
+
+	if exitCode := runImportCurations([]string{"-from", "ort", "-o", output, path}); exitCode != exitSuccess {
+		t.Fatalf("runImportCurations() exit code = %d, want %d", exitCode, exitSuccess)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	f, err := curation.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v, output = %s", err, data)
+	}
+	if c, ok := f.Find("pkg:npm/left-pad@1.3.0"); !ok || c.License != "MIT" || len(f.Curations) != 1 {
+		t.Errorf("runImportCurations() output = %s", data)
+	}
+}
+
+// TestRunImportCurations_InvalidArgs tests the import-curations command with invalid arguments.
+func TestRunImportCurations_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestFile(t, "curations.yml", "[]")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no file", args: []string{"-from", "ort"}, want: exitInvalidArgs},
+		{name: "no format", args: []string{path}, want: exitInvalidArgs},
+		{name: "invalid format", args: []string{"-from", "scancode", path}, want: exitInvalidArgs},
+		{name: "missing file", args: []string{"-from", "ort", path + ".missing"}, want: exitRuntimeError},
+		{name: "help", args: []string{"-h"}, want: exitSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := runImportCurations(tt.args); got != tt.want {
+				t.Errorf("runImportCurations(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
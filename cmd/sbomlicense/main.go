@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -13,7 +15,9 @@ import (
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
+	"github.com/boringbin/sbomlicense/internal/curation"
 	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/policy"
 	"github.com/boringbin/sbomlicense/internal/provider"
 	"github.com/boringbin/sbomlicense/internal/sbom"
 	"github.com/boringbin/sbomlicense/internal/version"
@@ -29,6 +33,10 @@ const (
 	exitInvalidArgs = 1
 	// exitRuntimeError is the exit code for runtime error.
 	exitRuntimeError = 3
+	// exitLicenseCheckFailed is the exit code when licenses violate the policy or are incompatible.
+	exitLicenseCheckFailed = 4
+	// reportFileMode is the file mode for files written by the CLI.
+	reportFileMode = 0o644
 )
 
 func main() {
@@ -36,13 +44,65 @@ func main() {
 }
 
 func run() int {
+	// Run the subcommand, if any; otherwise the arguments are for enrichment
+	if len(os.Args) > 1 {
+		if command := subcommand(os.Args[1]); command != nil {
+			return command(os.Args[2:])
+		}
+	}
+
 	var (
 		verbose     = flag.Bool("v", false, "Verbose output (debug mode)")
 		showVersion = flag.Bool("version", false, "Show version and exit")
 		parallel    = flag.Int("parallel", 10, "Number of concurrent workers for enrichment")
 		email       = flag.String("email", "", "Email for polite pool (optional)")
 		timeout     = flag.Duration("timeout", 5*time.Minute, "Timeout for enrichment operation")
+		outFormat   = flag.String(
+			"output-format",
+			"",
+			"Convert the enriched SBOM to another format: spdx-2.3 or cyclonedx-1.6 (default: same as input)",
+		)
+		licenseTexts = flag.String(
+			"license-texts",
+			"",
+			"Embed full license texts into the enriched SBOM with this `encoding`: plain or base64 (optional)",
+		)
+		reportPath    = flag.String("report", "", "Write a JSON enrichment report to this `file` (optional)")
+		policyPath    = flag.String("policy", "", "Check licenses against the YAML or JSON policy `file` (optional)")
+		curationsPath = flag.String(
+			"curations",
+			"",
+			"Set licenses by hand with the YAML or JSON curations `file`, consulted before any lookup (optional)",
+		)
+		providersPath = flag.String(
+			"providers",
+			"",
+			"Look up licenses with the chain of providers in the YAML or JSON `file` (default: Ecosyste.ms)",
+		)
+		rateBurst = flag.Int("rate-burst", 1, "Number of Ecosyste.ms API requests that can be made at once")
+		rateLimit = flag.Float64(
+			"rate-limit",
+			0,
+			"Maximum Ecosyste.ms API requests per second (default: only the API headers)",
+		)
+		breakerThreshold = flag.Int(
+			"breaker-threshold",
+			5,
+			"Consecutive failures of a provider that stop lookups to it until the cooldown (0 disables)",
+		)
+		breakerCooldown = flag.Duration(
+			"breaker-cooldown",
+			30*time.Second,
+			"Time lookups to a failing provider are stopped before one probes it again",
+		)
+		includes stringList
+		excludes stringList
+		private  stringList
 	)
+	flag.Var(&includes, "include", "Only enrich items matching this `filter`, e.g. type=npm (repeatable)")
+	flag.Var(&private, "private",
+		"Never send purls matching this `pattern` to external providers, e.g. pkg:npm/@acme/* (repeatable)")
+	flag.Var(&excludes, "exclude", "Skip items matching this `filter`, e.g. type=github,namespace=actions (repeatable)")
 
 	// Customize usage message
 	flag.CommandLine.Usage = printUsage
@@ -68,6 +128,60 @@ func run() int {
 		return exitInvalidArgs
 	}
 
+	// Validate the output format
+	outputFormat, err := enricher.ParseOutputFormat(*outFormat)
+	if err != nil {
+		logger.Error("invalid output format", "error", err)
+		return exitInvalidArgs
+	}
+
+	// Validate the license text encoding
+	licenseTextEncoding, err := enricher.ParseLicenseTextEncoding(*licenseTexts)
+	if err != nil {
+		logger.Error("invalid license text encoding", "error", err)
+		return exitInvalidArgs
+	}
+
+	// Parse the filters
+	filter, err := parseFilter(includes, excludes)
+	if err != nil {
+		logger.Error("invalid filter", "error", err)
+		return exitInvalidArgs
+	}
+
+	// Load the license policy
+	var licensePolicy *policy.Policy
+	if *policyPath != "" {
+		licensePolicy, err = policy.Load(*policyPath)
+		if err != nil {
+			logger.Error("invalid policy", "path", *policyPath, "error", err)
+			return exitInvalidArgs
+		}
+	}
+
+	// Load the curations
+	var curations provider.Curator
+	if *curationsPath != "" {
+		store, curationsErr := curation.Open(*curationsPath)
+		if curationsErr != nil {
+			logger.Error("invalid curations", "path", *curationsPath, "error", curationsErr)
+			return exitInvalidArgs
+		}
+		curations = store
+		logger.Debug("loaded curations", "path", *curationsPath, "curations", store.Len())
+	}
+
+	// Load the provider chain
+	var chainConfig *provider.ChainConfig
+	if *providersPath != "" {
+		chainConfig, err = provider.LoadChainConfig(*providersPath)
+		if err != nil {
+			logger.Error("invalid providers", "path", *providersPath, "error", err)
+			return exitInvalidArgs
+		}
+		logger.Debug("loaded providers", "path", *providersPath, "providers", len(chainConfig.Providers))
+	}
+
 	// Expand paths to get list of files
 	files := expandPaths(args, logger)
 
@@ -100,30 +214,114 @@ func run() int {
 	cacheInstance := cache.NewMemoryCache()
 	logger.Debug("using in-memory cache")
 
-	// Initialize the ecosystems provider
-	service := provider.NewClient(provider.ClientOptions{
-		Email: *email,
+	// Share one rate limiter for the ecosystems API between all workers
+	rateLimiter := provider.NewRateLimiter(provider.RateLimiterOptions{
+		Name:   string(provider.KindEcosystems),
+		Rate:   *rateLimit,
+		Burst:  *rateBurst,
+		Logger: logger,
 	})
 
+	// Stop looking up licenses from failing providers for a while, instead of waiting for each lookup to time out
+	breakerOpts := provider.BreakerOptions{
+		Name:      string(provider.KindEcosystems),
+		Threshold: *breakerThreshold,
+		Cooldown:  *breakerCooldown,
+		Logger:    logger,
+	}
+	if breakerOpts.Threshold <= 0 {
+		breakerOpts.Threshold = -1
+	}
+
+	// Initialize the provider chain, or the ecosystems provider, guarded against leaking private purls
+	var service provider.Provider
+	if chainConfig != nil {
+		service, err = chainConfig.Chain(provider.ChainOptions{
+			Email:       *email,
+			Private:     private,
+			RateLimiter: rateLimiter,
+			Breaker:     breakerOpts,
+		})
+	} else {
+		service, err = provider.NewGuard(provider.NewBreaker(provider.NewClient(provider.ClientOptions{
+			Email:       *email,
+			RateLimiter: rateLimiter,
+		}), breakerOpts), private)
+	}
+	if err != nil {
+		logger.Error("invalid private pattern", "error", err)
+		return exitInvalidArgs
+	}
+
 	// Process the file
-	enrichedSBOM, err := processFile(ctx, files[0], service, cacheInstance, *parallel, logger)
+	report := &enricher.Report{}
+	enrichedSBOM, err := processFile(ctx, files[0], service, cacheInstance, enricher.Options{
+		Logger:       logger,
+		Parallelism:  *parallel,
+		OutputFormat: outputFormat,
+		LicenseTexts: licenseTextEncoding,
+		Filter:       filter,
+		Curations:    curations,
+		Report:       report,
+	})
 	if err != nil {
 		logger.Error("failed to process file", "file", files[0], "error", err)
 		return exitRuntimeError
 	}
 
+	// Write the enrichment report if requested
+	if *reportPath != "" {
+		if reportErr := writeJSONFile(*reportPath, report); reportErr != nil {
+			logger.Error("failed to write report", "path", *reportPath, "error", reportErr)
+			return exitRuntimeError
+		}
+	}
+
 	// Write enriched SBOM to stdout
 	if _, writeErr := os.Stdout.Write(enrichedSBOM); writeErr != nil {
 		logger.Error("failed to write output", "error", writeErr)
 		return exitRuntimeError
 	}
 
+	// Check the enriched SBOM against the license policy
+	if licensePolicy != nil {
+		passed, policyErr := checkPolicy(enrichedSBOM, licensePolicy, logger)
+		if policyErr != nil {
+			logger.Error("failed to check policy", "error", policyErr)
+			return exitRuntimeError
+		}
+		if !passed {
+			return exitLicenseCheckFailed
+		}
+	}
+
 	return exitSuccess
 }
 
+// subcommand returns the function running the named subcommand, or nil if there is no such subcommand.
+func subcommand(name string) func(args []string) int {
+	switch name {
+	case "classify":
+		return runClassify
+	case "compat":
+		return runCompat
+	case "notice":
+		return runNotice
+	case "export-worksheet":
+		return runExportWorksheet
+	case "import-curations":
+		return runImportCurations
+	case "import-worksheet":
+		return runImportWorksheet
+	default:
+		return nil
+	}
+}
+
 // printUsage prints the usage message.
 func printUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] <sbom-file>\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] <sbom-file>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s <command> [OPTIONS] <sbom-file>\n\n", os.Args[0])
 	fmt.Fprintf(os.Stderr, "Enrich SBOM files with license information.\n\n")
 	fmt.Fprintf(os.Stderr, "The enriched SBOM is written to stdout.\n\n")
 	fmt.Fprintf(os.Stderr, "This CLI tool is designed for local, one-off enrichment with in-memory caching.\n")
@@ -133,6 +331,19 @@ func printUsage() {
 		os.Stderr,
 		"  sbom-file           Path to a single SBOM file (SPDX or CycloneDX JSON format)\n\n",
 	)
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  classify            Identify the licenses in license text files\n")
+	fmt.Fprintf(os.Stderr, "  compat              Check dependency licenses against the project license\n")
+	fmt.Fprintf(os.Stderr, "  export-worksheet    Export the components without license to a CSV worksheet\n")
+	fmt.Fprintf(os.Stderr, "  import-curations    Convert ORT or ClearlyDefined curations into a curations file\n")
+	fmt.Fprintf(os.Stderr, "  import-worksheet    Apply a completed worksheet to an SBOM or convert it into curations\n")
+	fmt.Fprintf(os.Stderr, "  notice              Generate a third-party attribution notice with license texts\n\n")
+	fmt.Fprintf(os.Stderr, "Run '%s <command> -h' for the options of a command.\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Exit codes:\n")
+	fmt.Fprintf(os.Stderr, "  0  success\n")
+	fmt.Fprintf(os.Stderr, "  1  invalid arguments\n")
+	fmt.Fprintf(os.Stderr, "  3  runtime error\n")
+	fmt.Fprintf(os.Stderr, "  4  license policy violation (with -policy) or incompatible license (compat)\n\n")
 	fmt.Fprintf(os.Stderr, "Options:\n")
 	flag.PrintDefaults()
 }
@@ -188,13 +399,13 @@ func expandPaths(paths []string, logger *slog.Logger) []string {
 }
 
 // processFile reads, detects format, parses, and enriches a single SBOM file.
+// The SBOM field of opts is set to the file contents.
 func processFile(
 	ctx context.Context,
 	filename string,
 	provider provider.Provider,
 	cacheInstance cache.Cache,
-	parallelism int,
-	logger *slog.Logger,
+	opts enricher.Options,
 ) ([]byte, error) {
 	// Read file
 	data, err := os.ReadFile(filename)
@@ -208,7 +419,7 @@ func processFile(
 		return nil, fmt.Errorf("detect format: %w", err)
 	}
 
-	logger.DebugContext(ctx, "detected SBOM format", "file", filename, "format", format)
+	opts.Logger.DebugContext(ctx, "detected SBOM format", "file", filename, "format", format)
 
 	// Select license enrichment service based on format
 	var licenseEnrichmentService enricher.Enricher
@@ -223,14 +434,107 @@ func processFile(
 	}
 
 	// Enrich the SBOM
-	enriched, err := licenseEnrichmentService.Enrich(ctx, enricher.Options{
-		SBOM:        data,
-		Logger:      logger,
-		Parallelism: parallelism,
-	})
+	opts.SBOM = data
+	enriched, err := licenseEnrichmentService.Enrich(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("enrich SBOM: %w", err)
 	}
 
 	return enriched, nil
 }
+
+// checkPolicy checks the licenses of the enriched SBOM against the policy and logs every finding.
+// It returns false if the policy is violated.
+func checkPolicy(enrichedSBOM []byte, licensePolicy *policy.Policy, logger *slog.Logger) (bool, error) {
+	inventory, err := enricher.ReadInventory(enrichedSBOM)
+	if err != nil {
+		return false, fmt.Errorf("read inventory: %w", err)
+	}
+
+	result := licensePolicy.Evaluate(inventory.Items)
+	for _, finding := range result.Findings {
+		level := slog.LevelWarn
+		if finding.Decision == policy.DecisionDeny || licensePolicy.FailOnReview {
+			level = slog.LevelError
+		}
+		logger.Log(context.Background(), level, "license policy "+string(finding.Decision),
+			"id", finding.ID,
+			"purl", finding.Purl,
+			"license", finding.License,
+			"reason", finding.Reason,
+		)
+	}
+	logger.Info("license policy checked",
+		"allowed", result.Allowed,
+		"review", result.Review,
+		"denied", result.Denied,
+		"passed", result.Passed,
+	)
+	return result.Passed, nil
+}
+
+// stringList is a flag.Value collecting the values of a repeatable flag.
+type stringList []string
+
+// String returns the values joined by commas.
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set appends a value.
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseFilter builds an enrichment filter from the -include and -exclude flags.
+// It returns nil if no filters were given.
+func parseFilter(includes, excludes []string) (*enricher.Filter, error) {
+	if len(includes) == 0 && len(excludes) == 0 {
+		return nil, nil //nolint:nilnil // no filter means enrich everything
+	}
+
+	filter := &enricher.Filter{}
+	for _, include := range includes {
+		rule, err := enricher.ParseFilterRule(include)
+		if err != nil {
+			return nil, err
+		}
+		filter.Include = append(filter.Include, rule)
+	}
+	for _, exclude := range excludes {
+		rule, err := enricher.ParseFilterRule(exclude)
+		if err != nil {
+			return nil, err
+		}
+		filter.Exclude = append(filter.Exclude, rule)
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// writeJSONFile writes v as indented JSON to the file at path.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	if writeErr := os.WriteFile(path, append(data, '\n'), reportFileMode); writeErr != nil {
+		return fmt.Errorf("write file: %w", writeErr)
+	}
+	return nil
+}
+
+// writeJSON writes v as indented JSON to w.
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	if _, writeErr := w.Write(append(data, '\n')); writeErr != nil {
+		return fmt.Errorf("write output: %w", writeErr)
+	}
+	return nil
+}
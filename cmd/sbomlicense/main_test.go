@@ -654,3 +654,252 @@ func minInt(a, b int) int {
 	}
 	return b
 }
+
+// TestRun_InvalidOutputFormat tests the run function with an unsupported output format.
+func TestRun_InvalidOutputFormat(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global flag.CommandLine
+
+	// Save and restore os.Args and flag.CommandLine
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"sbomlicense", "-output-format", "xml", "../../testdata/example-spdx.json"}
+
+	// Capture stderr
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	exitCode := run()
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+
+	if exitCode != exitInvalidArgs {
+		t.Errorf("run() with invalid output format returned exit code %d, want %d", exitCode, exitInvalidArgs)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	if !strings.Contains(buf.String(), "invalid output format") {
+		t.Errorf("run() stderr should mention invalid output format, got: %s", buf.String())
+	}
+}
+
+// TestParseFilter tests building a filter from the -include and -exclude flags.
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := parseFilter(nil, nil)
+	if err != nil || filter != nil {
+		t.Errorf("parseFilter(nil, nil) = %v, %v, want nil, nil", filter, err)
+	}
+
+	filter, err = parseFilter([]string{"type=npm"}, []string{"type=github", "scope=excluded"})
+	if err != nil {
+		t.Fatalf("parseFilter() error = %v", err)
+	}
+	if len(filter.Include) != 1 || len(filter.Exclude) != 2 {
+		t.Errorf("parseFilter() = %+v, want 1 include and 2 excludes", filter)
+	}
+
+	if _, err = parseFilter(nil, []string{"name=("}); err == nil {
+		t.Error("parseFilter() with invalid regex error = nil, want error")
+	}
+	if _, err = parseFilter([]string{"bogus"}, nil); err == nil {
+		t.Error("parseFilter() with invalid rule error = nil, want error")
+	}
+}
+
+// TestRun_Report tests that the run function writes the enrichment report.
+func TestRun_Report(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global flag.CommandLine
+
+	// Save and restore os.Args and flag.CommandLine
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	// Exclude everything so no network lookups are made
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	os.Args = []string{
+		"sbomlicense", "-report", reportPath, "-exclude", "name=.*", "../../testdata/example-cyclonedx.json",
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	exitCode := run()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if exitCode != exitSuccess {
+		t.Fatalf("run() with -report returned exit code %d, want %d", exitCode, exitSuccess)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if !strings.Contains(string(data), `"status": "skipped (filtered)"`) {
+		t.Errorf("report = %s, want filtered items", data)
+	}
+}
+
+// TestRun_Curations tests that curated licenses are applied and marked in the report.
+func TestRun_Curations(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global flag.CommandLine
+
+	// Save and restore os.Args and flag.CommandLine
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	dir := t.TempDir()
+	curationsPath := filepath.Join(dir, "curations.yaml")
+	curations := "curations:\n  - purl: pkg:npm/lodash\n    license: MIT\n    reviewer: jane@example.com\n"
+	if err := os.WriteFile(curationsPath, []byte(curations), 0o600); err != nil {
+		t.Fatalf("failed to write curations: %v", err)
+	}
+
+	// Only enrich the curated component so no network lookups are made
+	reportPath := filepath.Join(dir, "report.json")
+	os.Args = []string{
+		"sbomlicense", "-curations", curationsPath, "-report", reportPath, "-include", "name=lodash",
+		"../../testdata/example-cyclonedx.json",
+	}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	exitCode := run()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if exitCode != exitSuccess {
+		t.Fatalf("run() with -curations returned exit code %d, want %d", exitCode, exitSuccess)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if !strings.Contains(string(data), `"source": "curation"`) ||
+		!strings.Contains(string(data), `"reviewer": "jane@example.com"`) {
+		t.Errorf("report = %s, want curated lodash", data)
+	}
+}
+
+// TestRun_PolicyViolation tests that the run function exits with a distinct code when the policy is violated.
+func TestRun_PolicyViolation(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global flag.CommandLine
+
+	// Save and restore os.Args and flag.CommandLine
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	// The example components have no licenses, which this policy denies
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policyPath, []byte("allow: [MIT]\nmissing: deny\n"), 0o600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+
+	// Exclude everything so no network lookups are made
+	os.Args = []string{
+		"sbomlicense", "-policy", policyPath, "-exclude", "name=.*", "../../testdata/example-cyclonedx.json",
+	}
+
+	// Capture stdout and stderr
+	oldStdout := os.Stdout
+	oldStderr := os.Stderr
+	_, stdoutW, _ := os.Pipe()
+	stderrR, stderrW, _ := os.Pipe()
+	os.Stdout = stdoutW
+	os.Stderr = stderrW
+
+	exitCode := run()
+
+	_ = stdoutW.Close()
+	_ = stderrW.Close()
+	os.Stdout = oldStdout
+	os.Stderr = oldStderr
+
+	if exitCode != exitLicenseCheckFailed {
+		t.Errorf("run() with policy violation returned exit code %d, want %d", exitCode, exitLicenseCheckFailed)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, stderrR)
+
+	if !strings.Contains(buf.String(), "license policy deny") {
+		t.Errorf("run() stderr should report the denied components, got: %s", buf.String())
+	}
+}
+
+// TestRun_InvalidPolicy tests the run function with a policy file that cannot be loaded.
+func TestRun_InvalidPolicy(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global flag.CommandLine
+
+	// Save and restore os.Args and flag.CommandLine
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policyPath, []byte("default: block\n"), 0o600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	os.Args = []string{"sbomlicense", "-policy", policyPath, "../../testdata/example-cyclonedx.json"}
+
+	// Capture stderr
+	oldStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+
+	exitCode := run()
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+
+	if exitCode != exitInvalidArgs {
+		t.Errorf("run() with invalid policy returned exit code %d, want %d", exitCode, exitInvalidArgs)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/notice"
+)
+
+// defaultNoticeDir is the output directory of the dir format.
+const defaultNoticeDir = "THIRD_PARTY_LICENSES"
+
+// runNotice runs the notice command, which generates the third-party attribution notice of an enriched SBOM.
+func runNotice(args []string) int {
+	flags := flag.NewFlagSet("notice", flag.ContinueOnError)
+	var (
+		verbose = flags.Bool("v", false, "Verbose output (debug mode)")
+		format  = flags.String("format", "markdown", "Output `format`: markdown, html, text or dir")
+		output  = flags.String(
+			"o",
+			"",
+			"Write the notice to this `path` (default: stdout, or "+defaultNoticeDir+" for the dir format)",
+		)
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s notice [OPTIONS] <sbom-file>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Generate the third-party attribution notice of an enriched SBOM: the components\n")
+		fmt.Fprintf(os.Stderr, "grouped by license, their copyright lines and the full license texts.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitInvalidArgs
+	}
+
+	logger := setupLogger(*verbose)
+
+	if flags.NArg() != 1 {
+		logger.Error("exactly one SBOM file is required")
+		flags.Usage()
+		return exitInvalidArgs
+	}
+	var write func(n *notice.Notice, w io.Writer) error
+	switch *format {
+	case "markdown":
+		write = (*notice.Notice).WriteMarkdown
+	case "html":
+		write = (*notice.Notice).WriteHTML
+	case "text":
+		write = (*notice.Notice).WriteText
+	case "dir":
+	default:
+		logger.Error("invalid format", "format", *format)
+		return exitInvalidArgs
+	}
+
+	// Read the SBOM
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		logger.Error("failed to read file", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+	inventory, err := enricher.ReadInventory(data)
+	if err != nil {
+		logger.Error("failed to read SBOM", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+
+	n := notice.Build(inventory)
+	logger.Debug("built notice", "licenses", len(n.Groups), "texts", len(n.Texts))
+
+	if err = writeNotice(n, write, *output); err != nil {
+		logger.Error("failed to write notice", "error", err)
+		return exitRuntimeError
+	}
+	return exitSuccess
+}
+
+// writeNotice writes the notice with write to the output path or stdout, or as a directory if write is nil.
+func writeNotice(n *notice.Notice, write func(n *notice.Notice, w io.Writer) error, output string) error {
+	if write == nil {
+		if output == "" {
+			output = defaultNoticeDir
+		}
+		return n.WriteDir(output)
+	}
+	if output == "" {
+		return write(n, os.Stdout)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create output: %w", err)
+	}
+	if err = write(n, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunNotice tests the notice command with output to stdout, a file and a directory.
+func TestRunNotice(t *testing.T) {
+	// Note: Cannot use t.Parallel() because the command writes to os.Stdout
+
+	path := writeTestFile(t, "sbom.json", compatTestSBOM)
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runNotice([]string{path})
+	})
+	if exitCode != exitSuccess {
+		t.Errorf("runNotice() exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	if !strings.Contains(output, "# Third-party licenses of app\n") ||
+		!strings.Contains(output, "## GPL-2.0-only\n\n- b 2.0.0\n") ||
+		!strings.Contains(output, "### MIT\n") {
+		t.Errorf("runNotice() output = %s", output)
+	}
+
+	htmlPath := filepath.Join(t.TempDir(), "NOTICE.html")
+	if exitCode = runNotice([]string{"-format", "html", "-o", htmlPath, path}); exitCode != exitSuccess {
+		t.Errorf("runNotice() with -o exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	if html, err := os.ReadFile(htmlPath); err != nil || !strings.Contains(string(html), "<h2>MIT</h2>") {
+		t.Errorf("runNotice() HTML output = %s, error = %v", html, err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "licenses")
+	if exitCode = runNotice([]string{"-format", "dir", "-o", dir, path}); exitCode != exitSuccess {
+		t.Errorf("runNotice() with dir format exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	for _, name := range []string{"GPL-2.0-only.txt", "MIT.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("runNotice() did not write %s: %v", name, err)
+		}
+	}
+}
+
+// TestRunNotice_InvalidArgs tests the notice command with invalid arguments.
+func TestRunNotice_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestFile(t, "sbom.json", compatTestSBOM)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no file", args: nil, want: exitInvalidArgs},
+		{name: "invalid format", args: []string{"-format", "pdf", path}, want: exitInvalidArgs},
+		{name: "missing file", args: []string{filepath.Join(t.TempDir(), "missing.json")}, want: exitRuntimeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if exitCode := runNotice(tt.args); exitCode != tt.want {
+				t.Errorf("runNotice() exit code = %d, want %d", exitCode, tt.want)
+			}
+		})
+	}
+}
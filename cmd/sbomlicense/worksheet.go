@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/worksheet"
+)
+
+// offlineProvider is a provider without any license information, so that only the licenses of a worksheet are
+// applied to an SBOM.
+type offlineProvider struct{}
+
+// Get returns no license.
+func (offlineProvider) Get(context.Context, string) (string, error) {
+	return "", nil
+}
+
+// runExportWorksheet runs the export-worksheet command, which writes the components of an enriched SBOM without
+// license information to a CSV worksheet.
+func runExportWorksheet(args []string) int {
+	flags := flag.NewFlagSet("export-worksheet", flag.ContinueOnError)
+	var (
+		verbose = flags.Bool("v", false, "Verbose output (debug mode)")
+		output  = flags.String("o", "", "Write the worksheet to this `file` (default: stdout)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export-worksheet [OPTIONS] <sbom-file>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Write the components of an enriched SBOM without license information to a CSV\n")
+		fmt.Fprintf(os.Stderr, "worksheet with the columns name, version, purl, homepage and a blank license.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitInvalidArgs
+	}
+
+	logger := setupLogger(*verbose)
+
+	if flags.NArg() != 1 {
+		logger.Error("exactly one SBOM file is required")
+		flags.Usage()
+		return exitInvalidArgs
+	}
+
+	// Read the SBOM
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		logger.Error("failed to read file", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+	inventory, err := enricher.ReadInventory(data)
+	if err != nil {
+		logger.Error("failed to read SBOM", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+
+	rows := worksheet.Export(inventory)
+	logger.Debug("exported worksheet", "components", len(inventory.Items), "unresolved", len(rows))
+
+	var buf bytes.Buffer
+	if err = worksheet.Write(&buf, rows); err != nil {
+		logger.Error("failed to write worksheet", "error", err)
+		return exitRuntimeError
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = os.WriteFile(*output, buf.Bytes(), reportFileMode)
+	}
+	if err != nil {
+		logger.Error("failed to write worksheet", "error", err)
+		return exitRuntimeError
+	}
+	return exitSuccess
+}
+
+// runImportWorksheet runs the import-worksheet command, which applies the licenses of a completed worksheet to an
+// SBOM or converts them into a curations file.
+func runImportWorksheet(args []string) int {
+	flags := flag.NewFlagSet("import-worksheet", flag.ContinueOnError)
+	var (
+		verbose  = flags.Bool("v", false, "Verbose output (debug mode)")
+		sbomPath = flags.String("sbom", "", "Apply the licenses to this SBOM `file` instead of writing curations")
+		reviewer = flags.String("reviewer", "", "Record this `name` as the reviewer of the curations (optional)")
+		output   = flags.String("o", "", "Write the curations or the SBOM to this `file` (default: stdout)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s import-worksheet [OPTIONS] <worksheet.csv>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Validate the licenses of a completed worksheet and write them as a curations\n")
+		fmt.Fprintf(os.Stderr, "file, or apply them to an SBOM with -sbom.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitInvalidArgs
+	}
+
+	logger := setupLogger(*verbose)
+
+	if flags.NArg() != 1 {
+		logger.Error("exactly one worksheet is required")
+		flags.Usage()
+		return exitInvalidArgs
+	}
+
+	// Read and validate the worksheet
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		logger.Error("failed to read file", "file", flags.Arg(0), "error", err)
+		return exitRuntimeError
+	}
+	rows, err := worksheet.Read(f)
+	_ = f.Close()
+	if err != nil {
+		logger.Error("invalid worksheet", "file", flags.Arg(0), "error", err)
+		return exitInvalidArgs
+	}
+
+	comment := "Imported from worksheet " + filepath.Base(flags.Arg(0))
+	curations, unmatched := worksheet.Curations(rows, *reviewer, comment)
+	for _, row := range unmatched {
+		fmt.Fprintf(os.Stderr, "skipped %s %s: no valid purl\n", row.Name, row.Version)
+	}
+	fmt.Fprintf(os.Stderr, "%d licenses imported, %d skipped\n", len(curations.Curations), len(unmatched))
+
+	var data []byte
+	if *sbomPath == "" {
+		data, err = yaml.Marshal(curations)
+	} else {
+		// Only the worksheet is consulted, so nothing is looked up
+		data, err = processFile(context.Background(), *sbomPath, offlineProvider{}, nil, enricher.Options{
+			Logger:    logger,
+			Curations: curations,
+		})
+	}
+	if err != nil {
+		logger.Error("failed to import worksheet", "error", err)
+		return exitRuntimeError
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, reportFileMode)
+	}
+	if err != nil {
+		logger.Error("failed to write output", "error", err)
+		return exitRuntimeError
+	}
+	return exitSuccess
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+	"github.com/boringbin/sbomlicense/internal/enricher"
+)
+
+// worksheetTestSBOM is a CycloneDX SBOM with components without license information.
+const worksheetTestSBOM = `{"bomFormat": "CycloneDX", "specVersion": "1.6",
+	"components": [
+		{"bom-ref": "a", "name": "a", "version": "1.0.0", "purl": "pkg:npm/a@1.0.0",
+			"licenses": [{"license": {"id": "MIT"}}]},
+		{"bom-ref": "b", "name": "b", "version": "2.0.0", "purl": "pkg:npm/b@2.0.0"},
+		{"bom-ref": "c", "name": "c", "version": "3.0.0", "purl": "pkg:npm/c@3.0.0"}
+	]}`
+
+// TestRunWorksheet tests exporting a worksheet, completing it and importing it back.
+func TestRunWorksheet(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sbomPath := writeTestFile(t, "sbom.json", worksheetTestSBOM)
+	worksheetPath := filepath.Join(dir, "worksheet.csv")
+
+	if exitCode := runExportWorksheet([]string{"-o", worksheetPath, sbomPath}); exitCode != exitSuccess {
+		t.Fatalf("runExportWorksheet() exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	data, err := os.ReadFile(worksheetPath)
+	if err != nil {
+		t.Fatalf("failed to read worksheet: %v", err)
+	}
+	want := "name,version,purl,homepage,license\nb,2.0.0,pkg:npm/b@2.0.0,,\nc,3.0.0,pkg:npm/c@3.0.0,,\n"
+	if string(data) != want {
+		t.Fatalf("runExportWorksheet() worksheet = %q, want %q", data, want)
+	}
+
+	// Fill in the license of b only
+	completed := strings.Replace(string(data), "pkg:npm/b@2.0.0,,", "pkg:npm/b@2.0.0,,BSD-3-Clause", 1)
+	if err = os.WriteFile(worksheetPath, []byte(completed), 0o600); err != nil {
+		t.Fatalf("failed to write worksheet: %v", err)
+	}
+
+	curationsPath := filepath.Join(dir, "curations.yaml")
+	exitCode := runImportWorksheet([]string{"-reviewer", "legal", "-o", curationsPath, worksheetPath})
+	if exitCode != exitSuccess {
+		t.Fatalf("runImportWorksheet() exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	store, err := curation.Open(curationsPath)
+	if err != nil {
+		t.Fatalf("curation.Open() error = %v", err)
+	}
+	if c, ok := store.Find("pkg:npm/b@2.0.0"); !ok || c.License != "BSD-3-Clause" || c.Reviewer != "legal" {
+		t.Errorf("runImportWorksheet() curations = %+v", c)
+	}
+
+	enrichedPath := filepath.Join(dir, "enriched.json")
+	exitCode = runImportWorksheet([]string{"-sbom", sbomPath, "-o", enrichedPath, worksheetPath})
+	if exitCode != exitSuccess {
+		t.Fatalf("runImportWorksheet() with -sbom exit code = %d, want %d", exitCode, exitSuccess)
+	}
+	enriched, err := os.ReadFile(enrichedPath)
+	if err != nil {
+		t.Fatalf("failed to read enriched SBOM: %v", err)
+	}
+	inventory, err := enricher.ReadInventory(enriched)
+	if err != nil {
+		t.Fatalf("ReadInventory() error = %v", err)
+	}
+	licenses := make(map[string]string)
+	for _, item := range inventory.Items {
+		licenses[item.Name] = item.License
+	}
+	if licenses["a"] != "MIT" || licenses["b"] != "BSD-3-Clause" || licenses["c"] != "" {
+		t.Errorf("runImportWorksheet() with -sbom licenses = %v", licenses)
+	}
+}
+
+// TestRunImportWorksheet_InvalidArgs tests the worksheet commands with invalid arguments.
+func TestRunImportWorksheet_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	invalid := writeTestFile(t, "worksheet.csv", "name,purl,license\nb,pkg:npm/b@2.0.0,NOT A LICENSE\n")
+
+	tests := []struct {
+		name string
+		run  func(args []string) int
+		args []string
+		want int
+	}{
+		{name: "export without file", run: runExportWorksheet, args: nil, want: exitInvalidArgs},
+		{name: "import without file", run: runImportWorksheet, args: nil, want: exitInvalidArgs},
+		{name: "invalid license", run: runImportWorksheet, args: []string{invalid}, want: exitInvalidArgs},
+		{name: "missing worksheet", run: runImportWorksheet, args: []string{invalid + ".missing"}, want: exitRuntimeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.run(tt.args); got != tt.want {
+				t.Errorf("%s(%v) = %d, want %d", tt.name, tt.args, got, tt.want)
+			}
+		})
+	}
+}
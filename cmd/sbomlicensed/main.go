@@ -9,12 +9,15 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"go.etcd.io/bbolt"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
+	"github.com/boringbin/sbomlicense/internal/curation"
+	"github.com/boringbin/sbomlicense/internal/policy"
 	"github.com/boringbin/sbomlicense/internal/provider"
 	"github.com/boringbin/sbomlicense/internal/server"
 	"github.com/boringbin/sbomlicense/internal/version"
@@ -37,6 +40,8 @@ const (
 	writeTimeout = 60 * time.Second
 	// shutdownTimeout is the timeout for graceful shutdown.
 	shutdownTimeout = 10 * time.Second
+	// curationsReloadInterval is how often the curations file is checked for changes.
+	curationsReloadInterval = 5 * time.Second
 )
 
 func main() {
@@ -45,13 +50,43 @@ func main() {
 
 func run() int {
 	var (
-		port      = flag.Int("port", defaultPort, "HTTP port to listen on")
-		cachePath = flag.String("cache-path", defaultCachePath, "Path to bbolt cache database file")
-		parallel  = flag.Int("parallel", defaultParallelism, "Default number of concurrent workers for enrichment")
-		cacheTTL  = flag.Duration("cache-ttl", 0*time.Hour, "Cache TTL for enrichment results")
-		verbose   = flag.Bool("v", false, "Verbose output (debug mode)")
-		email     = flag.String("email", "", "Email for polite pool (required)")
+		port         = flag.Int("port", defaultPort, "HTTP port to listen on")
+		cachePath    = flag.String("cache-path", defaultCachePath, "Path to bbolt cache database file")
+		parallel     = flag.Int("parallel", defaultParallelism, "Default number of concurrent workers for enrichment")
+		cacheTTL     = flag.Duration("cache-ttl", 0*time.Hour, "Cache TTL for enrichment results")
+		verbose      = flag.Bool("v", false, "Verbose output (debug mode)")
+		email        = flag.String("email", "", "Email for polite pool (required)")
+		policyArg    = flag.String("policy", "", "Check enriched SBOMs against the YAML or JSON policy `file` (optional)")
+		curationsArg = flag.String(
+			"curations",
+			"",
+			"Set licenses by hand with the YAML or JSON curations `file`, reloaded when it changes (optional)",
+		)
+		providersArg = flag.String(
+			"providers",
+			"",
+			"Look up licenses with the chain of providers in the YAML or JSON `file` (default: Ecosyste.ms)",
+		)
+		rateBurst = flag.Int("rate-burst", 1, "Number of Ecosyste.ms API requests that can be made at once")
+		rateLimit = flag.Float64(
+			"rate-limit",
+			0,
+			"Maximum Ecosyste.ms API requests per second, shared by all requests (default: only the API headers)",
+		)
+		breakerThreshold = flag.Int(
+			"breaker-threshold",
+			5,
+			"Consecutive failures of a provider that stop lookups to it until the cooldown (0 disables)",
+		)
+		breakerCooldown = flag.Duration(
+			"breaker-cooldown",
+			30*time.Second,
+			"Time lookups to a failing provider are stopped before one probes it again",
+		)
+		private stringList
 	)
+	flag.Var(&private, "private",
+		"Never send purls matching this `pattern` to external providers, e.g. pkg:npm/@acme/* (repeatable)")
 
 	flag.Parse()
 
@@ -78,15 +113,111 @@ func run() int {
 		emailAddr = emailEnv
 	}
 
+	// Get private purl patterns from flag or environment variable (comma-separated)
+	privatePatterns := []string(private)
+	if privateEnv := os.Getenv("PRIVATE_PURLS"); privateEnv != "" {
+		privatePatterns = strings.Split(privateEnv, ",")
+	}
+
+	// Get policy path from flag or environment variable
+	policyPath := *policyArg
+	if policyEnv := os.Getenv("POLICY_PATH"); policyEnv != "" {
+		policyPath = policyEnv
+	}
+
+	// Get curations path from flag or environment variable
+	curationsPath := *curationsArg
+	if curationsEnv := os.Getenv("CURATIONS_PATH"); curationsEnv != "" {
+		curationsPath = curationsEnv
+	}
+
+	// Get rate limit from flag or environment variable
+	rateLimitPerSecond := *rateLimit
+	if rateLimitEnv := os.Getenv("RATE_LIMIT"); rateLimitEnv != "" {
+		if rateLimitFromEnv, err := strconv.ParseFloat(rateLimitEnv, 64); err == nil {
+			rateLimitPerSecond = rateLimitFromEnv
+		}
+	}
+
+	// Get rate limit burst from flag or environment variable
+	rateLimitBurst := *rateBurst
+	if rateBurstEnv := os.Getenv("RATE_BURST"); rateBurstEnv != "" {
+		if rateBurstFromEnv, err := strconv.Atoi(rateBurstEnv); err == nil {
+			rateLimitBurst = rateBurstFromEnv
+		}
+	}
+
+	// Get circuit breaker threshold from flag or environment variable
+	breakerFailures := *breakerThreshold
+	if breakerThresholdEnv := os.Getenv("BREAKER_THRESHOLD"); breakerThresholdEnv != "" {
+		if breakerThresholdFromEnv, err := strconv.Atoi(breakerThresholdEnv); err == nil {
+			breakerFailures = breakerThresholdFromEnv
+		}
+	}
+
+	// Get circuit breaker cooldown from flag or environment variable
+	breakerCooldownTime := *breakerCooldown
+	if breakerCooldownEnv := os.Getenv("BREAKER_COOLDOWN"); breakerCooldownEnv != "" {
+		if breakerCooldownFromEnv, err := time.ParseDuration(breakerCooldownEnv); err == nil {
+			breakerCooldownTime = breakerCooldownFromEnv
+		}
+	}
+
+	// Get providers path from flag or environment variable
+	providersPath := *providersArg
+	if providersEnv := os.Getenv("PROVIDERS_PATH"); providersEnv != "" {
+		providersPath = providersEnv
+	}
+
+	// Load the provider chain
+	var chainConfig *provider.ChainConfig
+	if providersPath != "" {
+		loaded, loadErr := provider.LoadChainConfig(providersPath)
+		if loadErr != nil {
+			logger.Error("invalid providers", "path", providersPath, "error", loadErr)
+			return 1
+		}
+		chainConfig = loaded
+		logger.Info("loaded providers", "path", providersPath, "providers", len(chainConfig.Providers))
+	}
+
 	// Validate that email is provided
 	// Email is REQUIRED for daemon mode to access the ecosyste.ms API "polite pool",
-	if emailAddr == "" {
+	if emailAddr == "" && (chainConfig == nil || chainConfig.Uses(provider.KindEcosystems)) {
 		logger.Error("email is required for ecosyste.ms API polite pool access")
 		logger.Error("provide via -email flag or EMAIL environment variable")
 		logger.Error("example: sbomlicensed -email your@example.com")
 		return 1
 	}
 
+	// Load the license policy
+	var licensePolicy *policy.Policy
+	if policyPath != "" {
+		loaded, loadErr := policy.Load(policyPath)
+		if loadErr != nil {
+			logger.Error("invalid policy", "path", policyPath, "error", loadErr)
+			return 1
+		}
+		licensePolicy = loaded
+		logger.Info("loaded license policy", "path", policyPath)
+	}
+
+	// Load the curations and reload them when the file changes
+	var curations *curation.Store
+	if curationsPath != "" {
+		loaded, loadErr := curation.Open(curationsPath)
+		if loadErr != nil {
+			logger.Error("invalid curations", "path", curationsPath, "error", loadErr)
+			return 1
+		}
+		curations = loaded
+		logger.Info("loaded curations", "path", curationsPath, "curations", curations.Len())
+
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+		go curations.Watch(watchCtx, curationsReloadInterval, logger)
+	}
+
 	// Open bbolt database
 	db, err := bbolt.Open(cacheFilePath, dbFileMode, nil)
 	if err != nil {
@@ -103,13 +234,52 @@ func run() int {
 		return 1
 	}
 
-	// Initialize ecosystems provider
-	service := provider.NewClient(provider.ClientOptions{
-		Email: emailAddr,
+	// Share one rate limiter for the ecosystems API between all requests
+	rateLimiter := provider.NewRateLimiter(provider.RateLimiterOptions{
+		Name:   string(provider.KindEcosystems),
+		Rate:   rateLimitPerSecond,
+		Burst:  rateLimitBurst,
+		Logger: logger,
 	})
 
+	// Stop looking up licenses from failing providers for a while, serving cached licenses only
+	breakerOpts := provider.BreakerOptions{
+		Name:      string(provider.KindEcosystems),
+		Threshold: breakerFailures,
+		Cooldown:  breakerCooldownTime,
+		Logger:    logger,
+	}
+	if breakerOpts.Threshold <= 0 {
+		breakerOpts.Threshold = -1
+	}
+
+	// Initialize the provider chain, or the ecosystems provider, guarded against leaking private purls
+	var service provider.Provider
+	if chainConfig != nil {
+		service, err = chainConfig.Chain(provider.ChainOptions{
+			Email:       emailAddr,
+			Private:     privatePatterns,
+			RateLimiter: rateLimiter,
+			Breaker:     breakerOpts,
+		})
+	} else {
+		service, err = provider.NewGuard(provider.NewBreaker(provider.NewClient(provider.ClientOptions{
+			Email:       emailAddr,
+			RateLimiter: rateLimiter,
+		}), breakerOpts), privatePatterns)
+	}
+	if err != nil {
+		logger.Error("invalid private pattern", "error", err)
+		return 1
+	}
+
 	// Create server
 	srv := server.NewServer(service, cacheInstance, logger, *parallel, *cacheTTL, version.Get())
+	srv.SetPolicy(licensePolicy)
+	srv.AddMetrics(rateLimiter)
+	if curations != nil {
+		srv.SetCurations(curations)
+	}
 
 	// Create HTTP server
 	httpServer := &http.Server{
@@ -165,3 +335,17 @@ func setupLogger(verbose bool) *slog.Logger {
 		Level: logLevel,
 	}))
 }
+
+// stringList is a flag.Value collecting the values of a repeatable flag.
+type stringList []string
+
+// String returns the values joined by commas.
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set appends a value.
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -507,3 +507,89 @@ func TestRun_VerboseMode(t *testing.T) {
 		t.Errorf("run() in verbose mode should have detailed logs, got: %s", output)
 	}
 }
+
+// TestRun_InvalidPrivatePatternEnv tests that an invalid PRIVATE_PURLS pattern is rejected.
+func TestRun_InvalidPrivatePatternEnv(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global state
+
+	// Save and restore state
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Set environment variables (t.Setenv will auto-restore)
+	t.Setenv("CACHE_PATH", filepath.Join(t.TempDir(), "cache.db"))
+	t.Setenv("EMAIL", "test@example.com")
+	t.Setenv("PRIVATE_PURLS", "pkg:npm/@acme/*,npm/@acme")
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"sbomlicensed"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	exitCode := run()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if exitCode != 1 {
+		t.Errorf("run() with invalid private pattern returned exit code %d, want 1", exitCode)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	if !strings.Contains(buf.String(), "invalid private pattern") {
+		t.Errorf("run() output should mention invalid private pattern, got: %s", buf.String())
+	}
+}
+
+// TestRun_InvalidPolicyEnv tests that a POLICY_PATH that cannot be loaded is rejected.
+func TestRun_InvalidPolicyEnv(t *testing.T) {
+	// Note: Cannot use t.Parallel() because run() modifies global state
+
+	// Save and restore state
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	t.Cleanup(func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	})
+
+	// Set environment variables (t.Setenv will auto-restore)
+	t.Setenv("CACHE_PATH", filepath.Join(t.TempDir(), "cache.db"))
+	t.Setenv("EMAIL", "test@example.com")
+	t.Setenv("POLICY_PATH", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	// Reset flag.CommandLine for this test
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"sbomlicensed"}
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	exitCode := run()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if exitCode != 1 {
+		t.Errorf("run() with invalid policy returned exit code %d, want 1", exitCode)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	if !strings.Contains(buf.String(), "invalid policy") {
+		t.Errorf("run() output should mention invalid policy, got: %s", buf.String())
+	}
+}
@@ -4,4 +4,7 @@ go 1.25.0
 
 require golang.org/x/sys v0.29.0 // indirect
 
-require go.etcd.io/bbolt v1.4.3
+require (
+	go.etcd.io/bbolt v1.4.3
+	gopkg.in/yaml.v3 v3.0.1
+)
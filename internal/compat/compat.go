@@ -0,0 +1,290 @@
+// Package compat checks whether the licenses of dependencies are compatible with the license a project is
+// distributed under.
+//
+// The check uses a compatibility matrix: licenses are grouped into categories (permissive, weak copyleft,
+// strong copyleft and network copyleft) with a verdict for every pair of categories, and known exceptions
+// are listed as license pairs. The default matrix for common licenses is embedded.
+package compat
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// Verdict is the outcome of checking a dependency license against the project license.
+type Verdict string
+
+const (
+	// VerdictCompatible means the dependency may be distributed under the project license.
+	VerdictCompatible Verdict = "compatible"
+	// VerdictConditional means the dependency is compatible only under conditions, e.g. dynamic linking.
+	VerdictConditional Verdict = "conditional"
+	// VerdictUnknown means the compatibility is unknown, e.g. the license is not in the matrix.
+	VerdictUnknown Verdict = "unknown"
+	// VerdictIncompatible means the dependency must not be distributed under the project license.
+	VerdictIncompatible Verdict = "incompatible"
+)
+
+// severity orders verdicts from compatible to incompatible.
+func (v Verdict) severity() int {
+	switch v {
+	case VerdictCompatible:
+		return 0
+	case VerdictConditional:
+		return 1
+	case VerdictUnknown:
+		return 2 //nolint:mnd // unknown is worse than conditional
+	default:
+		return 3 //nolint:mnd // incompatible is the most severe verdict
+	}
+}
+
+// ErrUnknownProjectLicense is returned when the project license is not in the compatibility matrix.
+var ErrUnknownProjectLicense = errors.New("project license is not in the compatibility matrix")
+
+//go:embed matrix.json
+var defaultMatrix []byte
+
+// Matrix is a license compatibility matrix.
+type Matrix struct {
+	// Licenses maps license identifiers (or "<license> WITH <exception>") to their category.
+	Licenses map[string]string `json:"licenses"`
+	// Categories maps a dependency category and a project category (or "*") to a rule.
+	Categories map[string]map[string]Rule `json:"categories"`
+	// Pairs are rules for specific licenses. They take precedence over categories; the first match wins.
+	Pairs []Pair `json:"pairs"`
+}
+
+// Rule is the verdict for combining a dependency license with a project license.
+type Rule struct {
+	// Verdict is the outcome of the combination.
+	Verdict Verdict `json:"verdict"`
+	// Reason explains the verdict.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Pair is a rule for specific dependency and project licenses.
+type Pair struct {
+	Rule
+
+	// Dependency are globs matched against the dependency license, e.g. "GPL-3.0-*".
+	Dependency []string `json:"dependency"`
+	// Project are globs matched against the project license.
+	Project []string `json:"project"`
+}
+
+// Finding is a dependency that is not plainly compatible with the project license.
+type Finding struct {
+	// ID is the SPDX ID or BOM reference of the dependency.
+	ID string `json:"id"`
+	// Name is the dependency name.
+	Name string `json:"name"`
+	// Version is the dependency version.
+	Version string `json:"version,omitempty"`
+	// Purl is the package URL, if any.
+	Purl string `json:"purl,omitempty"`
+	// License is the license expression of the dependency.
+	License string `json:"license,omitempty"`
+	// Verdict is the outcome of the check.
+	Verdict Verdict `json:"verdict"`
+	// Reason explains the verdict.
+	Reason string `json:"reason"`
+}
+
+// Result is the outcome of checking the dependencies of a project.
+type Result struct {
+	// ProjectLicense is the license the project is distributed under.
+	ProjectLicense string `json:"projectLicense"`
+	// Compatible is false if any dependency is incompatible.
+	Compatible bool `json:"compatible"`
+	// Counts is the number of dependencies per verdict.
+	Counts map[Verdict]int `json:"counts"`
+	// Findings are the dependencies that are not plainly compatible, in document order.
+	Findings []Finding `json:"findings"`
+}
+
+// DefaultMatrix returns the embedded compatibility matrix for common licenses.
+func DefaultMatrix() (*Matrix, error) {
+	return ParseMatrix(defaultMatrix)
+}
+
+// ParseMatrix parses a compatibility matrix from JSON.
+func ParseMatrix(data []byte) (*Matrix, error) {
+	var m Matrix
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse compatibility matrix: %w", err)
+	}
+	return &m, nil
+}
+
+// Check checks the dependencies against the project license.
+//
+// If the project license is an expression, every license in it must be honoured, so each dependency gets the
+// most severe verdict over the project's licenses. Dependency expressions are evaluated with SPDX semantics:
+// OR takes the least severe verdict of its operands, AND the most severe one.
+func (m *Matrix) Check(projectLicense string, items []enricher.InventoryItem) (*Result, error) {
+	project, err := license.Parse(projectLicense)
+	if err != nil {
+		return nil, fmt.Errorf("project license: %w", err)
+	}
+	var projectKeys []string
+	for _, leaf := range project.Normalize().Leaves() {
+		key := m.key(leaf)
+		if _, ok := m.Licenses[key]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProjectLicense, key)
+		}
+		projectKeys = append(projectKeys, key)
+	}
+
+	result := &Result{
+		ProjectLicense: projectLicense,
+		Counts:         map[Verdict]int{},
+		Findings:       []Finding{},
+	}
+	for _, item := range items {
+		rule := m.checkItem(item.License, projectKeys)
+		result.Counts[rule.Verdict]++
+		if rule.Verdict == VerdictCompatible {
+			continue
+		}
+		result.Findings = append(result.Findings, Finding{
+			ID:      item.ID,
+			Name:    item.Name,
+			Version: item.Version,
+			Purl:    item.Purl,
+			License: item.License,
+			Verdict: rule.Verdict,
+			Reason:  rule.Reason,
+		})
+	}
+	result.Compatible = result.Counts[VerdictIncompatible] == 0
+	return result, nil
+}
+
+// checkItem returns the verdict for a dependency license against all project licenses.
+func (m *Matrix) checkItem(dependencyLicense string, projectKeys []string) Rule {
+	if dependencyLicense == "" || dependencyLicense == "NONE" || dependencyLicense == "NOASSERTION" {
+		return Rule{Verdict: VerdictUnknown, Reason: "no license information"}
+	}
+	expr, err := license.Parse(dependencyLicense)
+	if err != nil {
+		return Rule{Verdict: VerdictUnknown, Reason: dependencyLicense + " is not a valid SPDX expression"}
+	}
+	expr = expr.Normalize()
+
+	worst := Rule{Verdict: VerdictCompatible}
+	for _, projectKey := range projectKeys {
+		rule := m.checkExpression(expr, projectKey)
+		if rule.Verdict.severity() > worst.Verdict.severity() {
+			worst = rule
+		}
+	}
+	return worst
+}
+
+// checkExpression returns the verdict for a dependency expression against a single project license.
+func (m *Matrix) checkExpression(expr *license.Expression, projectKey string) Rule {
+	if expr.IsLeaf() {
+		return m.checkLicense(m.key(expr), projectKey)
+	}
+
+	var chosen Rule
+	var reasons []string
+	for i, operand := range expr.Operands {
+		rule := m.checkExpression(operand, projectKey)
+		switch {
+		case i == 0,
+			expr.Operator == license.OperatorAnd && rule.Verdict.severity() > chosen.Verdict.severity(),
+			expr.Operator == license.OperatorOr && rule.Verdict.severity() < chosen.Verdict.severity():
+			chosen, reasons = rule, []string{rule.Reason}
+		case rule.Verdict == chosen.Verdict:
+			reasons = append(reasons, rule.Reason)
+		}
+	}
+	if chosen.Verdict != VerdictCompatible {
+		chosen.Reason = strings.Join(reasons, "; ")
+	}
+	return chosen
+}
+
+// checkLicense returns the verdict for a single dependency license against a single project license.
+func (m *Matrix) checkLicense(dependencyKey, projectKey string) Rule {
+	if strings.EqualFold(dependencyKey, projectKey) {
+		return Rule{Verdict: VerdictCompatible}
+	}
+
+	for _, pair := range m.Pairs {
+		if matchAny(pair.Dependency, dependencyKey) && matchAny(pair.Project, projectKey) {
+			return withSubject(pair.Rule, dependencyKey)
+		}
+	}
+
+	dependencyCategory, ok := m.Licenses[dependencyKey]
+	if !ok {
+		return Rule{Verdict: VerdictUnknown, Reason: dependencyKey + " is not in the compatibility matrix"}
+	}
+	rules := m.Categories[dependencyCategory]
+	if rule, found := rules[m.Licenses[projectKey]]; found {
+		return withSubject(rule, dependencyKey)
+	}
+	if rule, found := rules["*"]; found {
+		return withSubject(rule, dependencyKey)
+	}
+	return Rule{
+		Verdict: VerdictUnknown,
+		Reason:  fmt.Sprintf("no rule for %s dependencies in %s projects", dependencyCategory, m.Licenses[projectKey]),
+	}
+}
+
+// withSubject prefixes the reason of a non-compatible rule with the dependency license.
+func withSubject(rule Rule, dependencyKey string) Rule {
+	if rule.Verdict != VerdictCompatible && rule.Reason != "" {
+		rule.Reason = dependencyKey + ": " + rule.Reason
+	}
+	return rule
+}
+
+// key returns the matrix key of a license: the license with its exception if the matrix knows the pair,
+// otherwise the license alone. Keys use the matrix's spelling of the identifier.
+func (m *Matrix) key(leaf *license.Expression) string {
+	if leaf.Exception != "" {
+		withException := leaf.License + " WITH " + leaf.Exception
+		if known, ok := m.lookup(withException); ok {
+			return known
+		}
+	}
+	if known, ok := m.lookup(leaf.License); ok {
+		return known
+	}
+	return leaf.License
+}
+
+// lookup finds the matrix spelling of a license, ignoring case.
+func (m *Matrix) lookup(id string) (string, bool) {
+	if _, ok := m.Licenses[id]; ok {
+		return id, true
+	}
+	for known := range m.Licenses {
+		if strings.EqualFold(known, id) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// matchAny reports whether s matches any of the globs.
+func matchAny(globs []string, s string) bool {
+	for _, glob := range globs {
+		if purl.MatchGlob(glob, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package compat_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/compat"
+	"github.com/boringbin/sbomlicense/internal/enricher"
+)
+
+// TestDefaultMatrix tests that the embedded matrix is consistent.
+func TestDefaultMatrix(t *testing.T) {
+	t.Parallel()
+
+	m, err := compat.DefaultMatrix()
+	if err != nil {
+		t.Fatalf("DefaultMatrix() error = %v", err)
+	}
+
+	for id, category := range m.Licenses {
+		if _, ok := m.Categories[category]; !ok {
+			t.Errorf("license %s has category %q without rules", id, category)
+		}
+	}
+	for i, pair := range m.Pairs {
+		if len(pair.Dependency) == 0 || len(pair.Project) == 0 || pair.Verdict == "" {
+			t.Errorf("pair %d is incomplete: %+v", i, pair)
+		}
+	}
+}
+
+// TestMatrix_Check tests the verdict for dependency licenses against project licenses.
+func TestMatrix_Check(t *testing.T) {
+	t.Parallel()
+
+	m, err := compat.DefaultMatrix()
+	if err != nil {
+		t.Fatalf("DefaultMatrix() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		project    string
+		dependency string
+		want       compat.Verdict
+		wantReason string
+	}{
+		{name: "permissive", project: "Apache-2.0", dependency: "MIT", want: compat.VerdictCompatible},
+		{name: "same license", project: "GPL-2.0-only", dependency: "GPL-2.0-only", want: compat.VerdictCompatible},
+		{name: "case-insensitive", project: "apache-2.0", dependency: "mit", want: compat.VerdictCompatible},
+		{
+			name:       "strong copyleft in permissive project",
+			project:    "Apache-2.0",
+			dependency: "GPL-2.0-only",
+			want:       compat.VerdictIncompatible,
+			wantReason: "GPL-2.0-only: strong copyleft",
+		},
+		{
+			name:       "deprecated identifier",
+			project:    "Apache-2.0",
+			dependency: "GPL-2.0",
+			want:       compat.VerdictIncompatible,
+			wantReason: "GPL-2.0-only: strong copyleft",
+		},
+		{
+			name:       "weak copyleft",
+			project:    "Apache-2.0",
+			dependency: "LGPL-2.1-only",
+			want:       compat.VerdictConditional,
+			wantReason: "LGPL-2.1-only: the dependency must stay a separate library",
+		},
+		{name: "classpath exception", project: "Apache-2.0", dependency: "GPL-2.0-only WITH Classpath-exception-2.0",
+			want: compat.VerdictConditional},
+		{
+			name:       "apache in GPL-2.0",
+			project:    "GPL-2.0-only",
+			dependency: "Apache-2.0",
+			want:       compat.VerdictIncompatible,
+			wantReason: "Apache-2.0: the patent termination",
+		},
+		{name: "apache in GPL-3.0", project: "GPL-3.0-or-later", dependency: "Apache-2.0", want: compat.VerdictCompatible},
+		{name: "or-later upgrade", project: "GPL-3.0-only", dependency: "GPL-2.0-or-later", want: compat.VerdictCompatible},
+		{
+			name:       "GPL-2.0-only in GPL-3.0",
+			project:    "GPL-3.0-only",
+			dependency: "GPL-2.0-only",
+			want:       compat.VerdictIncompatible,
+			wantReason: "GPL-2.0-only: GPL-2.0-only code cannot be combined",
+		},
+		{name: "or picks a compatible choice", project: "MIT", dependency: "GPL-3.0-only OR MIT",
+			want: compat.VerdictCompatible},
+		{
+			name:       "and picks the worst operand",
+			project:    "MIT",
+			dependency: "MIT AND AGPL-3.0-only",
+			want:       compat.VerdictIncompatible,
+			wantReason: "AGPL-3.0-only: network copyleft",
+		},
+		{
+			name:       "dual-licensed project must honour both licenses",
+			project:    "MIT OR GPL-2.0-only",
+			dependency: "Apache-2.0",
+			want:       compat.VerdictIncompatible,
+			wantReason: "Apache-2.0: the patent termination",
+		},
+		{
+			name:       "copyleft in proprietary project",
+			project:    "LicenseRef-proprietary",
+			dependency: "GPL-3.0-or-later",
+			want:       compat.VerdictIncompatible,
+			wantReason: "GPL-3.0-or-later: strong copyleft",
+		},
+		{
+			name:       "unknown license",
+			project:    "MIT",
+			dependency: "LicenseRef-acme",
+			want:       compat.VerdictUnknown,
+			wantReason: "LicenseRef-acme is not in the compatibility matrix",
+		},
+		{
+			name:       "missing license",
+			project:    "MIT",
+			dependency: "NOASSERTION",
+			want:       compat.VerdictUnknown,
+			wantReason: "no license information",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, checkErr := m.Check(tt.project, []enricher.InventoryItem{{ID: "dep", License: tt.dependency}})
+			if checkErr != nil {
+				t.Fatalf("Check() error = %v", checkErr)
+			}
+			if result.Counts[tt.want] != 1 {
+				t.Fatalf("Check() = %+v, want verdict %s", result, tt.want)
+			}
+			if tt.want == compat.VerdictCompatible {
+				if len(result.Findings) != 0 {
+					t.Errorf("Check() findings = %+v, want none", result.Findings)
+				}
+				return
+			}
+			if len(result.Findings) != 1 || !strings.HasPrefix(result.Findings[0].Reason, tt.wantReason) {
+				t.Errorf("Check() findings = %+v, want reason starting with %q", result.Findings, tt.wantReason)
+			}
+			if result.Compatible != (tt.want != compat.VerdictIncompatible) {
+				t.Errorf("Check() Compatible = %v", result.Compatible)
+			}
+		})
+	}
+}
+
+// TestMatrix_Check_InvalidProjectLicense tests that the project license must be known.
+func TestMatrix_Check_InvalidProjectLicense(t *testing.T) {
+	t.Parallel()
+
+	m, err := compat.DefaultMatrix()
+	if err != nil {
+		t.Fatalf("DefaultMatrix() error = %v", err)
+	}
+
+	if _, err = m.Check("LicenseRef-acme", nil); !errors.Is(err, compat.ErrUnknownProjectLicense) {
+		t.Errorf("Check() error = %v, want ErrUnknownProjectLicense", err)
+	}
+	if _, err = m.Check("MIT OR", nil); err == nil {
+		t.Error("Check() with invalid expression error = nil, want error")
+	}
+}
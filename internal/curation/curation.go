@@ -0,0 +1,206 @@
+// Package curation provides manual license curations: licenses set by hand for packages whose license is
+// wrong or missing in the providers.
+//
+// Curations are read from a YAML or JSON file. Each curation maps a purl pattern, optionally restricted to a
+// version range, to a license:
+//
+//	curations:
+//	  - purl: pkg:npm/left-pad
+//	    versions: ">=1.0.0 <2.0.0"
+//	    license: MIT
+//	    comment: License file is missing from the published package
+//	    reviewer: jane@example.com
+package curation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// ErrInvalidCuration is returned when a curation is incomplete or invalid.
+var ErrInvalidCuration = errors.New("invalid curation")
+
+// Curation sets the license of the packages matching a purl pattern.
+type Curation struct {
+	// Purl is a purl glob, e.g. "pkg:npm/left-pad" or "pkg:maven/com.acme/*". Percent-encoding may be omitted.
+	// A pattern without version matches every version of the package.
+	Purl string `yaml:"purl" json:"purl"`
+	// Versions restricts the curation to a version range, e.g. ">=1.0.0 <2.0.0". If empty, all versions match.
+	Versions string `yaml:"versions,omitempty" json:"versions,omitempty"`
+	// License is the SPDX license expression of the packages.
+	License string `yaml:"license" json:"license"`
+	// Comment documents why the license was curated.
+	Comment string `yaml:"comment,omitempty" json:"comment,omitempty"`
+	// Reviewer is the person who reviewed the curation.
+	Reviewer string `yaml:"reviewer,omitempty" json:"reviewer,omitempty"`
+
+	// versions is the parsed version range.
+	versions versionRange
+}
+
+// File is a curations file.
+type File struct {
+	// Curations are the curations; the first matching curation wins.
+	Curations []Curation `yaml:"curations"`
+}
+
+// Parse parses a curations file from YAML or JSON and validates it.
+func Parse(data []byte) (*File, error) {
+	var f File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse curations: %w", err)
+	}
+
+	for i := range f.Curations {
+		c := &f.Curations[i]
+		if !strings.HasPrefix(c.Purl, "pkg:") {
+			return nil, fmt.Errorf("%w: curation %d: purl %q must start with \"pkg:\"", ErrInvalidCuration, i+1, c.Purl)
+		}
+		if _, err := license.Parse(c.License); err != nil {
+			return nil, fmt.Errorf("%w: curation %d (%s): %w", ErrInvalidCuration, i+1, c.Purl, err)
+		}
+		versions, err := parseVersionRange(c.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: curation %d (%s): %w", ErrInvalidCuration, i+1, c.Purl, err)
+		}
+		c.versions = versions
+	}
+	return &f, nil
+}
+
+// Find returns the first curation matching the purl.
+func (f *File) Find(rawPurl string) (*Curation, bool) {
+	for i := range f.Curations {
+		if f.Curations[i].matches(rawPurl) {
+			return &f.Curations[i], true
+		}
+	}
+	return nil, false
+}
+
+// matches reports whether the curation applies to the purl.
+func (c *Curation) matches(rawPurl string) bool {
+	if !purl.Match(c.Purl, rawPurl) && (hasVersion(c.Purl) || !purl.Match(c.Purl+"@*", rawPurl)) {
+		return false
+	}
+	if len(c.versions) == 0 {
+		return true
+	}
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil || parsed.Version == "" {
+		return false
+	}
+	return c.versions.contains(parsed.Version)
+}
+
+// hasVersion reports whether a purl pattern contains a version.
+func hasVersion(pattern string) bool {
+	last := pattern[strings.LastIndex(pattern, "/")+1:]
+	return strings.Contains(last, "@")
+}
+
+// Store holds the curations of a file and reloads them when the file changes.
+//
+// It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	file    *File
+	modTime time.Time
+}
+
+// Open loads the curations file at path.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Find returns the first curation matching the purl.
+func (s *Store) Find(rawPurl string) (*Curation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.file.Find(rawPurl)
+}
+
+// Len returns the number of curations.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.file.Curations)
+}
+
+// Reload reloads the curations if the file was modified since it was last loaded, and reports whether it
+// did. If the file is invalid, the previous curations are kept until the file changes again.
+func (s *Store) Reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, fmt.Errorf("stat curations: %w", err)
+	}
+
+	s.mu.RLock()
+	unchanged := s.file != nil && info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Remember the modification time even if the file is invalid, so it is not reloaded until it changes
+	s.modTime = info.ModTime()
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, fmt.Errorf("read curations: %w", err)
+	}
+	file, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+	s.file = file
+	return true, nil
+}
+
+// Watch reloads the curations every interval until the context is done. Errors are logged and the previous
+// curations are kept.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				logger.Error("failed to reload curations", "path", s.path, "error", err)
+				continue
+			}
+			if reloaded {
+				logger.Info("reloaded curations", "path", s.path, "curations", s.Len())
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package curation_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+)
+
+// testCurations is a curations file with version ranges.
+const testCurations = `curations:
+  - purl: pkg:npm/left-pad
+    versions: ">=1.0.0 <2.0.0"
+    license: MIT
+    comment: License file missing from the package
+    reviewer: jane@example.com
+  - purl: pkg:npm/left-pad
+    versions: "0.9.0 || >=3"
+    license: Apache-2.0
+  - purl: pkg:npm/@acme/*
+    license: LicenseRef-acme
+  - purl: pkg:maven/org.example/lib@1.*
+    license: EPL-2.0
+`
+
+// TestFile_Find tests matching purls against curations.
+func TestFile_Find(t *testing.T) {
+	t.Parallel()
+
+	f, err := curation.Parse([]byte(testCurations))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		purl        string
+		wantLicense string
+	}{
+		{purl: "pkg:npm/left-pad@1.3.0", wantLicense: "MIT"},
+		{purl: "pkg:npm/left-pad@1.0.0", wantLicense: "MIT"},
+		{purl: "pkg:npm/left-pad@v1.10.2", wantLicense: "MIT"},
+		{purl: "pkg:npm/left-pad@2.0.0", wantLicense: ""},
+		{purl: "pkg:npm/left-pad@2.0.0-rc.1", wantLicense: "MIT"},
+		{purl: "pkg:npm/left-pad@0.9.0", wantLicense: "Apache-2.0"},
+		{purl: "pkg:npm/left-pad@3.0.0-beta", wantLicense: ""},
+		{purl: "pkg:npm/left-pad@3.1", wantLicense: "Apache-2.0"},
+		{purl: "pkg:npm/left-pad", wantLicense: ""},
+		{purl: "pkg:npm/left-pad-extra@1.0.0", wantLicense: ""},
+		{purl: "pkg:npm/%40acme/ui@0.1.0", wantLicense: "LicenseRef-acme"},
+		{purl: "pkg:maven/org.example/lib@1.2.3?type=jar", wantLicense: "EPL-2.0"},
+		{purl: "pkg:maven/org.example/lib@2.0.0", wantLicense: ""},
+	}
+
+	for _, tt := range tests {
+		c, ok := f.Find(tt.purl)
+		got := ""
+		if ok {
+			got = c.License
+		}
+		if got != tt.wantLicense {
+			t.Errorf("Find(%q) = %q, want %q", tt.purl, got, tt.wantLicense)
+		}
+	}
+}
+
+// TestParse_Invalid tests that invalid curations are rejected.
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{name: "unknown field", data: "curations:\n  - purl: pkg:npm/a\n    licence: MIT\n"},
+		{name: "invalid purl", data: "curations:\n  - purl: npm/a\n    license: MIT\n", wantErr: curation.ErrInvalidCuration},
+		{name: "missing license", data: "curations:\n  - purl: pkg:npm/a\n", wantErr: curation.ErrInvalidCuration},
+		{
+			name:    "invalid range",
+			data:    "curations:\n  - purl: pkg:npm/a\n    versions: '>= || 1'\n    license: MIT\n",
+			wantErr: curation.ErrInvalidCuration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := curation.Parse([]byte(tt.data))
+			if err == nil {
+				t.Fatal("Parse() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestParse_JSON tests that curations files may be JSON.
+func TestParse_JSON(t *testing.T) {
+	t.Parallel()
+
+	f, err := curation.Parse([]byte(`{"curations": [{"purl": "pkg:pypi/requests", "license": "Apache-2.0"}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if c, ok := f.Find("pkg:pypi/requests@2.31.0"); !ok || c.License != "Apache-2.0" {
+		t.Errorf("Find() = %+v, %v", c, ok)
+	}
+}
+
+// TestStore_Reload tests that the store picks up changes to the file and keeps the curations of an invalid one.
+func TestStore_Reload(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "curations.yaml")
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write curations: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("failed to set modification time: %v", err)
+		}
+	}
+	now := time.Now()
+
+	write("curations:\n  - purl: pkg:npm/a\n    license: MIT\n", now)
+	s, err := curation.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if reloaded, reloadErr := s.Reload(); reloaded || reloadErr != nil {
+		t.Errorf("Reload() of unchanged file = %v, %v, want false, nil", reloaded, reloadErr)
+	}
+
+	write("curations:\n  - purl: pkg:npm/a\n    license: Apache-2.0\n", now.Add(time.Second))
+	if reloaded, reloadErr := s.Reload(); !reloaded || reloadErr != nil {
+		t.Errorf("Reload() of changed file = %v, %v, want true, nil", reloaded, reloadErr)
+	}
+	if c, ok := s.Find("pkg:npm/a@1.0.0"); !ok || c.License != "Apache-2.0" {
+		t.Errorf("Find() after reload = %+v, want Apache-2.0", c)
+	}
+
+	write("curations: [", now.Add(2*time.Second))
+	if _, reloadErr := s.Reload(); reloadErr == nil {
+		t.Error("Reload() of invalid file error = nil, want error")
+	}
+	if c, ok := s.Find("pkg:npm/a@1.0.0"); !ok || c.License != "Apache-2.0" {
+		t.Errorf("Find() after invalid reload = %+v, want previous curations", c)
+	}
+}
@@ -0,0 +1,308 @@
+package curation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// Import is the result of importing curations from another tool.
+type Import struct {
+	// Curations are the translated curations.
+	Curations []Curation
+	// Skipped are the curations that could not be translated.
+	Skipped []Skipped
+}
+
+// Skipped is a curation that could not be translated.
+type Skipped struct {
+	// ID identifies the curation in the source format, e.g. the ORT identifier.
+	ID string
+	// Reason explains why the curation was skipped.
+	Reason string
+}
+
+// ortTypes maps ORT package manager types to purl types.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var ortTypes = map[string]string{
+	"bower":     "bower",
+	"cocoapods": "cocoapods",
+	"composer":  "composer",
+	"conan":     "conan",
+	"crate":     "cargo",
+	"gem":       "gem",
+	"go":        "golang",
+	"gomod":     "golang",
+	"hackage":   "hackage",
+	"maven":     "maven",
+	"npm":       "npm",
+	"nuget":     "nuget",
+	"pub":       "pub",
+	"pypi":      "pypi",
+	"swift":     "swift",
+}
+
+// clearlyDefinedTypes maps ClearlyDefined coordinate types to purl types.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var clearlyDefinedTypes = map[string]string{
+	"composer": "composer",
+	"conda":    "conda",
+	"crate":    "cargo",
+	"deb":      "deb",
+	"gem":      "gem",
+	"go":       "golang",
+	"maven":    "maven",
+	"npm":      "npm",
+	"nuget":    "nuget",
+	"pod":      "cocoapods",
+	"pypi":     "pypi",
+}
+
+// clearlyDefinedGitProviders maps the providers of ClearlyDefined git coordinates, which are the hosts of the
+// repositories, to purl types.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var clearlyDefinedGitProviders = map[string]string{
+	"bitbucket": "bitbucket",
+	"github":    "github",
+	"gitlab":    "gitlab",
+}
+
+// ivyRange matches an Ivy version range such as "[1.0,2.0)" as used by ORT for Maven.
+//
+//nolint:gochecknoglobals // compiled once
+var ivyRange = regexp.MustCompile(`^([\[\]\(])\s*([^,]*?)\s*,\s*([^,]*?)\s*([\[\]\)])$`)
+
+// ortCuration is a package curation of the OSS Review Toolkit.
+type ortCuration struct {
+	ID        string `yaml:"id"`
+	Curations struct {
+		Comment                string            `yaml:"comment"`
+		ConcludedLicense       string            `yaml:"concluded_license"`
+		DeclaredLicenseMapping map[string]string `yaml:"declared_license_mapping"`
+	} `yaml:"curations"`
+}
+
+// ImportORT imports the package curations of an OSS Review Toolkit curations.yml file.
+//
+// Identifiers of the form "Type:Namespace:Name:Version" are mapped to purls. An empty version applies the
+// curation to all versions, and Ivy ranges such as "[1.0,2.0)" are translated to version ranges. Only the
+// concluded license is imported; curations without one are skipped.
+func ImportORT(data []byte) (*Import, error) {
+	var entries []ortCuration
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse ORT curations: %w", err)
+	}
+
+	result := &Import{}
+	for _, entry := range entries {
+		curation, reason := translateORT(entry)
+		if reason != "" {
+			result.Skipped = append(result.Skipped, Skipped{ID: entry.ID, Reason: reason})
+			continue
+		}
+		result.Curations = append(result.Curations, curation)
+	}
+	return result, nil
+}
+
+// translateORT translates an ORT curation, or returns the reason it cannot be translated.
+func translateORT(entry ortCuration) (Curation, string) {
+	parts := strings.Split(entry.ID, ":")
+	if len(parts) != 4 { //nolint:mnd // type, namespace, name and version
+		return Curation{}, "identifier is not of the form Type:Namespace:Name:Version"
+	}
+	typ, ok := ortTypes[strings.ToLower(parts[0])]
+	if !ok {
+		return Curation{}, fmt.Sprintf("package type %q has no purl equivalent", parts[0])
+	}
+	if parts[2] == "" {
+		return Curation{}, "identifier has no name"
+	}
+	if entry.Curations.ConcludedLicense == "" {
+		if len(entry.Curations.DeclaredLicenseMapping) > 0 {
+			return Curation{}, "declared license mappings are not supported, only concluded licenses"
+		}
+		return Curation{}, "curation has no concluded license"
+	}
+
+	c := Curation{
+		Purl:    purlPattern(typ, parts[1], parts[2]),
+		License: entry.Curations.ConcludedLicense,
+		Comment: strings.TrimSpace(entry.Curations.Comment),
+	}
+	version := strings.TrimSpace(parts[3])
+	switch {
+	case version == "":
+	case ivyRange.MatchString(version):
+		c.Versions = translateIvyRange(version)
+	case strings.ContainsAny(version, "<>=|^~*, "):
+		if _, err := parseVersionRange(version); err != nil || strings.ContainsAny(version, "^~*") {
+			return Curation{}, fmt.Sprintf("version range %q is not supported", version)
+		}
+		c.Versions = version
+	default:
+		c.Purl += "@" + version
+	}
+	return validate(c)
+}
+
+// translateIvyRange translates an Ivy version range such as "[1.0,2.0)" to ">=1.0 <2.0".
+func translateIvyRange(s string) string {
+	m := ivyRange.FindStringSubmatch(s)
+	var constraints []string
+	if m[2] != "" {
+		op := ">"
+		if m[1] == "[" {
+			op = ">="
+		}
+		constraints = append(constraints, op+m[2])
+	}
+	if m[3] != "" {
+		op := "<"
+		if m[4] == "]" {
+			op = "<="
+		}
+		constraints = append(constraints, op+m[3])
+	}
+	return strings.Join(constraints, " ")
+}
+
+// clearlyDefinedCuration is a curation file of the ClearlyDefined curated-data repository.
+type clearlyDefinedCuration struct {
+	Coordinates struct {
+		Type      string `yaml:"type"`
+		Provider  string `yaml:"provider"`
+		Namespace string `yaml:"namespace"`
+		Name      string `yaml:"name"`
+	} `yaml:"coordinates"`
+	Revisions map[string]struct {
+		Licensed struct {
+			Declared string `yaml:"declared"`
+		} `yaml:"licensed"`
+	} `yaml:"revisions"`
+}
+
+// ImportClearlyDefined imports a ClearlyDefined curation YAML file, which may contain several documents.
+//
+// The coordinates are mapped to purls and every revision with a curated declared license becomes a curation
+// for that version. Revisions that only curate files or other facets are skipped.
+func ImportClearlyDefined(data []byte) (*Import, error) {
+	result := &Import{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc clearlyDefinedCuration
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse ClearlyDefined curations: %w", err)
+		}
+
+		coordinates := doc.Coordinates
+		id := strings.Join([]string{
+			coordinates.Type, coordinates.Provider, orDash(coordinates.Namespace), coordinates.Name,
+		}, "/")
+		typ, reason := clearlyDefinedType(coordinates.Type, coordinates.Provider)
+		if reason != "" || coordinates.Name == "" {
+			if coordinates.Name == "" {
+				reason = "coordinates have no name"
+			}
+			result.Skipped = append(result.Skipped, Skipped{ID: id, Reason: reason})
+			continue
+		}
+		namespace := coordinates.Namespace
+		if typ == "deb" && namespace == "" {
+			namespace = "debian"
+		}
+
+		revisions := make([]string, 0, len(doc.Revisions))
+		for revision := range doc.Revisions {
+			revisions = append(revisions, revision)
+		}
+		sort.Strings(revisions)
+		for _, revision := range revisions {
+			declared := doc.Revisions[revision].Licensed.Declared
+			if declared == "" {
+				result.Skipped = append(result.Skipped, Skipped{
+					ID:     id + "/" + revision,
+					Reason: "revision does not curate the declared license",
+				})
+				continue
+			}
+			c, reason := validate(Curation{
+				Purl:    purlPattern(typ, namespace, coordinates.Name) + "@" + revision,
+				License: declared,
+				Comment: "Imported from ClearlyDefined " + id + "/" + revision,
+			})
+			if reason != "" {
+				result.Skipped = append(result.Skipped, Skipped{ID: id + "/" + revision, Reason: reason})
+				continue
+			}
+			result.Curations = append(result.Curations, c)
+		}
+	}
+	return result, nil
+}
+
+// clearlyDefinedType returns the purl type of ClearlyDefined coordinates, or the reason there is none. Git
+// coordinates are mapped by their provider, since a repository on GitLab is not the one with the same name on
+// GitHub.
+func clearlyDefinedType(coordinateType, provider string) (string, string) {
+	if strings.EqualFold(coordinateType, "git") {
+		typ, ok := clearlyDefinedGitProviders[strings.ToLower(provider)]
+		if !ok {
+			return "", fmt.Sprintf("git provider %q has no purl equivalent", provider)
+		}
+		return typ, ""
+	}
+	typ, ok := clearlyDefinedTypes[strings.ToLower(coordinateType)]
+	if !ok {
+		return "", fmt.Sprintf("coordinate type %q has no purl equivalent", coordinateType)
+	}
+	return typ, ""
+}
+
+// purlPattern returns the purl pattern of a package. ClearlyDefined encodes slashes in Go namespaces as
+// "%2f", and uses "-" for an empty namespace.
+func purlPattern(typ, namespace, name string) string {
+	if decoded, err := url.PathUnescape(namespace); err == nil {
+		namespace = decoded
+	}
+	if namespace == "" || namespace == "-" {
+		return "pkg:" + typ + "/" + name
+	}
+	return "pkg:" + typ + "/" + namespace + "/" + name
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// validate checks that an imported curation has a valid license, or returns the reason it does not.
+func validate(c Curation) (Curation, string) {
+	switch c.License {
+	case "NOASSERTION", "NONE":
+		return Curation{}, fmt.Sprintf("license %s is not a license", c.License)
+	}
+	if _, err := license.Parse(c.License); err != nil {
+		return Curation{}, fmt.Sprintf("license %q is not a valid SPDX expression", c.License)
+	}
+	return c, ""
+}
@@ -0,0 +1,182 @@
+package curation_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+)
+
+// TestImportORT tests translating ORT package curations.
+func TestImportORT(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`
+- id: "Maven:org.hamcrest:hamcrest-core:1.3"
+  curations:
+    comment: "License is BSD-3-Clause, see LICENSE.txt."
+    concluded_license: "BSD-3-Clause"
+- id: "NPM:@acme:ui:"
+  curations:
+    concluded_license: "LicenseRef-acme"
+- id: "Maven:org.example:lib:[1.0,2.0)"
+  curations:
+    concluded_license: "EPL-2.0"
+- id: "PyPI::requests:>=2.0 <3"
+  curations:
+    concluded_license: "Apache-2.0"
+- id: "NPM::left-pad:^1.0.0"
+  curations:
+    concluded_license: "MIT"
+- id: "Unmanaged::project:1.0"
+  curations:
+    concluded_license: "MIT"
+- id: "NPM::foo:1.0.0"
+  curations:
+    declared_license_mapping:
+      "BSD": "BSD-3-Clause"
+- id: "NPM::bar:1.0.0"
+  curations:
+    concluded_license: "NOASSERTION"
+- id: "NPM:bar"
+  curations:
+    concluded_license: "MIT"
+`)
+
+	got, err := curation.ImportORT(data)
+	if err != nil {
+		t.Fatalf("ImportORT() error = %v", err)
+	}
+
+	wantCurations := []curation.Curation{
+		{
+			Purl:    "pkg:maven/org.hamcrest/hamcrest-core@1.3",
+			License: "BSD-3-Clause",
+			Comment: "License is BSD-3-Clause, see LICENSE.txt.",
+		},
+		{Purl: "pkg:npm/@acme/ui", License: "LicenseRef-acme"},
+		{Purl: "pkg:maven/org.example/lib", Versions: ">=1.0 <2.0", License: "EPL-2.0"},
+		{Purl: "pkg:pypi/requests", Versions: ">=2.0 <3", License: "Apache-2.0"},
+	}
+	if !reflect.DeepEqual(got.Curations, wantCurations) {
+		t.Errorf("ImportORT() curations = %+v, want %+v", got.Curations, wantCurations)
+	}
+
+	wantSkipped := []string{
+		"NPM::left-pad:^1.0.0", "Unmanaged::project:1.0", "NPM::foo:1.0.0", "NPM::bar:1.0.0", "NPM:bar",
+	}
+	if len(got.Skipped) != len(wantSkipped) {
+		t.Fatalf("ImportORT() skipped = %+v, want %v", got.Skipped, wantSkipped)
+	}
+	for i, s := range got.Skipped {
+		if s.ID != wantSkipped[i] || s.Reason == "" {
+			t.Errorf("ImportORT() skipped[%d] = %+v, want ID %q with a reason", i, s, wantSkipped[i])
+		}
+	}
+}
+
+// TestImportClearlyDefined tests translating ClearlyDefined curations.
+func TestImportClearlyDefined(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`coordinates:
+  name: lodash
+  provider: npmjs
+  type: npm
+revisions:
+  4.17.21:
+    licensed:
+      declared: MIT
+  4.17.20:
+    files:
+      - path: LICENSE
+        license: MIT
+---
+coordinates:
+  name: mux
+  namespace: github.com%2fgorilla
+  provider: golang
+  type: go
+revisions:
+  v1.8.0:
+    licensed:
+      declared: BSD-3-Clause
+---
+coordinates:
+  name: zlib
+  provider: github
+  type: sourcearchive
+revisions:
+  1.2.13:
+    licensed:
+      declared: Zlib
+---
+coordinates:
+  name: project
+  namespace: group
+  provider: gitlab
+  type: git
+revisions:
+  0123456789abcdef0123456789abcdef01234567:
+    licensed:
+      declared: Apache-2.0
+---
+coordinates:
+  name: repo
+  namespace: team
+  provider: codeberg
+  type: git
+revisions:
+  fedcba9876543210fedcba9876543210fedcba98:
+    licensed:
+      declared: MIT
+`)
+
+	got, err := curation.ImportClearlyDefined(data)
+	if err != nil {
+		t.Fatalf("ImportClearlyDefined() error = %v", err)
+	}
+
+	wantCurations := []curation.Curation{
+		{
+			Purl:    "pkg:npm/lodash@4.17.21",
+			License: "MIT",
+			Comment: "Imported from ClearlyDefined npm/npmjs/-/lodash/4.17.21",
+		},
+		{
+			Purl:    "pkg:golang/github.com/gorilla/mux@v1.8.0",
+			License: "BSD-3-Clause",
+			Comment: "Imported from ClearlyDefined go/golang/github.com%2fgorilla/mux/v1.8.0",
+		},
+		{
+			Purl:    "pkg:gitlab/group/project@0123456789abcdef0123456789abcdef01234567",
+			License: "Apache-2.0",
+			Comment: "Imported from ClearlyDefined git/gitlab/group/project/0123456789abcdef0123456789abcdef01234567",
+		},
+	}
+	if !reflect.DeepEqual(got.Curations, wantCurations) {
+		t.Errorf("ImportClearlyDefined() curations = %+v, want %+v", got.Curations, wantCurations)
+	}
+
+	wantSkipped := []string{"npm/npmjs/-/lodash/4.17.20", "sourcearchive/github/-/zlib", "git/codeberg/team/repo"}
+	if len(got.Skipped) != len(wantSkipped) {
+		t.Fatalf("ImportClearlyDefined() skipped = %+v, want %v", got.Skipped, wantSkipped)
+	}
+	for i, s := range got.Skipped {
+		if s.ID != wantSkipped[i] || s.Reason == "" {
+			t.Errorf("ImportClearlyDefined() skipped[%d] = %+v, want ID %q with a reason", i, s, wantSkipped[i])
+		}
+	}
+}
+
+// TestImport_Invalid tests that malformed input is rejected.
+func TestImport_Invalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := curation.ImportORT([]byte("id: not-a-list")); err == nil {
+		t.Error("ImportORT() error = nil, want error")
+	}
+	if _, err := curation.ImportClearlyDefined([]byte("coordinates: [")); err == nil {
+		t.Error("ImportClearlyDefined() error = nil, want error")
+	}
+}
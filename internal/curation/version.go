@@ -0,0 +1,139 @@
+package curation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// versionRange is a set of alternatives ("||"), each a list of constraints that must all hold.
+type versionRange [][]constraint
+
+// constraint compares a version with a bound, e.g. ">=1.0.0".
+type constraint struct {
+	op      string
+	version string
+}
+
+// parseVersionRange parses a version range such as ">=1.0.0 <2.0.0 || 3.0.0".
+//
+// Constraints are separated by spaces or commas and use the operators =, !=, <, <=, > and >=; a version
+// without an operator must match exactly.
+func parseVersionRange(s string) (versionRange, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var r versionRange
+	for _, alternative := range strings.Split(s, "||") {
+		var constraints []constraint
+		for _, field := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ' ' || r == ',' }) {
+			c := constraint{op: "="}
+			for _, op := range []string{"<=", ">=", "!=", "<", ">", "="} {
+				if strings.HasPrefix(field, op) {
+					c.op = op
+					field = field[len(op):]
+					break
+				}
+			}
+			if field == "" {
+				return nil, fmt.Errorf("invalid version range %q: operator without version", s)
+			}
+			c.version = field
+			constraints = append(constraints, c)
+		}
+		if len(constraints) == 0 {
+			return nil, fmt.Errorf("invalid version range %q: empty alternative", s)
+		}
+		r = append(r, constraints)
+	}
+	return r, nil
+}
+
+// contains reports whether the version is in the range.
+func (r versionRange) contains(version string) bool {
+	for _, constraints := range r {
+		matched := true
+		for _, c := range constraints {
+			if !c.allows(version) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether the version satisfies the constraint.
+func (c constraint) allows(version string) bool {
+	cmp := compareVersions(version, c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compareVersions compares two versions in the style of semantic versioning and returns -1, 0 or 1.
+//
+// A leading "v" and build metadata ("+...") are ignored. Dot-separated parts are compared numerically if both
+// are numbers and lexically otherwise; missing parts count as zero. A pre-release ("-...") sorts before the
+// release, so 1.0.0-rc.1 < 1.0.0.
+func compareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	releaseA, preA, hasPreA := strings.Cut(a, "-")
+	releaseB, preB, hasPreB := strings.Cut(b, "-")
+
+	if cmp := compareParts(strings.Split(releaseA, "."), strings.Split(releaseB, "."), "0"); cmp != 0 {
+		return cmp
+	}
+	switch {
+	case hasPreA && !hasPreB:
+		return -1
+	case !hasPreA && hasPreB:
+		return 1
+	case !hasPreA && !hasPreB:
+		return 0
+	}
+	return compareParts(strings.Split(preA, "."), strings.Split(preB, "."), "")
+}
+
+// compareParts compares version parts one by one, padding the shorter list with pad.
+func compareParts(a, b []string, pad string) int {
+	for i := range max(len(a), len(b)) {
+		partA, partB := pad, pad
+		if i < len(a) {
+			partA = a[i]
+		}
+		if i < len(b) {
+			partB = b[i]
+		}
+
+		numA, errA := strconv.Atoi(partA)
+		numB, errB := strconv.Atoi(partB)
+		switch {
+		case errA == nil && errB == nil && numA != numB:
+			if numA < numB {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && partA != partB:
+			return strings.Compare(partA, partB)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,506 @@
+package enricher
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/version"
+)
+
+// OutputFormat is the format the enriched SBOM is written in.
+type OutputFormat string
+
+const (
+	// OutputFormatNative keeps the format of the input SBOM.
+	OutputFormatNative OutputFormat = ""
+	// OutputFormatSPDX23 writes the enriched SBOM as SPDX 2.3 JSON.
+	OutputFormatSPDX23 OutputFormat = "spdx-2.3"
+	// OutputFormatCycloneDX16 writes the enriched SBOM as CycloneDX 1.6 JSON.
+	OutputFormatCycloneDX16 OutputFormat = "cyclonedx-1.6"
+)
+
+const (
+	// spdxDocumentID is the SPDX ID of the document itself.
+	spdxDocumentID = "SPDXRef-DOCUMENT"
+	// spdxRefPrefix is the prefix of all SPDX element IDs.
+	spdxRefPrefix = "SPDXRef-"
+	// cycloneDXAcknowledgementDeclared marks a license as declared by the package author.
+	cycloneDXAcknowledgementDeclared = "declared"
+	// cycloneDXAcknowledgementConcluded marks a license as concluded by analysis.
+	cycloneDXAcknowledgementConcluded = "concluded"
+	// cycloneDXRefWebsite is the external reference type for a homepage.
+	cycloneDXRefWebsite = "website"
+	// cycloneDXRefDistribution is the external reference type for a download location.
+	cycloneDXRefDistribution = "distribution"
+	// spdxOrganizationPrefix is the prefix of an organization in SPDX supplier fields.
+	spdxOrganizationPrefix = "Organization: "
+	// spdxPersonPrefix is the prefix of a person in SPDX supplier fields.
+	spdxPersonPrefix = "Person: "
+)
+
+// ParseOutputFormat parses an output format name.
+// An empty string returns OutputFormatNative.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(s)); f {
+	case OutputFormatNative, OutputFormatSPDX23, OutputFormatCycloneDX16:
+		return f, nil
+	default:
+		return "", fmt.Errorf(
+			"unsupported output format %q: must be %q or %q",
+			s, OutputFormatSPDX23, OutputFormatCycloneDX16,
+		)
+	}
+}
+
+// cycloneDXTypeToSPDXPurpose maps CycloneDX component types to SPDX primary package purposes.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var cycloneDXTypeToSPDXPurpose = map[string]string{
+	"application":      "APPLICATION",
+	"framework":        "FRAMEWORK",
+	"library":          "LIBRARY",
+	"container":        "CONTAINER",
+	"operating-system": "OPERATING-SYSTEM",
+	"device":           "DEVICE",
+	"firmware":         "FIRMWARE",
+	"file":             "FILE",
+}
+
+// unmappedFields collects fields that cannot be represented in the target format, so they can be
+// logged once per field instead of once per item.
+type unmappedFields map[string]int
+
+// add records that field could not be mapped.
+func (u unmappedFields) add(field string) {
+	u[field]++
+}
+
+// log writes one warning per unmapped field.
+func (u unmappedFields) log(logger *slog.Logger, target OutputFormat) {
+	fields := make([]string, 0, len(u))
+	for field := range u {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		logger.Warn("field cannot be mapped to output format",
+			"field", field,
+			"format", string(target),
+			"count", u[field])
+	}
+}
+
+// addUnknownKeys records the keys of the raw JSON document (and of the items in arrayKey) that are not
+// part of the known sets, since they are dropped during conversion.
+func (u unmappedFields) addUnknownKeys(raw []byte, known map[string]bool, arrayKey string, itemKnown map[string]bool) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &top); err != nil {
+		return
+	}
+	for key := range top {
+		if !known[key] {
+			u.add(key)
+		}
+	}
+
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(top[arrayKey], &items); err != nil {
+		return
+	}
+	for _, item := range items {
+		for key := range item {
+			if !itemKnown[key] {
+				u.add(arrayKey + "[]." + key)
+			}
+		}
+	}
+}
+
+// isSPDXValue returns true if the SPDX field value carries information (it is not empty, NONE or NOASSERTION).
+func isSPDXValue(v string) bool {
+	return v != "" && v != spdxLicenseNone && v != spdxLicenseNoAssertion
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 //nolint:mnd // UUID version 4
+	b[8] = (b[8] & 0x3f) | 0x80 //nolint:mnd // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ConvertSPDXToCycloneDX converts an SPDX document into a CycloneDX 1.6 BOM.
+//
+// raw is the original (unwrapped) SPDX JSON, used to report fields that are not modelled and
+// therefore dropped. Every field that cannot be mapped is logged as a warning.
+func ConvertSPDXToCycloneDX(doc *Document, raw []byte, logger *slog.Logger) *BOM {
+	unmapped := unmappedFields{}
+	unmapped.addUnknownKeys(raw, map[string]bool{
+		"spdxVersion": true, "dataLicense": true, "SPDXID": true, "name": true,
+		"documentNamespace": true, "creationInfo": true, "packages": true, "relationships": true,
+	}, "packages", map[string]bool{
+		"SPDXID": true, "name": true, "versionInfo": true, "supplier": true, "downloadLocation": true,
+		"homepage": true, "licenseConcluded": true, "licenseDeclared": true, "copyrightText": true,
+		"description": true, "primaryPackagePurpose": true, "externalRefs": true,
+	})
+
+	bom := &BOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.6",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata:     &Metadata{},
+	}
+	if doc.CreationInfo != nil {
+		bom.Metadata.Timestamp = doc.CreationInfo.Created
+		if len(doc.CreationInfo.Creators) > 0 {
+			unmapped.add("creationInfo.creators")
+		}
+	}
+	if doc.DocumentNamespace != "" {
+		unmapped.add("documentNamespace")
+	}
+
+	// The package the document describes becomes the BOM metadata component
+	described := ""
+	for _, rel := range doc.Relationships {
+		if rel.SPDXElementID == spdxDocumentID && rel.RelationshipType == "DESCRIBES" {
+			described = rel.RelatedSPDXElement
+			break
+		}
+	}
+
+	for i := range doc.Packages {
+		component := spdxPackageToComponent(&doc.Packages[i], unmapped)
+		if component.BOMRef == described && bom.Metadata.Component == nil {
+			bom.Metadata.Component = &component
+			continue
+		}
+		bom.Components = append(bom.Components, component)
+	}
+	if bom.Metadata.Component == nil && doc.Name != "" {
+		bom.Metadata.Component = &Component{
+			Type:               "application",
+			BOMRef:             spdxDocumentID,
+			Name:               doc.Name,
+			ExternalReferences: []ExternalReference{},
+		}
+	}
+
+	bom.Dependencies = spdxRelationshipsToDependencies(doc.Relationships, unmapped)
+
+	unmapped.log(logger, OutputFormatCycloneDX16)
+	return bom
+}
+
+// spdxPackageToComponent converts a single SPDX package into a CycloneDX component.
+func spdxPackageToComponent(pkg *Package, unmapped unmappedFields) Component {
+	component := Component{
+		Type:               "library",
+		BOMRef:             pkg.SPDXID,
+		Name:               pkg.Name,
+		Version:            pkg.VersionInfo,
+		Description:        pkg.Description,
+		ExternalReferences: []ExternalReference{},
+	}
+
+	if pkg.PrimaryPackagePurpose != "" {
+		mapped := false
+		for componentType, purpose := range cycloneDXTypeToSPDXPurpose {
+			if purpose == pkg.PrimaryPackagePurpose {
+				component.Type = componentType
+				mapped = true
+			}
+		}
+		if !mapped {
+			unmapped.add("packages[].primaryPackagePurpose")
+		}
+	}
+
+	for _, ref := range pkg.ExternalRefs {
+		if ref.ReferenceType == "purl" && component.Purl == "" {
+			component.Purl = ref.ReferenceLocator
+			continue
+		}
+		unmapped.add("packages[].externalRefs[" + ref.ReferenceType + "]")
+	}
+
+	if isSPDXValue(pkg.Supplier) {
+		name := strings.TrimPrefix(strings.TrimPrefix(pkg.Supplier, spdxOrganizationPrefix), spdxPersonPrefix)
+		component.Supplier = &OrganizationalEntity{Name: name}
+	}
+	if isSPDXValue(pkg.CopyrightText) {
+		component.Copyright = pkg.CopyrightText
+	}
+	if isSPDXValue(pkg.Homepage) {
+		component.ExternalReferences = append(component.ExternalReferences, ExternalReference{
+			URL:  pkg.Homepage,
+			Type: cycloneDXRefWebsite,
+		})
+	}
+	if isSPDXValue(pkg.DownloadLocation) {
+		component.ExternalReferences = append(component.ExternalReferences, ExternalReference{
+			URL:  pkg.DownloadLocation,
+			Type: cycloneDXRefDistribution,
+		})
+	}
+
+	// CycloneDX 1.6 distinguishes declared and concluded licenses, so both are kept
+	if isSPDXValue(pkg.LicenseConcluded) {
+		component.Licenses = append(component.Licenses, LicenseChoice{
+			Expression:      pkg.LicenseConcluded,
+			Acknowledgement: cycloneDXAcknowledgementConcluded,
+		})
+	}
+	if isSPDXValue(pkg.LicenseDeclared) && pkg.LicenseDeclared != pkg.LicenseConcluded {
+		component.Licenses = append(component.Licenses, LicenseChoice{
+			Expression:      pkg.LicenseDeclared,
+			Acknowledgement: cycloneDXAcknowledgementDeclared,
+		})
+	}
+
+	return component
+}
+
+// spdxRelationshipsToDependencies converts SPDX dependency relationships into CycloneDX dependencies.
+func spdxRelationshipsToDependencies(relationships []Relationship, unmapped unmappedFields) []Dependency {
+	dependsOn := map[string][]string{}
+	var refs []string
+
+	addDependency := func(from, to string) {
+		if _, ok := dependsOn[from]; !ok {
+			refs = append(refs, from)
+		}
+		dependsOn[from] = append(dependsOn[from], to)
+	}
+
+	for _, rel := range relationships {
+		switch rel.RelationshipType {
+		case "DEPENDS_ON":
+			addDependency(rel.SPDXElementID, rel.RelatedSPDXElement)
+		case "DEPENDENCY_OF":
+			addDependency(rel.RelatedSPDXElement, rel.SPDXElementID)
+		case "DESCRIBES":
+			// Mapped to the metadata component
+		default:
+			unmapped.add("relationships[" + rel.RelationshipType + "]")
+		}
+	}
+
+	dependencies := make([]Dependency, 0, len(refs))
+	for _, ref := range refs {
+		dependencies = append(dependencies, Dependency{Ref: ref, DependsOn: dependsOn[ref]})
+	}
+	return dependencies
+}
+
+// ConvertCycloneDXToSPDX converts a CycloneDX BOM into an SPDX 2.3 document.
+//
+// raw is the original CycloneDX JSON, used to report fields that are not modelled and therefore dropped.
+// Every field that cannot be mapped is logged as a warning.
+func ConvertCycloneDXToSPDX(bom *BOM, raw []byte, logger *slog.Logger) *Document {
+	unmapped := unmappedFields{}
+	unmapped.addUnknownKeys(raw, map[string]bool{
+		"bomFormat": true, "specVersion": true, "serialNumber": true, "version": true,
+		"metadata": true, "components": true, "dependencies": true, "$schema": true,
+	}, "components", map[string]bool{
+		"type": true, "bom-ref": true, "supplier": true, "name": true, "version": true,
+		"description": true, "scope": true, "licenses": true, "copyright": true, "purl": true,
+		"externalReferences": true,
+	})
+
+	doc := &Document{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      spdxDocumentID,
+		Name:        "sbomlicense-converted",
+		CreationInfo: &CreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: sbomlicense-" + version.Get()},
+		},
+	}
+
+	namespaceID := strings.TrimPrefix(bom.SerialNumber, "urn:uuid:")
+	if namespaceID == "" {
+		namespaceID = newUUID()
+	}
+
+	ids := spdxIDAllocator{used: map[string]bool{spdxDocumentID: true}, byRef: map[string]string{}}
+
+	if bom.Metadata != nil {
+		if bom.Metadata.Timestamp != "" {
+			doc.CreationInfo.Created = bom.Metadata.Timestamp
+		}
+		if len(bom.Metadata.Tools) > 0 {
+			unmapped.add("metadata.tools")
+		}
+		if bom.Metadata.Component != nil {
+			root := componentToSPDXPackage(bom.Metadata.Component, ids.allocate(bom.Metadata.Component), unmapped)
+			doc.Name = root.Name
+			doc.Packages = append(doc.Packages, root)
+			doc.Relationships = append(doc.Relationships, Relationship{
+				SPDXElementID:      spdxDocumentID,
+				RelationshipType:   "DESCRIBES",
+				RelatedSPDXElement: root.SPDXID,
+			})
+		}
+	}
+	doc.DocumentNamespace = "https://spdx.org/spdxdocs/" + doc.Name + "-" + namespaceID
+
+	for i := range bom.Components {
+		doc.Packages = append(doc.Packages, componentToSPDXPackage(
+			&bom.Components[i], ids.allocate(&bom.Components[i]), unmapped,
+		))
+	}
+
+	for _, dependency := range bom.Dependencies {
+		from, ok := ids.byRef[dependency.Ref]
+		if !ok {
+			unmapped.add("dependencies[].ref")
+			continue
+		}
+		for _, ref := range dependency.DependsOn {
+			to, toOK := ids.byRef[ref]
+			if !toOK {
+				unmapped.add("dependencies[].dependsOn")
+				continue
+			}
+			doc.Relationships = append(doc.Relationships, Relationship{
+				SPDXElementID:      from,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: to,
+			})
+		}
+	}
+
+	unmapped.log(logger, OutputFormatSPDX23)
+	return doc
+}
+
+// spdxIDAllocator assigns unique SPDX IDs to CycloneDX components.
+type spdxIDAllocator struct {
+	used  map[string]bool
+	byRef map[string]string
+}
+
+// allocate returns a unique SPDX ID for the component and remembers it by BOM reference.
+func (a *spdxIDAllocator) allocate(component *Component) string {
+	base := component.BOMRef
+	if base == "" {
+		base = component.Name + "-" + component.Version
+	}
+
+	// SPDX IDs may only contain letters, numbers, "." and "-"
+	var b strings.Builder
+	for _, r := range base {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	name := strings.TrimPrefix(b.String(), spdxRefPrefix)
+	id := spdxRefPrefix + name
+	for n := 2; a.used[id]; n++ {
+		id = fmt.Sprintf("%s%s-%d", spdxRefPrefix, name, n)
+	}
+
+	a.used[id] = true
+	if component.BOMRef != "" {
+		a.byRef[component.BOMRef] = id
+	}
+	return id
+}
+
+// componentToSPDXPackage converts a single CycloneDX component into an SPDX package.
+func componentToSPDXPackage(component *Component, spdxID string, unmapped unmappedFields) Package {
+	pkg := Package{
+		SPDXID:                spdxID,
+		Name:                  component.Name,
+		VersionInfo:           component.Version,
+		Supplier:              spdxLicenseNoAssertion,
+		DownloadLocation:      spdxLicenseNoAssertion,
+		LicenseConcluded:      spdxLicenseNoAssertion,
+		LicenseDeclared:       spdxLicenseNoAssertion,
+		CopyrightText:         spdxLicenseNoAssertion,
+		Description:           component.Description,
+		PrimaryPackagePurpose: cycloneDXTypeToSPDXPurpose[component.Type],
+	}
+	if component.Type != "" && pkg.PrimaryPackagePurpose == "" {
+		unmapped.add("components[].type")
+	}
+	if component.Scope != "" {
+		unmapped.add("components[].scope")
+	}
+	if component.Supplier != nil && component.Supplier.Name != "" {
+		pkg.Supplier = spdxOrganizationPrefix + component.Supplier.Name
+	}
+	if component.Copyright != "" {
+		pkg.CopyrightText = component.Copyright
+	}
+	if component.Purl != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, ExternalRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  component.Purl,
+		})
+	}
+
+	for _, ref := range component.ExternalReferences {
+		switch {
+		case ref.Type == cycloneDXRefWebsite && pkg.Homepage == "":
+			pkg.Homepage = ref.URL
+		case ref.Type == cycloneDXRefDistribution && pkg.DownloadLocation == spdxLicenseNoAssertion:
+			pkg.DownloadLocation = ref.URL
+		default:
+			unmapped.add("components[].externalReferences[" + ref.Type + "]")
+		}
+	}
+
+	var declared, concluded []string
+	for _, choice := range component.Licenses {
+		value := choice.Expression
+		if choice.License != nil {
+			switch {
+			case choice.License.ID != "":
+				value = choice.License.ID
+			case choice.License.Expression != "":
+				value = choice.License.Expression
+			default:
+				// A license name is not an SPDX identifier
+				unmapped.add("components[].licenses[].license.name")
+				continue
+			}
+			if choice.License.Text != nil {
+				unmapped.add("components[].licenses[].license.text")
+			}
+		}
+		if value == "" {
+			continue
+		}
+		if choice.Acknowledgement == cycloneDXAcknowledgementDeclared {
+			declared = append(declared, value)
+		} else {
+			concluded = append(concluded, value)
+		}
+	}
+	if len(concluded) > 0 {
+		pkg.LicenseConcluded = license.Join(license.OperatorAnd, concluded)
+	}
+	if len(declared) > 0 {
+		pkg.LicenseDeclared = license.Join(license.OperatorAnd, declared)
+	} else if len(concluded) > 0 {
+		pkg.LicenseDeclared = pkg.LicenseConcluded
+	}
+
+	return pkg
+}
@@ -0,0 +1,389 @@
+package enricher_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+)
+
+// TestParseOutputFormat tests the ParseOutputFormat function.
+func TestParseOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    enricher.OutputFormat
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: enricher.OutputFormatNative},
+		{name: "spdx", input: "spdx-2.3", want: enricher.OutputFormatSPDX23},
+		{name: "cyclonedx", input: "cyclonedx-1.6", want: enricher.OutputFormatCycloneDX16},
+		{name: "case insensitive", input: "CycloneDX-1.6", want: enricher.OutputFormatCycloneDX16},
+		{name: "unknown", input: "spdx-3.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := enricher.ParseOutputFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOutputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOutputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertSPDXToCycloneDX tests converting an SPDX document into a CycloneDX BOM.
+func TestConvertSPDXToCycloneDX(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"spdxVersion": "SPDX-2.3",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "app",
+		"documentNamespace": "https://example.com/app",
+		"creationInfo": {"created": "2024-01-15T10:00:00Z", "creators": ["Tool: test"]},
+		"packages": [
+			{
+				"SPDXID": "SPDXRef-app",
+				"name": "app",
+				"versionInfo": "1.0.0",
+				"primaryPackagePurpose": "APPLICATION",
+				"licenseConcluded": "Apache-2.0",
+				"licenseDeclared": "Apache-2.0"
+			},
+			{
+				"SPDXID": "SPDXRef-lodash",
+				"name": "lodash",
+				"versionInfo": "4.17.21",
+				"supplier": "Organization: OpenJS",
+				"homepage": "https://lodash.com",
+				"copyrightText": "Copyright OpenJS",
+				"licenseConcluded": "MIT",
+				"licenseDeclared": "MIT OR Apache-2.0",
+				"filesAnalyzed": false,
+				"externalRefs": [
+					{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash@4.17.21"},
+					{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:lodash"}
+				]
+			}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-app"},
+			{"spdxElementId": "SPDXRef-app", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-lodash"},
+			{"spdxElementId": "SPDXRef-app", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-lodash"}
+		]
+	}`)
+
+	doc, err := enricher.ParseSBOMFile(raw)
+	if err != nil {
+		t.Fatalf("ParseSBOMFile() error = %v", err)
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	bom := enricher.ConvertSPDXToCycloneDX(doc, raw, logger)
+
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.6" {
+		t.Errorf("BOM format = %s %s, want CycloneDX 1.6", bom.BOMFormat, bom.SpecVersion)
+	}
+	if !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") {
+		t.Errorf("SerialNumber = %q, want urn:uuid prefix", bom.SerialNumber)
+	}
+	if bom.Metadata == nil || bom.Metadata.Component == nil || bom.Metadata.Component.Name != "app" {
+		t.Fatalf("Metadata.Component = %+v, want app", bom.Metadata)
+	}
+	if bom.Metadata.Component.Type != "application" {
+		t.Errorf("Metadata.Component.Type = %q, want application", bom.Metadata.Component.Type)
+	}
+	if bom.Metadata.Timestamp != "2024-01-15T10:00:00Z" {
+		t.Errorf("Metadata.Timestamp = %q", bom.Metadata.Timestamp)
+	}
+
+	if len(bom.Components) != 1 {
+		t.Fatalf("len(Components) = %d, want 1", len(bom.Components))
+	}
+	lodash := bom.Components[0]
+	if lodash.Purl != "pkg:npm/lodash@4.17.21" || lodash.Version != "4.17.21" {
+		t.Errorf("component = %+v, want lodash purl and version", lodash)
+	}
+	if lodash.Supplier == nil || lodash.Supplier.Name != "OpenJS" {
+		t.Errorf("Supplier = %+v, want OpenJS", lodash.Supplier)
+	}
+	if lodash.Copyright != "Copyright OpenJS" {
+		t.Errorf("Copyright = %q", lodash.Copyright)
+	}
+	if len(lodash.ExternalReferences) != 1 || lodash.ExternalReferences[0].Type != "website" {
+		t.Errorf("ExternalReferences = %+v, want website", lodash.ExternalReferences)
+	}
+	wantLicenses := enricher.Licenses{
+		{Expression: "MIT", Acknowledgement: "concluded"},
+		{Expression: "MIT OR Apache-2.0", Acknowledgement: "declared"},
+	}
+	if len(lodash.Licenses) != len(wantLicenses) {
+		t.Fatalf("Licenses = %+v, want %+v", lodash.Licenses, wantLicenses)
+	}
+	for i := range wantLicenses {
+		if lodash.Licenses[i] != wantLicenses[i] {
+			t.Errorf("Licenses[%d] = %+v, want %+v", i, lodash.Licenses[i], wantLicenses[i])
+		}
+	}
+
+	if len(bom.Dependencies) != 1 || bom.Dependencies[0].Ref != "SPDXRef-app" ||
+		len(bom.Dependencies[0].DependsOn) != 1 || bom.Dependencies[0].DependsOn[0] != "SPDXRef-lodash" {
+		t.Errorf("Dependencies = %+v, want app -> lodash", bom.Dependencies)
+	}
+
+	// Fields that cannot be mapped must be logged
+	for _, field := range []string{
+		"packages[].filesAnalyzed",
+		"packages[].externalRefs[cpe23Type]",
+		"relationships[CONTAINS]",
+		"documentNamespace",
+	} {
+		if !strings.Contains(logs.String(), "field="+field) {
+			t.Errorf("logs do not mention unmapped field %q:\n%s", field, logs.String())
+		}
+	}
+}
+
+// TestConvertCycloneDXToSPDX tests converting a CycloneDX BOM into an SPDX document.
+func TestConvertCycloneDXToSPDX(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		"metadata": {
+			"timestamp": "2024-01-15T10:00:00Z",
+			"component": {"type": "application", "bom-ref": "app", "name": "my-app", "version": "2.0.0"}
+		},
+		"components": [
+			{
+				"type": "library",
+				"bom-ref": "pkg:npm/react@18.2.0",
+				"name": "react",
+				"version": "18.2.0",
+				"scope": "required",
+				"purl": "pkg:npm/react@18.2.0",
+				"hashes": [{"alg": "SHA-256", "content": "abc"}],
+				"licenses": [{"license": {"id": "MIT"}}, {"license": {"name": "Custom"}}],
+				"externalReferences": [
+					{"type": "website", "url": "https://react.dev"},
+					{"type": "vcs", "url": "https://github.com/facebook/react"}
+				]
+			},
+			{
+				"type": "library",
+				"bom-ref": "pkg:npm/react@18.2.0",
+				"name": "react-dup",
+				"version": "18.2.0",
+				"licenses": [{"expression": "MIT OR Apache-2.0", "acknowledgement": "declared"}]
+			}
+		],
+		"dependencies": [
+			{"ref": "app", "dependsOn": ["pkg:npm/react@18.2.0"]}
+		]
+	}`)
+
+	bom, err := enricher.ParseCycloneDXFile(raw)
+	if err != nil {
+		t.Fatalf("ParseCycloneDXFile() error = %v", err)
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	doc := enricher.ConvertCycloneDXToSPDX(bom, raw, logger)
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.SPDXID != "SPDXRef-DOCUMENT" || doc.DataLicense != "CC0-1.0" {
+		t.Errorf("document header = %s %s %s", doc.SPDXVersion, doc.SPDXID, doc.DataLicense)
+	}
+	if doc.Name != "my-app" {
+		t.Errorf("Name = %q, want my-app", doc.Name)
+	}
+	if !strings.HasSuffix(doc.DocumentNamespace, "3e671687-395b-41f5-a30f-a58921a69b79") {
+		t.Errorf("DocumentNamespace = %q, want serial number suffix", doc.DocumentNamespace)
+	}
+	if doc.CreationInfo == nil || doc.CreationInfo.Created != "2024-01-15T10:00:00Z" {
+		t.Errorf("CreationInfo = %+v", doc.CreationInfo)
+	}
+
+	if len(doc.Packages) != 3 {
+		t.Fatalf("len(Packages) = %d, want 3", len(doc.Packages))
+	}
+	root, react, dup := doc.Packages[0], doc.Packages[1], doc.Packages[2]
+	if root.SPDXID != "SPDXRef-app" || root.PrimaryPackagePurpose != "APPLICATION" {
+		t.Errorf("root package = %+v", root)
+	}
+	if react.SPDXID != "SPDXRef-pkg-npm-react-18.2.0" {
+		t.Errorf("react SPDXID = %q", react.SPDXID)
+	}
+	if dup.SPDXID == react.SPDXID {
+		t.Errorf("duplicate bom-ref produced duplicate SPDXID %q", dup.SPDXID)
+	}
+	if react.LicenseConcluded != "MIT" || react.LicenseDeclared != "MIT" {
+		t.Errorf("react licenses = %q / %q, want MIT", react.LicenseConcluded, react.LicenseDeclared)
+	}
+	if dup.LicenseDeclared != "MIT OR Apache-2.0" || dup.LicenseConcluded != "NOASSERTION" {
+		t.Errorf("dup licenses = %q / %q", dup.LicenseConcluded, dup.LicenseDeclared)
+	}
+	if react.Homepage != "https://react.dev" {
+		t.Errorf("Homepage = %q", react.Homepage)
+	}
+	if purl, purlErr := react.GetPurl(); purlErr != nil || purl != "pkg:npm/react@18.2.0" {
+		t.Errorf("GetPurl() = %q, %v", purl, purlErr)
+	}
+
+	var describes, dependsOn int
+	for _, rel := range doc.Relationships {
+		switch rel.RelationshipType {
+		case "DESCRIBES":
+			describes++
+		case "DEPENDS_ON":
+			dependsOn++
+			if rel.SPDXElementID != "SPDXRef-app" {
+				t.Errorf("DEPENDS_ON from %q, want SPDXRef-app", rel.SPDXElementID)
+			}
+		}
+	}
+	if describes != 1 || dependsOn != 1 {
+		t.Errorf("relationships = %+v, want 1 DESCRIBES and 1 DEPENDS_ON", doc.Relationships)
+	}
+
+	for _, field := range []string{
+		"components[].hashes",
+		"components[].scope",
+		"components[].licenses[].license.name",
+		"components[].externalReferences[vcs]",
+	} {
+		if !strings.Contains(logs.String(), "field="+field) {
+			t.Errorf("logs do not mention unmapped field %q:\n%s", field, logs.String())
+		}
+	}
+}
+
+// TestConvertCycloneDXToSPDX_DuplicateSPDXRefs tests that duplicate BOM references that already are SPDX IDs get
+// unique SPDX IDs without a repeated prefix.
+func TestConvertCycloneDXToSPDX_DuplicateSPDXRefs(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [
+			{"type": "library", "bom-ref": "SPDXRef-foo", "name": "foo", "version": "1.0.0"},
+			{"type": "library", "bom-ref": "SPDXRef-foo", "name": "foo-dup", "version": "1.0.0"}
+		]
+	}`)
+
+	bom, err := enricher.ParseCycloneDXFile(raw)
+	if err != nil {
+		t.Fatalf("ParseCycloneDXFile() error = %v", err)
+	}
+	doc := enricher.ConvertCycloneDXToSPDX(bom, raw, noopLogger())
+
+	var ids []string
+	for _, pkg := range doc.Packages {
+		ids = append(ids, pkg.SPDXID)
+	}
+	if want := []string{"SPDXRef-foo", "SPDXRef-foo-2"}; !slices.Equal(ids, want) {
+		t.Errorf("SPDX IDs = %v, want %v", ids, want)
+	}
+}
+
+// TestEnrich_OutputFormat tests that enrichment converts the output when requested.
+func TestEnrich_OutputFormat(t *testing.T) {
+	t.Parallel()
+
+	prov := &mockProvider{
+		getLicense: func(_ context.Context, _ string) (string, error) {
+			return "MIT", nil
+		},
+	}
+
+	spdxInput := []byte(`{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT", "packages": [
+		{"SPDXID": "SPDXRef-a", "name": "a", "externalRefs": [
+			{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/a@1.0.0"}
+		]}
+	]}`)
+	cdxInput := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4", "components": [
+		{"bom-ref": "a", "name": "a", "purl": "pkg:npm/a@1.0.0"}
+	]}`)
+	emptyCDXInput := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4"}`)
+
+	tests := []struct {
+		name       string
+		enricher   enricher.Enricher
+		input      []byte
+		format     enricher.OutputFormat
+		wantMarker string
+	}{
+		{
+			name:       "SPDX to CycloneDX",
+			enricher:   enricher.NewSPDXEnricher(prov, &mockCache{}, time.Hour),
+			input:      spdxInput,
+			format:     enricher.OutputFormatCycloneDX16,
+			wantMarker: `"specVersion":"1.6"`,
+		},
+		{
+			name:       "SPDX version upgrade",
+			enricher:   enricher.NewSPDXEnricher(prov, &mockCache{}, time.Hour),
+			input:      spdxInput,
+			format:     enricher.OutputFormatSPDX23,
+			wantMarker: `"spdxVersion":"SPDX-2.3"`,
+		},
+		{
+			name:       "CycloneDX to SPDX",
+			enricher:   enricher.NewCycloneDXEnricher(prov, &mockCache{}, time.Hour),
+			input:      cdxInput,
+			format:     enricher.OutputFormatSPDX23,
+			wantMarker: `"spdxVersion":"SPDX-2.3"`,
+		},
+		{
+			name:       "empty CycloneDX to SPDX",
+			enricher:   enricher.NewCycloneDXEnricher(prov, &mockCache{}, time.Hour),
+			input:      emptyCDXInput,
+			format:     enricher.OutputFormatSPDX23,
+			wantMarker: `"spdxVersion":"SPDX-2.3"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := tt.enricher.Enrich(context.Background(), enricher.Options{
+				SBOM:         tt.input,
+				Logger:       noopLogger(),
+				OutputFormat: tt.format,
+			})
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+			if !json.Valid(result) {
+				t.Fatalf("Enrich() returned invalid JSON: %s", result)
+			}
+			if !strings.Contains(string(result), tt.wantMarker) {
+				t.Errorf("Enrich() = %s, want to contain %s", result, tt.wantMarker)
+			}
+			if len(tt.input) != len(emptyCDXInput) && !strings.Contains(string(result), "MIT") {
+				t.Errorf("Enrich() = %s, want enriched MIT license", result)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
@@ -14,19 +15,46 @@ import (
 
 // BOM represents a minimal CycloneDX Bill of Materials with only the fields we need.
 type BOM struct {
-	BOMFormat   string      `json:"bomFormat"`
-	SpecVersion string      `json:"specVersion"`
-	Components  []Component `json:"components,omitempty"`
+	BOMFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	SerialNumber string       `json:"serialNumber,omitempty"`
+	Version      int          `json:"version,omitempty"`
+	Metadata     *Metadata    `json:"metadata,omitempty"`
+	Components   []Component  `json:"components,omitempty"`
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+}
+
+// Metadata represents the CycloneDX BOM metadata.
+type Metadata struct {
+	Timestamp string          `json:"timestamp,omitempty"`
+	Tools     json.RawMessage `json:"tools,omitempty"`
+	Component *Component      `json:"component,omitempty"`
+}
+
+// Dependency represents the dependencies of a single CycloneDX component.
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // Component represents a minimal CycloneDX component with only the fields we need.
 type Component struct {
-	BOMRef             string              `json:"bom-ref"`
-	Name               string              `json:"name"`
-	Version            string              `json:"version"`
-	Purl               string              `json:"purl"`
-	Licenses           Licenses            `json:"licenses,omitempty"`
-	ExternalReferences []ExternalReference `json:"externalReferences"`
+	Type               string                `json:"type,omitempty"`
+	BOMRef             string                `json:"bom-ref"`
+	Supplier           *OrganizationalEntity `json:"supplier,omitempty"`
+	Name               string                `json:"name"`
+	Version            string                `json:"version"`
+	Description        string                `json:"description,omitempty"`
+	Scope              string                `json:"scope,omitempty"`
+	Licenses           Licenses              `json:"licenses,omitempty"`
+	Copyright          string                `json:"copyright,omitempty"`
+	Purl               string                `json:"purl"`
+	ExternalReferences []ExternalReference   `json:"externalReferences"`
+}
+
+// OrganizationalEntity represents an organization such as a supplier.
+type OrganizationalEntity struct {
+	Name string `json:"name,omitempty"`
 }
 
 // ExternalReference represents an external reference with a URL and type.
@@ -42,6 +70,8 @@ type Licenses []LicenseChoice
 type LicenseChoice struct {
 	License    *License `json:"license,omitempty"`
 	Expression string   `json:"expression,omitempty"`
+	// Acknowledgement is either "declared" or "concluded" (CycloneDX 1.6+).
+	Acknowledgement string `json:"acknowledgement,omitempty"`
 }
 
 // License represents a license with various identification methods.
@@ -54,7 +84,10 @@ type License struct {
 
 // LicenseText represents license text content.
 type LicenseText struct {
-	Content string `json:"content"`
+	ContentType string `json:"contentType,omitempty"`
+	// Encoding is "base64" if the content is base64 encoded, or empty for plain text.
+	Encoding string `json:"encoding,omitempty"`
+	Content  string `json:"content"`
 }
 
 // GetPurl extracts the purl from the CycloneDX component.
@@ -80,6 +113,11 @@ func (c *Component) GetLogID() string {
 	return c.BOMRef
 }
 
+// filterAttributes returns the attributes filter rules are matched against.
+func (c *Component) filterAttributes() filterAttributes {
+	return filterAttributes{name: c.Name, scope: c.Scope}
+}
+
 // ParseCycloneDXFile parses the CycloneDX file into a CycloneDX BOM.
 func ParseCycloneDXFile(data []byte) (*BOM, error) {
 	// Parse the JSON into a CycloneDX BOM
@@ -165,7 +203,21 @@ func (s *CycloneDXEnricher) Enrich(ctx context.Context, opts Options) ([]byte, e
 		s.provider,
 		s.cache,
 		s.cacheTTL,
-		func(b *BOM) ([]byte, error) {
+		func(b *BOM, logger *slog.Logger) ([]byte, error) {
+			switch opts.OutputFormat {
+			case OutputFormatSPDX23:
+				doc := ConvertCycloneDXToSPDX(b, opts.SBOM, logger)
+				if opts.LicenseTexts != LicenseTextNone {
+					embedSPDXLicenseTexts(doc, logger)
+				}
+				return json.Marshal(doc)
+			case OutputFormatCycloneDX16:
+				b.SpecVersion = "1.6"
+			case OutputFormatNative:
+			}
+			if opts.LicenseTexts != LicenseTextNone {
+				embedCycloneDXLicenseTexts(b, opts.LicenseTexts, logger)
+			}
 			return json.Marshal(b)
 		},
 	)
@@ -4,13 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
 	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/provider"
 )
 
 // TestParseCycloneDXFile tests the ParseCycloneDXFile function.
@@ -1368,3 +1376,266 @@ func TestCycloneDXEnricher_Enrich_NilLogger(t *testing.T) {
 
 // Context cancellation stops individual provider calls but doesn't fail the
 // overall enrichment - errors are logged. This is by design for resilience.
+
+// TestCycloneDXEnricher_Enrich_BatchProvider tests that items are looked up in one batch if the provider supports
+// it, skipping items with a license or a cached license, and one by one if the batch fails.
+func TestCycloneDXEnricher_Enrich_BatchProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		batchErr error
+		wantGets int32
+	}{
+		{name: "batch"},
+		{name: "batch failed", batchErr: errors.New("API error: HTTP 500"), wantGets: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var batches [][]string
+			var gets atomic.Int32
+			prov := &mockBatchProvider{
+				mockProvider: mockProvider{
+					getLicense: func(_ context.Context, _ string) (string, error) {
+						gets.Add(1)
+						return "MIT", nil
+					},
+				},
+				getMany: func(_ context.Context, purls []string) (map[string]provider.BatchResult, error) {
+					batches = append(batches, purls)
+					if tt.batchErr != nil {
+						return nil, tt.batchErr
+					}
+					results := map[string]provider.BatchResult{}
+					for _, purl := range purls {
+						results[purl] = provider.BatchResult{License: "MIT"}
+					}
+					return results, nil
+				},
+			}
+			c := &mockCache{
+				getFunc: func(key string) (string, error) {
+					if key == "pkg:npm/cached@1.0.0" {
+						return "Apache-2.0", nil
+					}
+					return "", cache.ErrCacheMiss
+				},
+			}
+
+			report := &enricher.Report{}
+			e := enricher.NewCycloneDXEnricher(prov, c, time.Hour)
+			_, err := e.Enrich(context.Background(), enricher.Options{
+				SBOM: []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+					{"bom-ref": "existing", "name": "existing", "purl": "pkg:npm/existing@1.0.0", "licenses": [{"expression": "ISC"}]},
+					{"bom-ref": "cached", "name": "cached", "purl": "pkg:npm/cached@1.0.0"},
+					{"bom-ref": "lodash", "name": "lodash", "purl": "pkg:npm/lodash@4.17.21"},
+					{"bom-ref": "express", "name": "express", "purl": "pkg:npm/express@4.18.2"}
+				]}`),
+				Parallelism: 2,
+				Logger:      noopLogger(),
+				Report:      report,
+			})
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+
+			wantBatches := [][]string{{"pkg:npm/lodash@4.17.21", "pkg:npm/express@4.18.2"}}
+			if !reflect.DeepEqual(batches, wantBatches) {
+				t.Errorf("batches = %v, want %v", batches, wantBatches)
+			}
+			if gets.Load() != tt.wantGets {
+				t.Errorf("Get() called %d times, want %d", gets.Load(), tt.wantGets)
+			}
+			wantStatuses := []enricher.ItemStatus{
+				enricher.StatusExistingLicense, enricher.StatusEnriched, enricher.StatusEnriched, enricher.StatusEnriched,
+			}
+			if len(report.Items) != len(wantStatuses) {
+				t.Fatalf("report.Items = %+v, want %d items", report.Items, len(wantStatuses))
+			}
+			for i, item := range report.Items {
+				if item.Status != wantStatuses[i] {
+					t.Errorf("report.Items[%d] = %+v, want status %s", i, item, wantStatuses[i])
+				}
+			}
+		})
+	}
+}
+
+// TestCycloneDXEnricher_Enrich_BulkLookupFailed tests that items are looked up one by one if the bulk lookup of
+// the Ecosystems API fails or does not exist.
+func TestCycloneDXEnricher_Enrich_BulkLookupFailed(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusNotFound} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			t.Parallel()
+
+			var bulkCalls, getCalls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/packages/bulk_lookup" {
+					bulkCalls.Add(1)
+					w.WriteHeader(status)
+					return
+				}
+				getCalls.Add(1)
+				_, _ = w.Write([]byte(`[{"normalized_licenses": ["MIT"]}]`))
+			}))
+			t.Cleanup(server.Close)
+
+			client := provider.NewClient(provider.ClientOptions{
+				BaseURL: server.URL,
+				Retry:   provider.RetryOptions{MaxRetries: -1},
+			})
+			report := &enricher.Report{}
+			e := enricher.NewCycloneDXEnricher(client, &mockCache{}, time.Hour)
+			_, err := e.Enrich(context.Background(), enricher.Options{
+				SBOM: []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+					{"bom-ref": "lodash", "name": "lodash", "purl": "pkg:npm/lodash@4.17.21"},
+					{"bom-ref": "express", "name": "express", "purl": "pkg:npm/express@4.18.2"}
+				]}`),
+				Parallelism: 2,
+				Logger:      noopLogger(),
+				Report:      report,
+			})
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+
+			if bulkCalls.Load() != 1 || getCalls.Load() != 2 {
+				t.Errorf("bulk lookups = %d, lookups = %d, want 1 and 2", bulkCalls.Load(), getCalls.Load())
+			}
+			if len(report.Items) != 2 {
+				t.Fatalf("report.Items = %+v, want 2 items", report.Items)
+			}
+			for _, item := range report.Items {
+				if item.Status != enricher.StatusEnriched || item.License != "MIT" {
+					t.Errorf("report item = %+v, want enriched with MIT", item)
+				}
+			}
+		})
+	}
+}
+
+// TestCycloneDXEnricher_Enrich_Batches tests that many items are looked up in several batches.
+func TestCycloneDXEnricher_Enrich_Batches(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var sizes []int
+	prov := &mockBatchProvider{
+		getMany: func(_ context.Context, purls []string) (map[string]provider.BatchResult, error) {
+			mu.Lock()
+			sizes = append(sizes, len(purls))
+			mu.Unlock()
+			results := map[string]provider.BatchResult{}
+			for _, purl := range purls {
+				results[purl] = provider.BatchResult{License: "MIT"}
+			}
+			return results, nil
+		},
+	}
+
+	components := make([]string, 250)
+	for i := range components {
+		components[i] = fmt.Sprintf(`{"bom-ref": "c%d", "name": "c%d", "purl": "pkg:npm/c%d@1.0.0"}`, i, i, i)
+	}
+	report := &enricher.Report{}
+	e := enricher.NewCycloneDXEnricher(prov, &mockCache{}, time.Hour)
+	_, err := e.Enrich(context.Background(), enricher.Options{
+		SBOM: []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [` +
+			strings.Join(components, ",") + `]}`),
+		Parallelism: 2,
+		Logger:      noopLogger(),
+		Report:      report,
+	})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	slices.Sort(sizes)
+	if want := []int{50, 100, 100}; !slices.Equal(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+	if len(report.Items) != len(components) {
+		t.Fatalf("report.Items has %d items, want %d", len(report.Items), len(components))
+	}
+	for _, item := range report.Items {
+		if item.Status != enricher.StatusEnriched {
+			t.Errorf("report item = %+v, want enriched", item)
+		}
+	}
+}
+
+// TestCycloneDXEnricher_Enrich_ChainBulkLookup tests that the Ecosystems provider of a chain looks up the items with
+// the bulk lookup endpoint.
+func TestCycloneDXEnricher_Enrich_ChainBulkLookup(t *testing.T) {
+	t.Parallel()
+
+	var bulkCalls, getCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/packages/bulk_lookup" {
+			getCalls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		bulkCalls.Add(1)
+		var request struct {
+			Purls []string `json:"purls"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		packages := []map[string]any{}
+		for _, purl := range request.Purls {
+			name, _, _ := strings.Cut(purl, "@")
+			packages = append(packages, map[string]any{"purl": name, "normalized_licenses": []string{"MIT"}})
+		}
+		_ = json.NewEncoder(w).Encode(packages)
+	}))
+	t.Cleanup(server.Close)
+
+	config, err := provider.ParseChainConfig([]byte(`providers:
+  - provider: depsdev
+    types: [golang]
+    url: ` + server.URL + `
+  - provider: ecosystems
+    url: ` + server.URL + "\n"))
+	if err != nil {
+		t.Fatalf("ParseChainConfig() error = %v", err)
+	}
+	chain, err := config.Chain(provider.ChainOptions{})
+	if err != nil {
+		t.Fatalf("Chain() error = %v", err)
+	}
+
+	report := &enricher.Report{}
+	e := enricher.NewCycloneDXEnricher(chain, &mockCache{}, time.Hour)
+	_, err = e.Enrich(context.Background(), enricher.Options{
+		SBOM: []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+			{"bom-ref": "lodash", "name": "lodash", "purl": "pkg:npm/lodash@4.17.21"},
+			{"bom-ref": "express", "name": "express", "purl": "pkg:npm/express@4.18.2"}
+		]}`),
+		Parallelism: 2,
+		Logger:      noopLogger(),
+		Report:      report,
+	})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	if bulkCalls.Load() != 1 || getCalls.Load() != 0 {
+		t.Errorf("bulk lookups = %d, lookups = %d, want 1 and 0", bulkCalls.Load(), getCalls.Load())
+	}
+	if len(report.Items) != 2 {
+		t.Fatalf("report.Items = %+v, want 2 items", report.Items)
+	}
+	for _, item := range report.Items {
+		if item.Status != enricher.StatusEnriched || item.License != "MIT" || item.Provider != "ecosystems" {
+			t.Errorf("report item = %+v, want enriched with MIT by ecosystems", item)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package enricher
 import (
 	"context"
 	"log/slog"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
 )
 
 // Options are the options for enriching the SBOM with license information.
@@ -17,6 +19,26 @@ type Options struct {
 	//
 	// If <= 0, defaults to 1 (sequential processing).
 	Parallelism int
+	// OutputFormat is the format the enriched SBOM is written in.
+	//
+	// If empty, the enriched SBOM keeps the format of the input.
+	OutputFormat OutputFormat
+	// LicenseTexts is the encoding of the full license texts embedded into the enriched SBOM.
+	//
+	// If empty, no license texts are embedded.
+	LicenseTexts LicenseTextEncoding
+	// Filter selects the items that are enriched.
+	//
+	// If nil, all items are enriched.
+	Filter *Filter
+	// Curations set the licenses of packages by hand. They are consulted before the cache and the provider.
+	//
+	// If nil, no curations are used.
+	Curations provider.Curator
+	// Report receives the per-item results of the enrichment.
+	//
+	// If nil, no results are recorded.
+	Report *Report
 }
 
 // Enricher is the interface that each enrichment service must implement.
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
+	"github.com/boringbin/sbomlicense/internal/provider"
 )
 
 // mockProvider implements the provider.Provider interface for testing.
@@ -21,6 +22,17 @@ func (m *mockProvider) Get(ctx context.Context, purl string) (string, error) {
 	return "", nil
 }
 
+// mockBatchProvider implements the provider.BatchProvider interface for testing.
+type mockBatchProvider struct {
+	mockProvider
+
+	getMany func(ctx context.Context, purls []string) (map[string]provider.BatchResult, error)
+}
+
+func (m *mockBatchProvider) GetMany(ctx context.Context, purls []string) (map[string]provider.BatchResult, error) {
+	return m.getMany(ctx, purls)
+}
+
 // mockCache implements the cache.Cache interface for testing.
 type mockCache struct {
 	getFunc        func(key string) (string, error)
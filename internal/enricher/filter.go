@@ -0,0 +1,194 @@
+package enricher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// Filter selects the items of an SBOM that are enriched.
+//
+// Items that are filtered out are left untouched and reported as StatusFiltered.
+type Filter struct {
+	// Include limits enrichment to items matching at least one rule.
+	//
+	// If empty, all items are included.
+	Include []FilterRule `json:"include,omitempty"`
+	// Exclude skips items matching any rule. Exclude takes precedence over Include.
+	Exclude []FilterRule `json:"exclude,omitempty"`
+}
+
+// FilterRule matches items by their attributes.
+//
+// Every non-empty field must match for the rule to match. Within a field, any value may match.
+type FilterRule struct {
+	// PurlTypes are purl types, e.g. "github" or "npm".
+	PurlTypes []string `json:"purlTypes,omitempty"`
+	// Namespaces are globs matched against the decoded purl namespace, e.g. "@acme" or "com.acme*".
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Scopes are CycloneDX component scopes, e.g. "excluded" or "optional".
+	Scopes []string `json:"scopes,omitempty"`
+	// Purposes are SPDX primary package purposes, e.g. "APPLICATION" or "SOURCE".
+	Purposes []string `json:"purposes,omitempty"`
+	// Name is a regular expression matched against the package name.
+	Name string `json:"name,omitempty"`
+}
+
+// filterAttributes are the item attributes filter rules are matched against.
+type filterAttributes struct {
+	name    string
+	scope   string
+	purpose string
+}
+
+// ParseFilterRule parses a filter rule from its command line form: comma-separated key=value pairs,
+// e.g. "type=npm,namespace=@acme". Keys are type, namespace, scope, purpose and name. Repeating a key
+// adds alternatives.
+func ParseFilterRule(s string) (FilterRule, error) {
+	var rule FilterRule
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || value == "" {
+			return FilterRule{}, fmt.Errorf("invalid filter %q: expected key=value", pair)
+		}
+		switch key {
+		case "type":
+			rule.PurlTypes = append(rule.PurlTypes, value)
+		case "namespace":
+			rule.Namespaces = append(rule.Namespaces, value)
+		case "scope":
+			rule.Scopes = append(rule.Scopes, value)
+		case "purpose":
+			rule.Purposes = append(rule.Purposes, value)
+		case "name":
+			rule.Name = value
+		default:
+			return FilterRule{}, fmt.Errorf(
+				"invalid filter key %q: must be type, namespace, scope, purpose or name", key,
+			)
+		}
+	}
+	return rule, nil
+}
+
+// Validate checks that the filter's name patterns are valid regular expressions.
+func (f *Filter) Validate() error {
+	_, err := f.compile()
+	return err
+}
+
+// compiledFilter is a Filter with its name patterns compiled.
+type compiledFilter struct {
+	include []compiledRule
+	exclude []compiledRule
+}
+
+// compiledRule is a FilterRule with its name pattern compiled.
+type compiledRule struct {
+	FilterRule
+
+	name *regexp.Regexp
+}
+
+// compile validates the filter and compiles its regular expressions.
+// A nil filter compiles to a nil filter, which includes everything.
+func (f *Filter) compile() (*compiledFilter, error) {
+	if f == nil {
+		return nil, nil //nolint:nilnil // a nil filter is valid and matches everything
+	}
+
+	compileRules := func(rules []FilterRule) ([]compiledRule, error) {
+		compiled := make([]compiledRule, len(rules))
+		for i, rule := range rules {
+			compiled[i].FilterRule = rule
+			if rule.Name == "" {
+				continue
+			}
+			re, err := regexp.Compile(rule.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid name pattern %q: %w", rule.Name, err)
+			}
+			compiled[i].name = re
+		}
+		return compiled, nil
+	}
+
+	include, err := compileRules(f.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileRules(f.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledFilter{include: include, exclude: exclude}, nil
+}
+
+// allows reports whether an item with the given purl and attributes should be enriched.
+func (f *compiledFilter) allows(rawPurl string, attrs filterAttributes) bool {
+	if f == nil {
+		return true
+	}
+
+	// An unparsable purl simply doesn't match purl-based conditions
+	parsed, _ := purl.Parse(rawPurl)
+
+	for i := range f.exclude {
+		if f.exclude[i].matches(parsed, attrs) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for i := range f.include {
+		if f.include[i].matches(parsed, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether every condition of the rule matches the item.
+func (r *compiledRule) matches(p purl.PURL, attrs filterAttributes) bool {
+	if len(r.PurlTypes) > 0 && !containsFold(r.PurlTypes, p.Type) {
+		return false
+	}
+	if len(r.Namespaces) > 0 {
+		matched := false
+		for _, pattern := range r.Namespaces {
+			if p.Namespace != "" && purl.MatchGlob(pattern, p.Namespace) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Scopes) > 0 && !containsFold(r.Scopes, attrs.scope) {
+		return false
+	}
+	if len(r.Purposes) > 0 && !containsFold(r.Purposes, attrs.purpose) {
+		return false
+	}
+	if r.name != nil && !r.name.MatchString(attrs.name) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether values contains s, ignoring case. An empty s never matches.
+func containsFold(values []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
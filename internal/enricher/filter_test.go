@@ -0,0 +1,232 @@
+package enricher_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestParseFilterRule tests the ParseFilterRule function.
+func TestParseFilterRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    enricher.FilterRule
+		wantErr bool
+	}{
+		{
+			name:  "purl type",
+			input: "type=github",
+			want:  enricher.FilterRule{PurlTypes: []string{"github"}},
+		},
+		{
+			name:  "all keys",
+			input: "type=npm, type=maven,namespace=@acme,scope=excluded,purpose=SOURCE,name=^acme-",
+			want: enricher.FilterRule{
+				PurlTypes:  []string{"npm", "maven"},
+				Namespaces: []string{"@acme"},
+				Scopes:     []string{"excluded"},
+				Purposes:   []string{"SOURCE"},
+				Name:       "^acme-",
+			},
+		},
+		{name: "missing value", input: "type=", wantErr: true},
+		{name: "missing equals", input: "npm", wantErr: true},
+		{name: "unknown key", input: "version=1.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := enricher.ParseFilterRule(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilterRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilterRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCycloneDXEnricher_Enrich_Filter tests that filtered components are left untouched and reported.
+func TestCycloneDXEnricher_Enrich_Filter(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+		{"bom-ref": "checkout", "name": "checkout", "purl": "pkg:github/actions/checkout@v4"},
+		{"bom-ref": "excluded", "name": "jest", "scope": "excluded", "purl": "pkg:npm/jest@29.0.0"},
+		{"bom-ref": "internal", "name": "acme-ui", "purl": "pkg:npm/%40acme/ui@1.0.0"},
+		{"bom-ref": "first-party", "name": "acme-core", "purl": "pkg:npm/acme-core@1.0.0"},
+		{"bom-ref": "lodash", "name": "lodash", "purl": "pkg:npm/lodash@4.17.21"},
+		{"bom-ref": "requests", "name": "requests", "purl": "pkg:pypi/requests@2.31.0"}
+	]}`)
+
+	var mu sync.Mutex
+	var looked []string
+	prov := &mockProvider{
+		getLicense: func(_ context.Context, purl string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			looked = append(looked, purl)
+			return "MIT", nil
+		},
+	}
+
+	report := &enricher.Report{}
+	e := enricher.NewCycloneDXEnricher(prov, &mockCache{}, time.Hour)
+	result, err := e.Enrich(context.Background(), enricher.Options{
+		SBOM:   input,
+		Logger: noopLogger(),
+		Report: report,
+		Filter: &enricher.Filter{
+			Include: []enricher.FilterRule{{PurlTypes: []string{"npm", "github"}}},
+			Exclude: []enricher.FilterRule{
+				{PurlTypes: []string{"github"}},
+				{Scopes: []string{"excluded"}},
+				{Namespaces: []string{"@acme*"}},
+				{Name: "^acme-"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	if len(looked) != 1 || looked[0] != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("provider looked up %v, want only lodash", looked)
+	}
+
+	bom, err := enricher.ParseCycloneDXFile(result)
+	if err != nil {
+		t.Fatalf("ParseCycloneDXFile() error = %v", err)
+	}
+	for _, c := range bom.Components {
+		if got := enricher.HasComponentLicense(&c); got != (c.Name == "lodash") {
+			t.Errorf("component %s HasComponentLicense() = %v", c.Name, got)
+		}
+	}
+
+	wantStatuses := []enricher.ItemStatus{
+		enricher.StatusFiltered,
+		enricher.StatusFiltered,
+		enricher.StatusFiltered,
+		enricher.StatusFiltered,
+		enricher.StatusEnriched,
+		enricher.StatusFiltered,
+	}
+	if len(report.Items) != len(wantStatuses) {
+		t.Fatalf("len(report.Items) = %d, want %d", len(report.Items), len(wantStatuses))
+	}
+	for i, want := range wantStatuses {
+		if report.Items[i].Status != want {
+			t.Errorf("report.Items[%d] = %+v, want status %q", i, report.Items[i], want)
+		}
+	}
+	if got := report.Count(enricher.StatusFiltered); got != 5 {
+		t.Errorf("Count(StatusFiltered) = %d, want 5", got)
+	}
+}
+
+// TestSPDXEnricher_Enrich_FilterByPurpose tests filtering SPDX packages by primary package purpose.
+func TestSPDXEnricher_Enrich_FilterByPurpose(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "packages": [
+		{"SPDXID": "SPDXRef-app", "name": "app", "primaryPackagePurpose": "APPLICATION", "externalRefs": [
+			{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/app@1.0.0"}
+		]},
+		{"SPDXID": "SPDXRef-lib", "name": "lib", "primaryPackagePurpose": "LIBRARY", "externalRefs": [
+			{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lib@1.0.0"}
+		]},
+		{"SPDXID": "SPDXRef-nopurl", "name": "nopurl"}
+	]}`)
+
+	prov := &mockProvider{
+		getLicense: func(_ context.Context, _ string) (string, error) {
+			return "", provider.ErrLicenseNotFound
+		},
+	}
+
+	report := &enricher.Report{}
+	e := enricher.NewSPDXEnricher(prov, &mockCache{}, time.Hour)
+	_, err := e.Enrich(context.Background(), enricher.Options{
+		SBOM:   input,
+		Logger: noopLogger(),
+		Report: report,
+		Filter: &enricher.Filter{
+			Exclude: []enricher.FilterRule{{Purposes: []string{"application"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	want := []enricher.ItemStatus{enricher.StatusFiltered, enricher.StatusNotFound, enricher.StatusNoPurl}
+	if len(report.Items) != len(want) {
+		t.Fatalf("report.Items = %+v, want %d items", report.Items, len(want))
+	}
+	for i := range want {
+		if report.Items[i].Status != want[i] {
+			t.Errorf("report.Items[%d].Status = %q, want %q", i, report.Items[i].Status, want[i])
+		}
+	}
+}
+
+// TestEnrich_InvalidFilter tests that an invalid name pattern is rejected.
+func TestEnrich_InvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	e := enricher.NewCycloneDXEnricher(&mockProvider{}, &mockCache{}, time.Hour)
+	_, err := e.Enrich(context.Background(), enricher.Options{
+		SBOM: []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+			{"bom-ref": "a", "name": "a", "purl": "pkg:npm/a@1.0.0"}
+		]}`),
+		Filter: &enricher.Filter{Exclude: []enricher.FilterRule{{Name: "("}}},
+	})
+	if err == nil {
+		t.Error("Enrich() error = nil, want invalid filter error")
+	}
+}
+
+// TestEnrich_BlockedLookup tests that lookups refused by the privacy guard are reported as blocked.
+func TestEnrich_BlockedLookup(t *testing.T) {
+	t.Parallel()
+
+	guard, err := provider.NewGuard(&mockProvider{
+		getLicense: func(_ context.Context, _ string) (string, error) {
+			return "MIT", nil
+		},
+	}, []string{"pkg:npm/@acme/*"})
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+
+	report := &enricher.Report{}
+	e := enricher.NewCycloneDXEnricher(guard, &mockCache{}, time.Hour)
+	_, err = e.Enrich(context.Background(), enricher.Options{
+		SBOM: []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+			{"bom-ref": "private", "name": "ui", "purl": "pkg:npm/%40acme/ui@1.0.0"},
+			{"bom-ref": "public", "name": "lodash", "purl": "pkg:npm/lodash@4.17.21"}
+		]}`),
+		Logger: noopLogger(),
+		Report: report,
+	})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	if len(report.Items) != 2 ||
+		report.Items[0].Status != enricher.StatusBlocked ||
+		report.Items[1].Status != enricher.StatusEnriched {
+		t.Errorf("report.Items = %+v, want blocked then enriched", report.Items)
+	}
+}
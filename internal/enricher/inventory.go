@@ -0,0 +1,164 @@
+package enricher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/sbom"
+)
+
+// Inventory is a format-independent view of the packages of an SBOM.
+type Inventory struct {
+	// Root is the package the SBOM describes, if known.
+	Root *InventoryItem
+	// Items are the other packages of the SBOM in document order.
+	Items []InventoryItem
+}
+
+// InventoryItem is a package of an SBOM.
+type InventoryItem struct {
+	// ID is the SPDX ID or BOM reference of the package.
+	ID string `json:"id"`
+	// Name is the package name.
+	Name string `json:"name"`
+	// Version is the package version.
+	Version string `json:"version,omitempty"`
+	// Purl is the package URL, if any.
+	Purl string `json:"purl,omitempty"`
+	// License is the license expression of the package, or empty if unknown.
+	//
+	// For SPDX, the concluded license is preferred over the declared license. For CycloneDX, concluded
+	// licenses are preferred over declared ones, and several licenses are combined with AND.
+	License string `json:"license,omitempty"`
+	// Homepage is the package homepage, if any.
+	Homepage string `json:"homepage,omitempty"`
+	// Copyright is the copyright text of the package, if any.
+	Copyright string `json:"copyright,omitempty"`
+}
+
+// ReadInventory reads the packages of an SPDX or CycloneDX SBOM.
+func ReadInventory(data []byte) (*Inventory, error) {
+	format, err := sbom.DetectFormat(data)
+	if err != nil {
+		return nil, fmt.Errorf("detect format: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(format, "SPDX"):
+		doc, parseErr := ParseSBOMFile(data)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		return spdxInventory(doc), nil
+	case strings.HasPrefix(format, "CycloneDX"):
+		bom, parseErr := ParseCycloneDXFile(data)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		return cycloneDXInventory(bom), nil
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format: %s", format)
+	}
+}
+
+// spdxInventory builds the inventory of an SPDX document.
+func spdxInventory(doc *Document) *Inventory {
+	described := ""
+	for _, rel := range doc.Relationships {
+		if rel.SPDXElementID == spdxDocumentID && rel.RelationshipType == "DESCRIBES" {
+			described = rel.RelatedSPDXElement
+			break
+		}
+	}
+
+	inventory := &Inventory{}
+	for i := range doc.Packages {
+		pkg := &doc.Packages[i]
+		item := InventoryItem{
+			ID:      pkg.SPDXID,
+			Name:    pkg.Name,
+			Version: pkg.VersionInfo,
+		}
+		item.Purl, _ = pkg.GetPurl()
+		switch {
+		case isSPDXValue(pkg.LicenseConcluded):
+			item.License = pkg.LicenseConcluded
+		case isSPDXValue(pkg.LicenseDeclared):
+			item.License = pkg.LicenseDeclared
+		}
+		if isSPDXValue(pkg.Homepage) {
+			item.Homepage = pkg.Homepage
+		}
+		if isSPDXValue(pkg.CopyrightText) {
+			item.Copyright = pkg.CopyrightText
+		}
+
+		if pkg.SPDXID == described && inventory.Root == nil {
+			inventory.Root = &item
+			continue
+		}
+		inventory.Items = append(inventory.Items, item)
+	}
+	return inventory
+}
+
+// cycloneDXInventory builds the inventory of a CycloneDX BOM.
+func cycloneDXInventory(bom *BOM) *Inventory {
+	inventory := &Inventory{}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		root := cycloneDXInventoryItem(bom.Metadata.Component)
+		inventory.Root = &root
+	}
+	for i := range bom.Components {
+		inventory.Items = append(inventory.Items, cycloneDXInventoryItem(&bom.Components[i]))
+	}
+	return inventory
+}
+
+// cycloneDXInventoryItem converts a CycloneDX component into an inventory item.
+func cycloneDXInventoryItem(component *Component) InventoryItem {
+	item := InventoryItem{
+		ID:        component.BOMRef,
+		Name:      component.Name,
+		Version:   component.Version,
+		Purl:      component.Purl,
+		Copyright: component.Copyright,
+	}
+	for _, ref := range component.ExternalReferences {
+		if ref.Type == cycloneDXRefWebsite {
+			item.Homepage = ref.URL
+			break
+		}
+	}
+
+	var declared, concluded []string
+	for _, choice := range component.Licenses {
+		value := choice.Expression
+		if choice.License != nil {
+			switch {
+			case choice.License.ID != "":
+				value = choice.License.ID
+			case choice.License.Expression != "":
+				value = choice.License.Expression
+			default:
+				value = choice.License.Name
+			}
+		}
+		if value == "" {
+			continue
+		}
+		if choice.Acknowledgement == cycloneDXAcknowledgementDeclared {
+			declared = append(declared, value)
+		} else {
+			concluded = append(concluded, value)
+		}
+	}
+	switch {
+	case len(concluded) > 0:
+		item.License = license.Join(license.OperatorAnd, concluded)
+	case len(declared) > 0:
+		item.License = license.Join(license.OperatorAnd, declared)
+	}
+	return item
+}
@@ -0,0 +1,100 @@
+package enricher_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+)
+
+// TestReadInventory_SPDX tests reading the packages of an SPDX document.
+func TestReadInventory_SPDX(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"sbom": {"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "packages": [
+		{"SPDXID": "SPDXRef-app", "name": "app", "versionInfo": "1.0.0", "licenseConcluded": "Apache-2.0"},
+		{"SPDXID": "SPDXRef-lodash", "name": "lodash", "versionInfo": "4.17.21", "licenseConcluded": "NOASSERTION",
+			"licenseDeclared": "MIT", "homepage": "https://lodash.com", "copyrightText": "Copyright OpenJS Foundation",
+			"externalRefs": [
+				{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash@4.17.21"}
+			]},
+		{"SPDXID": "SPDXRef-unknown", "name": "unknown", "licenseConcluded": "NOASSERTION", "homepage": "NONE"}
+	], "relationships": [
+		{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-app"}
+	]}}`)
+
+	inventory, err := enricher.ReadInventory(input)
+	if err != nil {
+		t.Fatalf("ReadInventory() error = %v", err)
+	}
+
+	wantRoot := &enricher.InventoryItem{ID: "SPDXRef-app", Name: "app", Version: "1.0.0", License: "Apache-2.0"}
+	if !reflect.DeepEqual(inventory.Root, wantRoot) {
+		t.Errorf("Root = %+v, want %+v", inventory.Root, wantRoot)
+	}
+	wantItems := []enricher.InventoryItem{
+		{
+			ID:        "SPDXRef-lodash",
+			Name:      "lodash",
+			Version:   "4.17.21",
+			Purl:      "pkg:npm/lodash@4.17.21",
+			License:   "MIT",
+			Homepage:  "https://lodash.com",
+			Copyright: "Copyright OpenJS Foundation",
+		},
+		{ID: "SPDXRef-unknown", Name: "unknown"},
+	}
+	if !reflect.DeepEqual(inventory.Items, wantItems) {
+		t.Errorf("Items = %+v, want %+v", inventory.Items, wantItems)
+	}
+}
+
+// TestReadInventory_CycloneDX tests reading the components of a CycloneDX BOM.
+func TestReadInventory_CycloneDX(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.6",
+		"metadata": {"component": {"bom-ref": "app", "name": "app", "licenses": [{"license": {"id": "Apache-2.0"}}]}},
+		"components": [
+			{"bom-ref": "a", "name": "a", "purl": "pkg:npm/a@1.0.0", "licenses": [
+				{"license": {"id": "MIT"}, "acknowledgement": "declared"},
+				{"expression": "MIT OR Apache-2.0", "acknowledgement": "concluded"}
+			]},
+			{"bom-ref": "b", "name": "b", "copyright": "Copyright B", "licenses": [
+				{"license": {"name": "Custom License"}}
+			], "externalReferences": [{"type": "website", "url": "https://b.example"}]},
+			{"bom-ref": "c", "name": "c"}
+		]}`)
+
+	inventory, err := enricher.ReadInventory(input)
+	if err != nil {
+		t.Fatalf("ReadInventory() error = %v", err)
+	}
+
+	if inventory.Root == nil || inventory.Root.Name != "app" || inventory.Root.License != "Apache-2.0" {
+		t.Errorf("Root = %+v, want app under Apache-2.0", inventory.Root)
+	}
+	wantItems := []enricher.InventoryItem{
+		{ID: "a", Name: "a", Purl: "pkg:npm/a@1.0.0", License: "MIT OR Apache-2.0"},
+		{
+			ID:        "b",
+			Name:      "b",
+			License:   "Custom License",
+			Homepage:  "https://b.example",
+			Copyright: "Copyright B",
+		},
+		{ID: "c", Name: "c"},
+	}
+	if !reflect.DeepEqual(inventory.Items, wantItems) {
+		t.Errorf("Items = %+v, want %+v", inventory.Items, wantItems)
+	}
+}
+
+// TestReadInventory_UnknownFormat tests that non-SBOM JSON is rejected.
+func TestReadInventory_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	if _, err := enricher.ReadInventory([]byte(`{"foo": "bar"}`)); err == nil {
+		t.Error("ReadInventory() error = nil, want error")
+	}
+}
@@ -0,0 +1,180 @@
+package enricher
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// LicenseTextEncoding is the encoding of license texts embedded into CycloneDX SBOMs.
+type LicenseTextEncoding string
+
+const (
+	// LicenseTextNone does not embed license texts.
+	LicenseTextNone LicenseTextEncoding = ""
+	// LicenseTextPlain embeds license texts as plain text.
+	LicenseTextPlain LicenseTextEncoding = "plain"
+	// LicenseTextBase64 embeds license texts encoded as base64.
+	LicenseTextBase64 LicenseTextEncoding = "base64"
+)
+
+const (
+	// spdxLicenseRefPrefix is the prefix of license identifiers that are not on the SPDX License List.
+	spdxLicenseRefPrefix = "LicenseRef-"
+	// spdxLicenseURL is the URL of a license on the SPDX License List, without the identifier.
+	spdxLicenseURL = "https://spdx.org/licenses/"
+)
+
+// ParseLicenseTextEncoding parses a license text encoding name.
+// An empty string returns LicenseTextNone.
+func ParseLicenseTextEncoding(s string) (LicenseTextEncoding, error) {
+	switch e := LicenseTextEncoding(strings.ToLower(s)); e {
+	case LicenseTextNone, LicenseTextPlain, LicenseTextBase64:
+		return e, nil
+	default:
+		return "", fmt.Errorf(
+			"unsupported license text encoding %q: must be %q or %q",
+			s, LicenseTextPlain, LicenseTextBase64,
+		)
+	}
+}
+
+// embedSPDXLicenseTexts declares the LicenseRefs used by the packages of an SPDX document in
+// hasExtractedLicensingInfos, with their full text.
+//
+// SPDX documents only carry the texts of licenses that are not on the SPDX License List. The text of a
+// LicenseRef is known if it names a listed license, e.g. "LicenseRef-MIT". LicenseRefs that are already
+// declared, or whose text is unknown, are left alone.
+func embedSPDXLicenseTexts(doc *Document, logger *slog.Logger) {
+	declared := map[string]bool{}
+	for _, info := range doc.HasExtractedLicensingInfos {
+		declared[info.LicenseID] = true
+	}
+
+	embedded := 0
+	for _, pkg := range doc.Packages {
+		for _, expression := range []string{pkg.LicenseConcluded, pkg.LicenseDeclared} {
+			for _, id := range licenseIDs(expression) {
+				if !strings.HasPrefix(id, spdxLicenseRefPrefix) || declared[id] {
+					continue
+				}
+				declared[id] = true
+
+				listed := strings.TrimPrefix(id, spdxLicenseRefPrefix)
+				text, ok := license.Text(listed)
+				if !ok {
+					logger.Debug("license text not available", "license", id)
+					continue
+				}
+				listed = license.CanonicalID(listed)
+				doc.HasExtractedLicensingInfos = append(doc.HasExtractedLicensingInfos, ExtractedLicensingInfo{
+					LicenseID:     id,
+					ExtractedText: text,
+					Name:          listed,
+					SeeAlsos:      []string{spdxLicenseURL + listed + ".html"},
+				})
+				embedded++
+			}
+		}
+	}
+	logger.Info("embedded license texts", "count", embedded)
+}
+
+// embedCycloneDXLicenseTexts attaches the full license texts to the licenses of the components of a
+// CycloneDX BOM.
+//
+// CycloneDX has no way to share a text between components, so each distinct text is embedded only once: in
+// the first license that uses it, in document order. Expressions of a single license are turned into license
+// objects to carry the text. Compound expressions and licenses whose text is unknown are left alone.
+func embedCycloneDXLicenseTexts(bom *BOM, encoding LicenseTextEncoding, logger *slog.Logger) {
+	components := make([]*Component, 0, len(bom.Components)+1)
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		components = append(components, bom.Metadata.Component)
+	}
+	for i := range bom.Components {
+		components = append(components, &bom.Components[i])
+	}
+
+	// Texts that are already in the BOM are not embedded again
+	embedded := map[string]bool{}
+	for _, component := range components {
+		for _, choice := range component.Licenses {
+			if choice.License != nil && choice.License.Text != nil && choice.License.ID != "" {
+				if text, ok := license.Text(choice.License.ID); ok {
+					embedded[text] = true
+				}
+			}
+		}
+	}
+
+	count := 0
+	for _, component := range components {
+		for i := range component.Licenses {
+			choice := &component.Licenses[i]
+			id := singleLicenseID(choice)
+			if id == "" {
+				continue
+			}
+			text, ok := license.Text(id)
+			if !ok || embedded[text] {
+				continue
+			}
+			embedded[text] = true
+
+			if choice.License == nil {
+				choice.License = &License{ID: id}
+				choice.Expression = ""
+			}
+			choice.License.Text = newLicenseText(text, encoding)
+			count++
+		}
+	}
+	logger.Info("embedded license texts", "count", count)
+}
+
+// singleLicenseID returns the SPDX identifier of a license choice that names a single license without text, or
+// an empty string otherwise.
+func singleLicenseID(choice *LicenseChoice) string {
+	if choice.License != nil {
+		if choice.License.Text != nil {
+			return ""
+		}
+		return choice.License.ID
+	}
+	expr, err := license.Parse(choice.Expression)
+	if err != nil || !expr.IsLeaf() || expr.Exception != "" {
+		return ""
+	}
+	return expr.License
+}
+
+// newLicenseText returns the CycloneDX attachment of a license text.
+func newLicenseText(text string, encoding LicenseTextEncoding) *LicenseText {
+	if encoding == LicenseTextBase64 {
+		return &LicenseText{
+			ContentType: "text/plain",
+			Encoding:    "base64",
+			Content:     base64.StdEncoding.EncodeToString([]byte(text)),
+		}
+	}
+	return &LicenseText{ContentType: "text/plain", Content: text}
+}
+
+// licenseIDs returns the license identifiers of an SPDX license expression, or nil if it is not valid.
+func licenseIDs(expression string) []string {
+	if !isSPDXValue(expression) {
+		return nil
+	}
+	expr, err := license.Parse(expression)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, leaf := range expr.Leaves() {
+		ids = append(ids, leaf.License)
+	}
+	return ids
+}
@@ -0,0 +1,159 @@
+package enricher_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+)
+
+// TestParseLicenseTextEncoding tests parsing license text encoding names.
+func TestParseLicenseTextEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    enricher.LicenseTextEncoding
+		wantErr bool
+	}{
+		{input: "", want: enricher.LicenseTextNone},
+		{input: "plain", want: enricher.LicenseTextPlain},
+		{input: "BASE64", want: enricher.LicenseTextBase64},
+		{input: "gzip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := enricher.ParseLicenseTextEncoding(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLicenseTextEncoding(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLicenseTextEncoding(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestCycloneDXEnricher_Enrich_LicenseTexts tests that each distinct license text is embedded once.
+func TestCycloneDXEnricher_Enrich_LicenseTexts(t *testing.T) {
+	t.Parallel()
+
+	prov := &mockProvider{
+		getLicense: func(_ context.Context, _ string) (string, error) {
+			return "GPL-2.0-or-later", nil
+		},
+	}
+	input := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.6", "components": [
+		{"bom-ref": "a", "name": "a", "licenses": [{"expression": "MIT", "acknowledgement": "declared"}]},
+		{"bom-ref": "b", "name": "b", "licenses": [{"license": {"id": "MIT"}}]},
+		{"bom-ref": "c", "name": "c", "licenses": [{"expression": "MIT OR Apache-2.0"}]},
+		{"bom-ref": "d", "name": "d", "licenses": [{"license": {"name": "Acme License"}}]},
+		{"bom-ref": "e", "name": "e", "purl": "pkg:npm/e@1.0.0"},
+		{"bom-ref": "f", "name": "f", "licenses": [{"expression": "GPL-2.0-only"}]}
+	]}`)
+
+	tests := []struct {
+		name         string
+		encoding     enricher.LicenseTextEncoding
+		wantEncoding string
+		decode       func(string) string
+	}{
+		{name: "plain", encoding: enricher.LicenseTextPlain, decode: func(s string) string { return s }},
+		{
+			name:         "base64",
+			encoding:     enricher.LicenseTextBase64,
+			wantEncoding: "base64",
+			decode: func(s string) string {
+				decoded, _ := base64.StdEncoding.DecodeString(s)
+				return string(decoded)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := enricher.NewCycloneDXEnricher(prov, &mockCache{}, time.Hour)
+			result, err := e.Enrich(context.Background(), enricher.Options{SBOM: input, LicenseTexts: tt.encoding})
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+			bom, err := enricher.ParseCycloneDXFile(result)
+			if err != nil {
+				t.Fatalf("failed to parse result: %v", err)
+			}
+
+			// The first MIT license carries the text, turned from an expression into a license object
+			a := bom.Components[0].Licenses[0]
+			if a.License == nil || a.License.ID != "MIT" || a.License.Text == nil ||
+				a.Acknowledgement != "declared" {
+				t.Fatalf("a licenses = %+v, want MIT license with text", bom.Components[0].Licenses)
+			}
+			if a.License.Text.ContentType != "text/plain" || a.License.Text.Encoding != tt.wantEncoding {
+				t.Errorf("a text = %+v", a.License.Text)
+			}
+			if !strings.Contains(tt.decode(a.License.Text.Content), "Permission is hereby granted") {
+				t.Errorf("a text content = %q, want MIT text", a.License.Text.Content)
+			}
+
+			// Later MIT licenses, compound expressions and names are left alone
+			if b := bom.Components[1].Licenses[0]; b.License == nil || b.License.Text != nil {
+				t.Errorf("b licenses = %+v, want MIT without text", bom.Components[1].Licenses)
+			}
+			if c := bom.Components[2].Licenses[0]; c.Expression != "MIT OR Apache-2.0" || c.License != nil {
+				t.Errorf("c licenses = %+v, want expression", bom.Components[2].Licenses)
+			}
+			if d := bom.Components[3].Licenses[0]; d.License == nil || d.License.Text != nil {
+				t.Errorf("d licenses = %+v, want name without text", bom.Components[3].Licenses)
+			}
+
+			// GPL-2.0-or-later and GPL-2.0-only share a text
+			if e := bom.Components[4].Licenses[0]; e.License == nil || e.License.ID != "GPL-2.0-or-later" ||
+				e.License.Text == nil {
+				t.Errorf("e licenses = %+v, want enriched GPL-2.0-or-later with text", bom.Components[4].Licenses)
+			}
+			if f := bom.Components[5].Licenses[0]; f.Expression != "GPL-2.0-only" {
+				t.Errorf("f licenses = %+v, want expression without text", bom.Components[5].Licenses)
+			}
+		})
+	}
+}
+
+// TestSPDXEnricher_Enrich_LicenseTexts tests that LicenseRefs of listed licenses are declared with their text.
+func TestSPDXEnricher_Enrich_LicenseTexts(t *testing.T) {
+	t.Parallel()
+
+	input := []byte(`{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "packages": [
+		{"SPDXID": "SPDXRef-a", "name": "a", "licenseConcluded": "LicenseRef-MIT AND LicenseRef-acme",
+			"licenseDeclared": "LicenseRef-MIT"},
+		{"SPDXID": "SPDXRef-b", "name": "b", "licenseConcluded": "LicenseRef-custom", "licenseDeclared": "NOASSERTION"},
+		{"SPDXID": "SPDXRef-c", "name": "c", "licenseConcluded": "Apache-2.0", "licenseDeclared": "Apache-2.0"}
+	], "hasExtractedLicensingInfos": [{"licenseId": "LicenseRef-custom", "extractedText": "Custom terms"}]}`)
+
+	e := enricher.NewSPDXEnricher(&mockProvider{}, &mockCache{}, time.Hour)
+	result, err := e.Enrich(context.Background(), enricher.Options{SBOM: input, LicenseTexts: enricher.LicenseTextPlain})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	doc, err := enricher.ParseSBOMFile(result)
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+
+	infos := doc.HasExtractedLicensingInfos
+	if len(infos) != 2 {
+		t.Fatalf("HasExtractedLicensingInfos = %+v, want custom and MIT", infos)
+	}
+	if infos[0].LicenseID != "LicenseRef-custom" || infos[0].ExtractedText != "Custom terms" {
+		t.Errorf("existing info = %+v, want it preserved", infos[0])
+	}
+	if infos[1].LicenseID != "LicenseRef-MIT" || infos[1].Name != "MIT" ||
+		!strings.Contains(infos[1].ExtractedText, "Permission is hereby granted") ||
+		len(infos[1].SeeAlsos) != 1 || infos[1].SeeAlsos[0] != "https://spdx.org/licenses/MIT.html" {
+		t.Errorf("MIT info = %+v", infos[1])
+	}
+}
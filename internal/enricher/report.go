@@ -0,0 +1,103 @@
+package enricher
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// ItemStatus is the outcome of enriching a single item.
+type ItemStatus string
+
+const (
+	// StatusEnriched means a license was found and added to the item.
+	StatusEnriched ItemStatus = "enriched"
+	// StatusExistingLicense means the item already had a license and was left untouched.
+	StatusExistingLicense ItemStatus = "skipped (existing license)"
+	// StatusFiltered means the item was excluded by the filter and left untouched.
+	StatusFiltered ItemStatus = "skipped (filtered)"
+	// StatusNoPurl means the item has no purl to look up.
+	StatusNoPurl ItemStatus = "skipped (no purl)"
+	// StatusBlocked means the purl is private and was not sent to external providers.
+	StatusBlocked ItemStatus = "blocked (private)"
+	// StatusNotFound means no license was found for the item.
+	StatusNotFound ItemStatus = "not found"
+	// StatusError means the license lookup failed.
+	StatusError ItemStatus = "error"
+)
+
+// ItemResult is the enrichment result of a single item.
+type ItemResult struct {
+	// ID is the SPDX ID or BOM reference of the item.
+	ID string `json:"id"`
+	// Purl is the package URL of the item, if any.
+	Purl string `json:"purl,omitempty"`
+	// Status is the outcome of the enrichment.
+	Status ItemStatus `json:"status"`
+	// License is the license that was added to the item.
+	License string `json:"license,omitempty"`
+	// Source is where the license was found: a curation, the cache or the provider.
+	Source provider.Source `json:"source,omitempty"`
+	// Curation is the manual curation that set the license, if any.
+	Curation *curation.Curation `json:"curation,omitempty"`
+	// Provider is the name of the provider of a chain that found the license, if any.
+	Provider string `json:"provider,omitempty"`
+	// Disagreement are the licenses found by each provider, if they disagree and the license needs review.
+	Disagreement []provider.Answer `json:"disagreement,omitempty"`
+	// Error is the error message if the lookup failed.
+	Error string `json:"error,omitempty"`
+
+	// index is the position of the item in the SBOM, used to keep results in document order.
+	index int
+}
+
+// Report collects the per-item results of an enrichment.
+//
+// It is safe for concurrent use.
+type Report struct {
+	mu sync.Mutex
+	// Items are the results in document order.
+	Items []ItemResult `json:"items"`
+}
+
+// Count returns the number of items with the given status.
+func (r *Report) Count(status ItemStatus) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// add records the result of an item. It is a no-op on a nil report.
+func (r *Report) add(result ItemResult) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Items = append(r.Items, result)
+}
+
+// sort orders the results by their position in the SBOM. It is a no-op on a nil report.
+func (r *Report) sort() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.SliceStable(r.Items, func(i, j int) bool {
+		return r.Items[i].index < r.Items[j].index
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
@@ -21,20 +22,54 @@ const (
 
 // Document represents a minimal SPDX document with only the fields we need.
 type Document struct {
-	SPDXVersion string    `json:"spdxVersion"`
-	SPDXID      string    `json:"SPDXID"`
-	Packages    []Package `json:"packages"`
+	SPDXVersion       string         `json:"spdxVersion"`
+	DataLicense       string         `json:"dataLicense,omitempty"`
+	SPDXID            string         `json:"SPDXID"`
+	Name              string         `json:"name,omitempty"`
+	DocumentNamespace string         `json:"documentNamespace,omitempty"`
+	CreationInfo      *CreationInfo  `json:"creationInfo,omitempty"`
+	Packages          []Package      `json:"packages"`
+	Relationships     []Relationship `json:"relationships,omitempty"`
+	// HasExtractedLicensingInfos are the licenses referenced as LicenseRefs, with their text.
+	HasExtractedLicensingInfos []ExtractedLicensingInfo `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+// ExtractedLicensingInfo represents a license that is not on the SPDX License List.
+type ExtractedLicensingInfo struct {
+	LicenseID     string   `json:"licenseId"`
+	ExtractedText string   `json:"extractedText"`
+	Name          string   `json:"name,omitempty"`
+	SeeAlsos      []string `json:"seeAlsos,omitempty"`
+	Comment       string   `json:"comment,omitempty"`
+}
+
+// CreationInfo represents the SPDX document creation information.
+type CreationInfo struct {
+	Created  string   `json:"created,omitempty"`
+	Creators []string `json:"creators,omitempty"`
+}
+
+// Relationship represents a relationship between two SPDX elements.
+type Relationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
 }
 
 // Package represents a minimal SPDX package with only the fields we need.
 type Package struct {
-	SPDXID           string        `json:"SPDXID"`
-	Name             string        `json:"name"`
-	VersionInfo      string        `json:"versionInfo"`
-	Homepage         string        `json:"homepage"`
-	LicenseConcluded string        `json:"licenseConcluded"`
-	LicenseDeclared  string        `json:"licenseDeclared"`
-	ExternalRefs     []ExternalRef `json:"externalRefs"`
+	SPDXID                string        `json:"SPDXID"`
+	Name                  string        `json:"name"`
+	VersionInfo           string        `json:"versionInfo"`
+	Supplier              string        `json:"supplier,omitempty"`
+	DownloadLocation      string        `json:"downloadLocation,omitempty"`
+	Homepage              string        `json:"homepage"`
+	LicenseConcluded      string        `json:"licenseConcluded"`
+	LicenseDeclared       string        `json:"licenseDeclared"`
+	CopyrightText         string        `json:"copyrightText,omitempty"`
+	Description           string        `json:"description,omitempty"`
+	PrimaryPackagePurpose string        `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []ExternalRef `json:"externalRefs"`
 }
 
 // ExternalRef represents an external reference (like purl).
@@ -78,6 +113,11 @@ func (p *Package) GetLogID() string {
 	return p.SPDXID
 }
 
+// filterAttributes returns the attributes filter rules are matched against.
+func (p *Package) filterAttributes() filterAttributes {
+	return filterAttributes{name: p.Name, purpose: p.PrimaryPackagePurpose}
+}
+
 // UnwrapGitHubSBOM checks if the data is wrapped in GitHub's {"sbom": {...}} format and returns the unwrapped SPDX
 // data if so, or the original data otherwise.
 func UnwrapGitHubSBOM(data []byte) ([]byte, error) {
@@ -166,7 +206,25 @@ func (s *SPDXEnricher) Enrich(ctx context.Context, opts Options) ([]byte, error)
 		s.provider,
 		s.cache,
 		s.cacheTTL,
-		func(d *Document) ([]byte, error) {
+		func(d *Document, logger *slog.Logger) ([]byte, error) {
+			switch opts.OutputFormat {
+			case OutputFormatCycloneDX16:
+				raw, unwrapErr := UnwrapGitHubSBOM(opts.SBOM)
+				if unwrapErr != nil {
+					return nil, fmt.Errorf("failed to unwrap GitHub SBOM: %w", unwrapErr)
+				}
+				bom := ConvertSPDXToCycloneDX(d, raw, logger)
+				if opts.LicenseTexts != LicenseTextNone {
+					embedCycloneDXLicenseTexts(bom, opts.LicenseTexts, logger)
+				}
+				return json.Marshal(bom)
+			case OutputFormatSPDX23:
+				d.SPDXVersion = "SPDX-2.3"
+			case OutputFormatNative:
+			}
+			if opts.LicenseTexts != LicenseTextNone {
+				embedSPDXLicenseTexts(d, logger)
+			}
 			return json.Marshal(d)
 		},
 	)
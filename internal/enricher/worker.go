@@ -2,8 +2,11 @@ package enricher
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,6 +14,9 @@ import (
 	"github.com/boringbin/sbomlicense/internal/provider"
 )
 
+// batchSize is the number of items looked up in one batch lookup.
+const batchSize = 100
+
 // enrichableItem represents an item that can be enriched with license information.
 type enrichableItem interface {
 	// GetPurl returns the package URL for license lookup.
@@ -25,11 +31,14 @@ type enrichableItem interface {
 
 	// GetLogID returns a unique identifier for logging purposes.
 	GetLogID() string
+
+	// filterAttributes returns the attributes filter rules are matched against.
+	filterAttributes() filterAttributes
 }
 
 // enrichDocument handles the common enrichment flow for any document type.
 // It sets up parallelism, creates a logger if needed, enriches items in parallel,
-// and marshals the result.
+// and marshals the result (converting it if another output format was requested).
 func enrichDocument[T enrichableItem, D any](
 	ctx context.Context,
 	opts Options,
@@ -38,10 +47,10 @@ func enrichDocument[T enrichableItem, D any](
 	prov provider.Provider,
 	cacheInstance cache.Cache,
 	cacheTTL time.Duration,
-	marshalFn func(*D) ([]byte, error),
+	marshalFn func(*D, *slog.Logger) ([]byte, error),
 ) ([]byte, error) {
-	// No items to enrich, return original SBOM
-	if len(items) == 0 {
+	// No items to enrich and nothing to convert or embed, return original SBOM
+	if len(items) == 0 && opts.OutputFormat == OutputFormatNative && opts.LicenseTexts == LicenseTextNone {
 		return opts.SBOM, nil
 	}
 
@@ -57,31 +66,45 @@ func enrichDocument[T enrichableItem, D any](
 		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
 
+	// Validate the filter before doing any work
+	filter, err := opts.Filter.compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
 	// Process items in parallel using generic worker function
-	if err := processItemsParallel(
+	if processErr := processItemsParallel(
 		ctx,
 		items,
 		parallelism,
 		prov,
 		cacheInstance,
 		cacheTTL,
+		opts.Curations,
+		filter,
+		opts.Report,
 		logger,
-	); err != nil {
-		return nil, err
+	); processErr != nil {
+		return nil, processErr
 	}
+	opts.Report.sort()
 
-	return marshalFn(doc)
+	return marshalFn(doc, logger)
 }
 
 // job represents a single enrichment task.
 type job[T enrichableItem] struct {
-	item T
-	purl string
+	item  T
+	purl  string
+	index int
+	// prefetched is the result of a batch lookup of the item, if any.
+	prefetched *provider.BatchLookup
 }
 
 // processItemsParallel enriches items in parallel using a worker pool pattern.
-// It distributes work across multiple goroutines, skips items that already have licenses,
-// and logs errors without stopping processing.
+// It distributes work across multiple goroutines, skips items that already have licenses
+// or are excluded by the filter, and logs errors without stopping processing.
+// The outcome of every item is recorded in the report, if one is given.
 func processItemsParallel[T enrichableItem](
 	ctx context.Context,
 	items []T,
@@ -89,6 +112,9 @@ func processItemsParallel[T enrichableItem](
 	prov provider.Provider,
 	cacheInstance cache.Cache,
 	cacheTTL time.Duration,
+	curations provider.Curator,
+	filter *compiledFilter,
+	report *Report,
 	logger *slog.Logger,
 ) error {
 	// Create buffered channel sized to all items to avoid blocking on send
@@ -101,47 +127,57 @@ func processItemsParallel[T enrichableItem](
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
-				// Skip if item already has license
-				if j.item.HasLicense() {
-					continue
-				}
-
-				// Get license from provider (cache-through pattern)
-				lic, licErr := provider.Get(ctx, provider.GetOptions{
-					Purl:     j.purl,
-					Provider: prov,
-					Cache:    cacheInstance,
-					CacheTTL: cacheTTL,
-				})
-				if licErr != nil {
-					// Log error but continue processing other items
-					logger.ErrorContext(ctx, "failed to get license for item",
-						"purl", j.purl,
-						"id", j.item.GetLogID(),
-						"error", licErr)
-					continue
-				}
-
-				// Update item if license was found
-				if lic != "" {
-					j.item.SetLicense(lic)
-				}
+				report.add(enrichItem(ctx, j, prov, cacheInstance, cacheTTL, curations, logger))
 			}
 		}()
 	}
 
-	// Queue all items for processing
-	for _, item := range items {
+	// Collect the items to process
+	pending := make([]job[T], 0, len(items))
+	for i, item := range items {
 		purl, purlErr := item.GetPurl()
 		if purlErr != nil {
 			// Log error but continue processing other items
 			logger.ErrorContext(ctx, "failed to get purl for item",
 				"id", item.GetLogID(),
 				"error", purlErr)
+			report.add(ItemResult{ID: item.GetLogID(), Status: StatusNoPurl, Error: purlErr.Error(), index: i})
+			continue
+		}
+
+		// Leave filtered items untouched
+		if !filter.allows(purl, item.filterAttributes()) {
+			logger.DebugContext(ctx, "skipped (filtered)",
+				"purl", purl,
+				"id", item.GetLogID())
+			report.add(ItemResult{ID: item.GetLogID(), Purl: purl, Status: StatusFiltered, index: i})
 			continue
 		}
 
-		jobs <- job[T]{item: item, purl: purl}
+		pending = append(pending, job[T]{item: item, purl: purl, index: i})
+	}
+
+	// Look up the licenses in batches if the provider supports it, instead of one request per item. Up to
+	// parallelism batches are looked up at once, and the items of a batch are queued as soon as it is done.
+	if provider.SupportsBatch(prov) {
+		var batches sync.WaitGroup
+		semaphore := make(chan struct{}, parallelism)
+		for batch := range slices.Chunk(pending, batchSize) {
+			semaphore <- struct{}{}
+			batches.Go(func() {
+				defer func() { <-semaphore }()
+				prefetch(ctx, batch, prov, cacheInstance, cacheTTL, curations, logger)
+				for _, j := range batch {
+					jobs <- j
+				}
+			})
+		}
+		batches.Wait()
+	} else {
+		// Queue all items for processing
+		for _, j := range pending {
+			jobs <- j
+		}
 	}
 
 	// Signal no more jobs and wait for workers to finish
@@ -150,3 +186,120 @@ func processItemsParallel[T enrichableItem](
 
 	return nil
 }
+
+// enrichItem looks up and sets the license of a single item and returns its result.
+func enrichItem[T enrichableItem](
+	ctx context.Context,
+	j job[T],
+	prov provider.Provider,
+	cacheInstance cache.Cache,
+	cacheTTL time.Duration,
+	curations provider.Curator,
+	logger *slog.Logger,
+) ItemResult {
+	result := ItemResult{ID: j.item.GetLogID(), Purl: j.purl, index: j.index}
+
+	// Skip if item already has license
+	if j.item.HasLicense() {
+		result.Status = StatusExistingLicense
+		return result
+	}
+
+	// Get license from curations or provider (cache-through pattern)
+	var lookup provider.Result
+	var licErr error
+	if j.prefetched != nil {
+		lookup, licErr = j.prefetched.Result, j.prefetched.Err
+	} else {
+		lookup, licErr = provider.Lookup(ctx, provider.GetOptions{
+			Purl:      j.purl,
+			Provider:  prov,
+			Cache:     cacheInstance,
+			CacheTTL:  cacheTTL,
+			Curations: curations,
+		})
+	}
+	if errors.Is(licErr, provider.ErrBlocked) {
+		// Private purls are expected to be resolved by local providers or overrides only
+		logger.WarnContext(ctx, "lookup blocked by privacy guard",
+			"purl", j.purl,
+			"id", j.item.GetLogID())
+		result.Status = StatusBlocked
+		return result
+	}
+	if licErr != nil {
+		// Log error but continue processing other items
+		logger.ErrorContext(ctx, "failed to get license for item",
+			"purl", j.purl,
+			"id", j.item.GetLogID(),
+			"error", licErr)
+		result.Status = StatusError
+		if errors.Is(licErr, provider.ErrLicenseNotFound) {
+			result.Status = StatusNotFound
+		}
+		result.Error = licErr.Error()
+		return result
+	}
+
+	// Update item if license was found
+	if lookup.License == "" {
+		result.Status = StatusNotFound
+		return result
+	}
+	if len(lookup.Disagreement) > 0 {
+		logger.WarnContext(ctx, "providers disagree on license",
+			"purl", j.purl,
+			"id", j.item.GetLogID(),
+			"license", lookup.License,
+			"answers", lookup.Disagreement)
+	}
+	j.item.SetLicense(lookup.License)
+	result.Status = StatusEnriched
+	result.License = lookup.License
+	result.Source = lookup.Source
+	result.Curation = lookup.Curation
+	result.Provider = lookup.Provider
+	result.Disagreement = lookup.Disagreement
+	return result
+}
+
+// prefetch looks up the licenses of a batch of jobs without a license with one batch lookup and attaches the results
+// to the jobs. Jobs without a result, e.g. because the batch failed, are looked up one by one by enrichItem.
+func prefetch[T enrichableItem](
+	ctx context.Context,
+	jobs []job[T],
+	prov provider.Provider,
+	cacheInstance cache.Cache,
+	cacheTTL time.Duration,
+	curations provider.Curator,
+	logger *slog.Logger,
+) {
+	purls := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		if !j.item.HasLicense() {
+			purls = append(purls, j.purl)
+		}
+	}
+	if len(purls) == 0 {
+		return
+	}
+
+	results, err := provider.LookupMany(ctx, provider.GetOptions{
+		Provider:  prov,
+		Cache:     cacheInstance,
+		CacheTTL:  cacheTTL,
+		Curations: curations,
+	}, purls)
+	if errors.Is(err, errors.ErrUnsupported) {
+		// Some provider of the chain cannot batch, so look up the items one by one
+		logger.DebugContext(ctx, "batch lookup not supported", "error", err)
+	} else if err != nil {
+		// Log error and look up the items that were not curated or cached one by one
+		logger.WarnContext(ctx, "batch lookup failed", "purls", len(purls), "error", err)
+	}
+	for i := range jobs {
+		if result, ok := results[jobs[i].purl]; ok && !jobs[i].item.HasLicense() {
+			jobs[i].prefetched = &result
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package license
+
+import (
+	"cmp"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// minConfidence is the minimum share of a license text that must be found in a text to match the license.
+	minConfidence = 0.8
+	// exactConfidence is the minimum share of a license text that must be found, and the maximum share of
+	// other words that may be added, for a text to be considered unmodified. The tolerance covers the names
+	// filled into a license, such as in "Neither the name of Google Inc. nor the names of its contributors".
+	exactConfidence = 0.95
+	// shingleSize is the number of consecutive words compared between texts.
+	shingleSize = 3
+	// regionGap is the number of words without a matching word sequence that separates two regions of a text.
+	regionGap = 32
+)
+
+// referenceEnds are the word sequences that end the part of a license text that identifies it: the
+// instructions on how to apply a license, and the GPL appended to the LGPL 3.0, are often missing from
+// license files.
+//
+//nolint:gochecknoglobals // constant lookup table
+var referenceEnds = []string{
+	"end of terms and conditions",
+	"gnu general public license version 29 june 2007",
+}
+
+// Match is a license found in a text.
+type Match struct {
+	// ID is the SPDX license identifier.
+	ID string `json:"id"`
+	// Confidence is the share of the license text that was found, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+	// Modified is true if parts of the license text are missing or other words were added.
+	Modified bool `json:"modified"`
+	// StartLine is the first line of the license in the text, starting at 1.
+	StartLine int `json:"startLine"`
+	// EndLine is the last line of the license in the text.
+	EndLine int `json:"endLine"`
+}
+
+// reference is an embedded license text prepared for comparison.
+type reference struct {
+	id       string
+	shingles map[string]bool
+}
+
+// loadReferences prepares the embedded license texts for comparison, once.
+//
+//nolint:gochecknoglobals // computed once from the embedded texts
+var loadReferences = sync.OnceValue(func() []reference {
+	entries, err := texts.ReadDir("texts")
+	if err != nil {
+		return nil
+	}
+
+	var references []reference
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if !ok {
+			continue
+		}
+		data, err := texts.ReadFile(path.Join("texts", entry.Name()))
+		if err != nil {
+			continue
+		}
+		words := wordTexts(normalizeWords(string(data)))
+		for _, end := range referenceEnds {
+			if i := indexWords(words, strings.Fields(end)); i > 0 {
+				words = words[:i]
+			}
+		}
+		shingles := make(map[string]bool)
+		for i := 0; i+shingleSize <= len(words); i++ {
+			shingles[strings.Join(words[i:i+shingleSize], " ")] = true
+		}
+		references = append(references, reference{id: NormalizeID(name), shingles: shingles})
+	}
+	return references
+})
+
+// Classify finds the licenses in a text, such as the contents of a LICENSE or COPYING file, by comparing it
+// with the embedded texts of the SPDX License List. Whitespace, punctuation, markup and copyright notices are
+// ignored.
+//
+// A text may contain several licenses; they are returned in order of appearance. A license whose text is
+// contained in a longer one, such as BSD-2-Clause in BSD-3-Clause, is only found on its own.
+func Classify(text string) []Match {
+	matches := classify(text)
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return cmp.Compare(a.StartLine, b.StartLine)
+	})
+	return matches
+}
+
+// Identify returns the license of a license text. If the text contains several licenses, the most complete
+// one is returned. It returns false if no license is found.
+func Identify(text string) (string, bool) {
+	matches := classify(text)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return matches[0].ID, true
+}
+
+// candidate is a license whose text is similar enough to a text.
+type candidate struct {
+	id      string
+	matched int
+	total   int
+	covered []int
+}
+
+// score ranks candidates: missing words count against a license, so that a text is not matched by a longer
+// license that contains it.
+func (c candidate) score() int {
+	return 2*c.matched - c.total
+}
+
+// classify finds the licenses in a text, most complete first.
+func classify(text string) []Match {
+	words := normalizeWords(text)
+	positions := make(map[string][]int)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		shingle := strings.Join(wordTexts(words[i:i+shingleSize]), " ")
+		positions[shingle] = append(positions[shingle], i)
+	}
+
+	var candidates []candidate
+	for _, ref := range loadReferences() {
+		matched := 0
+		covered := make(map[int]bool)
+		for shingle := range ref.shingles {
+			if starts, ok := positions[shingle]; ok {
+				matched++
+				for _, start := range starts {
+					for i := start; i < start+shingleSize; i++ {
+						covered[i] = true
+					}
+				}
+			}
+		}
+		if matched == 0 || float64(matched) < minConfidence*float64(len(ref.shingles)) {
+			continue
+		}
+		indexes := make([]int, 0, len(covered))
+		for i := range covered {
+			indexes = append(indexes, i)
+		}
+		slices.Sort(indexes)
+		candidates = append(candidates, candidate{
+			id:      ref.id,
+			matched: matched,
+			total:   len(ref.shingles),
+			covered: largestRun(indexes),
+		})
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(b.score(), a.score()), cmp.Compare(a.id, b.id))
+	})
+
+	// Skip licenses found mostly in the words of a more complete one
+	var accepted []candidate
+	claimed := make(map[int]int)
+	for _, c := range candidates {
+		overlap := 0
+		for _, i := range c.covered {
+			if claimed[i] > 0 {
+				overlap++
+			}
+		}
+		if 2*overlap >= len(c.covered) {
+			continue
+		}
+		for _, i := range c.covered {
+			claimed[i]++
+		}
+		accepted = append(accepted, c)
+	}
+
+	matches := make([]Match, 0, len(accepted))
+	for _, c := range accepted {
+		// The region of a license excludes the words of the other licenses
+		own := slices.DeleteFunc(slices.Clone(c.covered), func(i int) bool {
+			return claimed[i] > 1
+		})
+		if len(own) == 0 {
+			own = c.covered
+		}
+		own = largestRun(own)
+
+		start, end := own[0], own[len(own)-1]
+
+		// Words in the region that are not part of the license text were added
+		found := 0
+		for _, i := range c.covered {
+			if i >= start && i <= end {
+				found++
+			}
+		}
+		added := float64(end-start+1-found) / float64(end-start+1)
+		confidence := float64(c.matched) / float64(c.total)
+		matches = append(matches, Match{
+			ID:         c.id,
+			Confidence: confidence,
+			Modified:   confidence < exactConfidence || added > 1-exactConfidence,
+			StartLine:  words[start].line,
+			EndLine:    words[end].line,
+		})
+	}
+	return matches
+}
+
+// largestRun returns the largest run of sorted indexes without gaps of regionGap words. Word sequences of a
+// license that also occur elsewhere in a text are outside of this run.
+func largestRun(indexes []int) []int {
+	if len(indexes) == 0 {
+		return indexes
+	}
+	bestStart, bestEnd := 0, 1
+	start := 0
+	for n := 1; n < len(indexes); n++ {
+		if indexes[n]-indexes[n-1] > regionGap {
+			start = n
+		}
+		if n+1-start > bestEnd-bestStart {
+			bestStart, bestEnd = start, n+1
+		}
+	}
+	return indexes[bestStart:bestEnd]
+}
+
+// word is a normalized word of a text and the line it is on.
+type word struct {
+	text string
+	line int
+}
+
+// copyrightNotice matches copyright notices up to the end of the sentence or line, since they differ between
+// copies of a license.
+//
+//nolint:gochecknoglobals // compiled once
+var copyrightNotice = regexp.MustCompile(
+	`(?i)(copyright\s*(\(c\)|©|\d|\[|<)|\(c\)\s*\d|©)[^\n]{0,80}?(\.\s|\n|$)|all rights reserved`)
+
+// markupTag matches HTML and XML tags.
+//
+//nolint:gochecknoglobals // compiled once
+var markupTag = regexp.MustCompile(`<[^>\s][^>]*>`)
+
+// normalizeWords splits a text into lower case words, without copyright notices, markup, punctuation and
+// single characters such as list markers.
+func normalizeWords(text string) []word {
+	// Blank out instead of removing, to keep the line numbers
+	blank := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, s)
+	}
+	text = strings.ToLower(text)
+	text = copyrightNotice.ReplaceAllStringFunc(text, blank)
+	text = markupTag.ReplaceAllStringFunc(text, blank)
+
+	var words []word
+	for i, line := range strings.Split(text, "\n") {
+		for _, w := range strings.FieldsFunc(line, func(r rune) bool {
+			return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+		}) {
+			if len(w) == 1 {
+				continue
+			}
+			switch w {
+			case "licence":
+				w = "license"
+			case "https":
+				w = "http"
+			}
+			words = append(words, word{text: w, line: i + 1})
+		}
+	}
+	return words
+}
+
+// wordTexts returns the texts of the words.
+func wordTexts(words []word) []string {
+	result := make([]string, len(words))
+	for i, w := range words {
+		result[i] = w.text
+	}
+	return result
+}
+
+// indexWords returns the index of the first occurrence of the sequence in words, or -1.
+func indexWords(words, sequence []string) int {
+	for i := 0; i+len(sequence) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(sequence)], sequence) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,214 @@
+package license_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// goLicense is the BSD-3-Clause license of the Go project, with the names of the copyright holders filled in.
+const goLicense = `Copyright (c) 2009 The Go Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+copyright notice, this list of conditions and the following disclaimer
+in the documentation and/or other materials provided with the
+distribution.
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+// TestIdentify tests identifying licenses from license texts.
+func TestIdentify(t *testing.T) {
+	t.Parallel()
+
+	mit, _ := license.Text("MIT")
+	apache, _ := license.Text("Apache-2.0")
+	lgpl, _ := license.Text("LGPL-3.0")
+
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "license file with copyright notice",
+			text:   "MIT License\n\nCopyright (c) 2024 Jane Doe <jane@example.com>\n\n" + mit,
+			want:   "MIT",
+			wantOK: true,
+		},
+		{
+			name:   "markup",
+			text:   "<h1>MIT License</h1>\n<p>Copyright © 2020 ACME</p>\n" + strings.ReplaceAll(mit, "\n", "<br>\n"),
+			want:   "MIT",
+			wantOK: true,
+		},
+		{
+			name:   "rewrapped text",
+			text:   strings.Join(strings.Fields(apache), " "),
+			want:   "Apache-2.0",
+			wantOK: true,
+		},
+		{
+			name:   "without appendix",
+			text:   apache[:strings.Index(apache, "APPENDIX")],
+			want:   "Apache-2.0",
+			wantOK: true,
+		},
+		{
+			name:   "filled in names",
+			text:   goLicense,
+			want:   "BSD-3-Clause",
+			wantOK: true,
+		},
+		{
+			name:   "shorter license contained in a longer one",
+			text:   goLicense[:strings.Index(goLicense, "   * Neither")] + goLicense[strings.Index(goLicense, "THIS"):],
+			want:   "BSD-2-Clause",
+			wantOK: true,
+		},
+		{
+			name:   "LGPL without the GPL",
+			text:   lgpl[:strings.Index(lgpl, "GNU GENERAL PUBLIC LICENSE")],
+			want:   "LGPL-3.0-only",
+			wantOK: true,
+		},
+		{
+			name:   "not a license",
+			text:   "This project is licensed under the terms in the file COPYING.",
+			wantOK: false,
+		},
+		{
+			name:   "empty",
+			text:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := license.Identify(tt.text)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Identify() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestIdentify_EmbeddedTexts tests that every embedded license text identifies its own license.
+func TestIdentify_EmbeddedTexts(t *testing.T) {
+	t.Parallel()
+
+	for _, id := range []string{
+		"0BSD", "AGPL-3.0-only", "Apache-1.1", "BSD-2-Clause", "BSD-4-Clause", "BSL-1.0", "CC0-1.0", "CDDL-1.1",
+		"EPL-1.0", "EPL-2.0", "GPL-2.0-only", "GPL-3.0-only", "ISC", "LGPL-2.1-only", "MPL-2.0", "Python-2.0",
+		"Unlicense", "X11", "Zlib",
+	} {
+		text, ok := license.Text(id)
+		if !ok {
+			t.Fatalf("Text(%q) not found", id)
+		}
+		if got, _ := license.Identify(text); got != id {
+			t.Errorf("Identify(Text(%q)) = %q", id, got)
+		}
+	}
+}
+
+// TestClassify tests finding several licenses, their lines and modifications in a text.
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	mit, _ := license.Text("MIT")
+	apache, _ := license.Text("Apache-2.0")
+	lgpl, _ := license.Text("LGPL-3.0")
+
+	tests := []struct {
+		name string
+		text string
+		want []license.Match
+	}{
+		{
+			name: "single license",
+			text: "MIT License\n\nCopyright (c) 2024 Jane Doe\n\n" + mit,
+			want: []license.Match{{ID: "MIT", Confidence: 1, StartLine: 5, EndLine: 21}},
+		},
+		{
+			name: "several licenses",
+			text: "Copyright (c) 2024 Jane Doe\n\n" + mit + "\n---\n\n" + apache,
+			want: []license.Match{
+				{ID: "MIT", Confidence: 1, StartLine: 3, EndLine: 19},
+				{ID: "Apache-2.0", Confidence: 1, StartLine: 23, EndLine: 198},
+			},
+		},
+		{
+			name: "license with appended license",
+			text: lgpl,
+			want: []license.Match{
+				{ID: "LGPL-3.0-only", Confidence: 1, StartLine: 1, EndLine: 47},
+				{ID: "GPL-3.0-only", Confidence: 1, StartLine: 60, EndLine: 248},
+			},
+		},
+		{
+			name: "added clause",
+			text: strings.Replace(mit, "The above copyright notice",
+				"The Software shall be used for Good, not Evil, and never on weekends or holidays.\n\n"+
+					"The above copyright notice", 1),
+			want: []license.Match{{ID: "MIT", Confidence: 0.9935, Modified: true, StartLine: 1, EndLine: 19}},
+		},
+		{
+			name: "replaced words",
+			text: strings.NewReplacer("merge", "combine", "sublicense", "rent", "substantial", "large").Replace(mit),
+			want: []license.Match{{ID: "MIT", Confidence: 0.9423, Modified: true, StartLine: 1, EndLine: 17}},
+		},
+		{
+			name: "filled in names",
+			text: goLicense,
+			want: []license.Match{{ID: "BSD-3-Clause", Confidence: 0.9644, StartLine: 3, EndLine: 27}},
+		},
+		{
+			name: "truncated",
+			text: mit[:len(mit)/2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := license.Classify(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Classify() = %+v, want %+v", got, tt.want)
+			}
+			for i, match := range got {
+				want := tt.want[i]
+				if match.ID != want.ID || match.Modified != want.Modified ||
+					match.StartLine != want.StartLine || match.EndLine != want.EndLine ||
+					match.Confidence < want.Confidence || match.Confidence > want.Confidence+0.0001 {
+					t.Errorf("Classify()[%d] = %+v, want %+v", i, match, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+// Package license parses and normalizes SPDX license expressions.
+//
+// See https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+package license
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidExpression is returned when a string is not a valid SPDX license expression.
+var ErrInvalidExpression = errors.New("invalid license expression")
+
+// Operator combines the operands of an expression.
+type Operator string
+
+const (
+	// OperatorAnd requires all operands to be complied with.
+	OperatorAnd Operator = "AND"
+	// OperatorOr allows choosing any one of the operands.
+	OperatorOr Operator = "OR"
+)
+
+// operatorWith attaches an exception to a license.
+const operatorWith = "WITH"
+
+// Expression is a node of a parsed SPDX license expression.
+//
+// A leaf holds a single license, optionally with an exception. Other nodes combine two or more operands
+// with the same operator; nested operands with the same operator are flattened.
+type Expression struct {
+	// Operator is the operator of a compound expression, empty for a leaf.
+	Operator Operator
+	// Operands are the operands of a compound expression.
+	Operands []*Expression
+	// License is the license identifier of a leaf, e.g. "MIT" or "LicenseRef-acme".
+	License string
+	// Exception is the exception identifier of a leaf, e.g. "Classpath-exception-2.0".
+	Exception string
+}
+
+// Parse parses an SPDX license expression.
+//
+// Operators may be upper or lower case. Identifiers are kept as written; use Normalize to
+// map deprecated identifiers to their current form.
+func Parse(s string) (*Expression, error) {
+	p := &parser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty expression", ErrInvalidExpression)
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", ErrInvalidExpression, s, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: %q: unexpected %q", ErrInvalidExpression, s, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// IsLeaf returns true if the expression is a single license.
+func (e *Expression) IsLeaf() bool {
+	return e.Operator == ""
+}
+
+// String returns the expression in SPDX syntax, with parentheses only where needed.
+func (e *Expression) String() string {
+	if e.IsLeaf() {
+		if e.Exception != "" {
+			return e.License + " " + operatorWith + " " + e.Exception
+		}
+		return e.License
+	}
+
+	parts := make([]string, len(e.Operands))
+	for i, operand := range e.Operands {
+		parts[i] = operand.String()
+		// AND binds tighter than OR, so only OR inside AND needs parentheses
+		if e.Operator == OperatorAnd && operand.Operator == OperatorOr {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+string(e.Operator)+" ")
+}
+
+// Leaves returns the distinct leaves of the expression in order of appearance.
+func (e *Expression) Leaves() []*Expression {
+	var leaves []*Expression
+	seen := map[string]bool{}
+	var walk func(*Expression)
+	walk = func(node *Expression) {
+		if !node.IsLeaf() {
+			for _, operand := range node.Operands {
+				walk(operand)
+			}
+			return
+		}
+		if key := node.String(); !seen[key] {
+			seen[key] = true
+			leaves = append(leaves, node)
+		}
+	}
+	walk(e)
+	return leaves
+}
+
+// Normalize returns a copy of the expression with deprecated license identifiers replaced by their
+// current form, e.g. "GPL-2.0" by "GPL-2.0-only" and "GPL-2.0+" by "GPL-2.0-or-later".
+func (e *Expression) Normalize() *Expression {
+	if e.IsLeaf() {
+		return &Expression{License: NormalizeID(e.License), Exception: e.Exception}
+	}
+	operands := make([]*Expression, len(e.Operands))
+	for i, operand := range e.Operands {
+		operands[i] = operand.Normalize()
+	}
+	return &Expression{Operator: e.Operator, Operands: operands}
+}
+
+// deprecatedGNU are the GNU license identifiers that were deprecated in favour of "-only" and
+// "-or-later" variants.
+//
+//nolint:gochecknoglobals // constant lookup table
+var deprecatedGNU = map[string]bool{
+	"GPL-1.0": true, "GPL-2.0": true, "GPL-3.0": true,
+	"LGPL-2.0": true, "LGPL-2.1": true, "LGPL-3.0": true,
+	"AGPL-1.0": true, "AGPL-3.0": true,
+	"GFDL-1.1": true, "GFDL-1.2": true, "GFDL-1.3": true,
+}
+
+// NormalizeID maps a deprecated license identifier to its current form.
+// Other identifiers are returned unchanged.
+func NormalizeID(id string) string {
+	base := strings.TrimSuffix(id, "+")
+	for deprecated := range deprecatedGNU {
+		if !strings.EqualFold(base, deprecated) {
+			continue
+		}
+		if strings.HasSuffix(id, "+") {
+			return deprecated + "-or-later"
+		}
+		return deprecated + "-only"
+	}
+	return id
+}
+
+// Join combines license expressions with the operator, parenthesizing compound expressions.
+func Join(operator Operator, expressions []string) string {
+	if len(expressions) == 1 {
+		return expressions[0]
+	}
+	parts := make([]string, len(expressions))
+	for i, expression := range expressions {
+		if strings.Contains(expression, " ") {
+			expression = "(" + expression + ")"
+		}
+		parts[i] = expression
+	}
+	return strings.Join(parts, " "+string(operator)+" ")
+}
+
+// tokenize splits an expression into identifiers, operators and parentheses.
+func tokenize(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parser is a recursive descent parser over the tokens of an expression.
+//
+// The grammar, from lowest to highest precedence:
+//
+//	or   = and { "OR" and }
+//	and  = with { "AND" with }
+//	with = atom [ "WITH" id ]
+//	atom = id | "(" or ")"
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the next token, or "" at the end of the input.
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// acceptOperator consumes the next token if it is the given operator in upper or lower case.
+func (p *parser) acceptOperator(op string) bool {
+	if next := p.peek(); next == op || next == strings.ToLower(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr parses a sequence of AND expressions separated by OR.
+func (p *parser) parseOr() (*Expression, error) {
+	return p.parseSequence(OperatorOr, p.parseAnd)
+}
+
+// parseAnd parses a sequence of WITH expressions separated by AND.
+func (p *parser) parseAnd() (*Expression, error) {
+	return p.parseSequence(OperatorAnd, p.parseWith)
+}
+
+// parseSequence parses operands separated by op, flattening nested operands with the same operator.
+func (p *parser) parseSequence(op Operator, parseOperand func() (*Expression, error)) (*Expression, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	var operands []*Expression
+	for p.acceptOperator(string(op)) {
+		if operands == nil {
+			operands = appendOperand(operands, op, first)
+		}
+		next, nextErr := parseOperand()
+		if nextErr != nil {
+			return nil, nextErr
+		}
+		operands = appendOperand(operands, op, next)
+	}
+	if operands == nil {
+		return first, nil
+	}
+	return &Expression{Operator: op, Operands: operands}, nil
+}
+
+// appendOperand appends operand, or its operands if it uses the same operator.
+func appendOperand(operands []*Expression, op Operator, operand *Expression) []*Expression {
+	if operand.Operator == op {
+		return append(operands, operand.Operands...)
+	}
+	return append(operands, operand)
+}
+
+// parseWith parses a license with an optional exception.
+func (p *parser) parseWith() (*Expression, error) {
+	expr, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	if !p.acceptOperator(operatorWith) {
+		return expr, nil
+	}
+	if !expr.IsLeaf() || expr.Exception != "" {
+		return nil, errors.New("WITH must follow a single license")
+	}
+	exception, err := p.parseID()
+	if err != nil {
+		return nil, err
+	}
+	expr.Exception = exception
+	return expr, nil
+}
+
+// parseAtom parses a license identifier or a parenthesized expression.
+func (p *parser) parseAtom() (*Expression, error) {
+	if p.peek() != "(" {
+		id, err := p.parseID()
+		if err != nil {
+			return nil, err
+		}
+		return &Expression{License: id}, nil
+	}
+
+	p.pos++
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ")" {
+		return nil, errors.New("missing closing parenthesis")
+	}
+	p.pos++
+	return expr, nil
+}
+
+// parseID parses a license or exception identifier.
+func (p *parser) parseID() (string, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return "", errors.New("unexpected end of expression")
+	case token == "(" || token == ")" || isOperator(token):
+		return "", fmt.Errorf("unexpected %q", token)
+	}
+	for i, r := range token {
+		valid := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '.' || r == '-' || r == ':' || r == '+' && i == len(token)-1
+		if !valid {
+			return "", fmt.Errorf("invalid character %q in identifier %q", r, token)
+		}
+	}
+	p.pos++
+	return token, nil
+}
+
+// isOperator returns true if the token is an operator in upper or lower case.
+func isOperator(token string) bool {
+	switch strings.ToUpper(token) {
+	case string(OperatorAnd), string(OperatorOr), operatorWith:
+		return token == strings.ToUpper(token) || token == strings.ToLower(token)
+	}
+	return false
+}
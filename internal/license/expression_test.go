@@ -0,0 +1,167 @@
+package license_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// TestParse tests parsing and formatting expressions.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "single license", input: "MIT", want: "MIT"},
+		{name: "or", input: "MIT OR Apache-2.0", want: "MIT OR Apache-2.0"},
+		{
+			name:  "lowercase operators",
+			input: "MIT or Apache-2.0 and BSD-3-Clause",
+			want:  "MIT OR Apache-2.0 AND BSD-3-Clause",
+		},
+		{
+			name:  "and binds tighter",
+			input: "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			want:  "(MIT OR Apache-2.0) AND BSD-3-Clause",
+		},
+		{name: "redundant parentheses", input: "((MIT))", want: "MIT"},
+		{name: "flattened", input: "MIT AND (ISC AND Zlib)", want: "MIT AND ISC AND Zlib"},
+		{
+			name:  "with exception",
+			input: "GPL-2.0-only WITH Classpath-exception-2.0",
+			want:  "GPL-2.0-only WITH Classpath-exception-2.0",
+		},
+		{name: "plus", input: "LGPL-2.1+", want: "LGPL-2.1+"},
+		{
+			name:  "license ref",
+			input: "LicenseRef-acme OR DocumentRef-x:LicenseRef-y",
+			want:  "LicenseRef-acme OR DocumentRef-x:LicenseRef-y",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := license.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("Parse().String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParse_Invalid tests that malformed expressions are rejected.
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"",
+		"MIT OR",
+		"(MIT",
+		"MIT)",
+		"MIT Apache-2.0",
+		"Apache License 2.0",
+		"(MIT OR ISC) WITH Classpath-exception-2.0",
+		"MIT And ISC",
+		"GPL+-2.0",
+	} {
+		if _, err := license.Parse(input); !errors.Is(err, license.ErrInvalidExpression) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidExpression", input, err)
+		}
+	}
+}
+
+// TestExpression_Leaves tests that leaves are returned once in order of appearance.
+func TestExpression_Leaves(t *testing.T) {
+	t.Parallel()
+
+	expr, err := license.Parse("MIT AND (Apache-2.0 OR MIT) AND GPL-2.0-only WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	leaves := expr.Leaves()
+	want := []string{"MIT", "Apache-2.0", "GPL-2.0-only WITH Classpath-exception-2.0"}
+	if len(leaves) != len(want) {
+		t.Fatalf("Leaves() = %v, want %v", leaves, want)
+	}
+	for i := range want {
+		if got := leaves[i].String(); got != want[i] {
+			t.Errorf("Leaves()[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// TestNormalizeID tests the NormalizeID function.
+func TestNormalizeID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "GPL-2.0", want: "GPL-2.0-only"},
+		{id: "GPL-2.0+", want: "GPL-2.0-or-later"},
+		{id: "lgpl-2.1+", want: "LGPL-2.1-or-later"},
+		{id: "AGPL-3.0", want: "AGPL-3.0-only"},
+		{id: "GPL-3.0-only", want: "GPL-3.0-only"},
+		{id: "Apache-2.0", want: "Apache-2.0"},
+	}
+
+	for _, tt := range tests {
+		if got := license.NormalizeID(tt.id); got != tt.want {
+			t.Errorf("NormalizeID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+// TestJoin tests combining expressions with an operator.
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		operator    license.Operator
+		expressions []string
+		want        string
+	}{
+		{operator: license.OperatorAnd, expressions: []string{"MIT"}, want: "MIT"},
+		{operator: license.OperatorAnd, expressions: []string{"MIT", "Apache-2.0"}, want: "MIT AND Apache-2.0"},
+		{
+			operator:    license.OperatorOr,
+			expressions: []string{"MIT", "GPL-2.0-only WITH Classpath-exception-2.0"},
+			want:        "MIT OR (GPL-2.0-only WITH Classpath-exception-2.0)",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := license.Join(tt.operator, tt.expressions); got != tt.want {
+			t.Errorf("Join(%q, %q) = %q, want %q", tt.operator, tt.expressions, got, tt.want)
+		}
+	}
+}
+
+// TestExpression_Normalize tests that deprecated identifiers are normalized throughout an expression.
+func TestExpression_Normalize(t *testing.T) {
+	t.Parallel()
+
+	expr, err := license.Parse("MIT OR (GPL-2.0+ AND LGPL-2.1 WITH Classpath-exception-2.0)")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := "MIT OR GPL-2.0-or-later AND LGPL-2.1-only WITH Classpath-exception-2.0"
+	if got := expr.Normalize().String(); got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+	if got := expr.String(); got != "MIT OR GPL-2.0+ AND LGPL-2.1 WITH Classpath-exception-2.0" {
+		t.Errorf("Normalize() modified the original expression: %q", got)
+	}
+}
@@ -0,0 +1,30 @@
+package license
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// ids are the identifiers of the SPDX License List, including deprecated identifiers such as "GPL-2.0", one per
+// line. They are from the spdx-license-ids package.
+//
+//go:embed ids.txt
+var ids string
+
+// listedIDs returns the identifiers of the SPDX License List, in lower case.
+//
+//nolint:gochecknoglobals // built once
+var listedIDs = sync.OnceValue(func() map[string]bool {
+	listed := make(map[string]bool)
+	for id := range strings.FieldsSeq(ids) {
+		listed[strings.ToLower(id)] = true
+	}
+	return listed
+})
+
+// Listed reports whether the license identifier is on the SPDX License List, whether or not its text is
+// embedded. Identifiers are matched case-insensitively, and the "+" suffix is ignored, e.g. "LGPL-2.1+".
+func Listed(id string) bool {
+	return listedIDs()[strings.ToLower(strings.TrimSuffix(id, "+"))]
+}
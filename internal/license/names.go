@@ -0,0 +1,257 @@
+package license
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// nameStopWords are the words ignored when matching license names.
+//
+//nolint:gochecknoglobals // constant lookup table
+var nameStopWords = map[string]bool{
+	"the": true, "version": true, "v": true, "license": true, "licence": true, "licensed": true, "under": true,
+}
+
+// names maps normalized license names (see nameKey) to SPDX license identifiers. Ambiguous names such as
+// "BSD" or "GPL" without version are deliberately missing.
+//
+//nolint:gochecknoglobals // constant lookup table
+var names = map[string]string{
+	"0bsd":                                 "0BSD",
+	"agpl3":                                "AGPL-3.0-only",
+	"agplv3":                               "AGPL-3.0-only",
+	"gnuafferogeneralpublic3":              "AGPL-3.0-only",
+	"agpl3orlater":                         "AGPL-3.0-or-later",
+	"agplv3orlater":                        "AGPL-3.0-or-later",
+	"gnuafferogeneralpublic3orlater":       "AGPL-3.0-or-later",
+	"apache2":                              "Apache-2.0",
+	"apache20":                             "Apache-2.0",
+	"apachesoftware":                       "Apache-2.0",
+	"apachesoftware2":                      "Apache-2.0",
+	"apachesoftware20":                     "Apache-2.0",
+	"asl2":                                 "Apache-2.0",
+	"asl20":                                "Apache-2.0",
+	"artistic20":                           "Artistic-2.0",
+	"boostsoftware10":                      "BSL-1.0",
+	"bsl10":                                "BSL-1.0",
+	"2clausebsd":                           "BSD-2-Clause",
+	"bsd2":                                 "BSD-2-Clause",
+	"bsd2clause":                           "BSD-2-Clause",
+	"freebsd":                              "BSD-2-Clause",
+	"simplifiedbsd":                        "BSD-2-Clause",
+	"3clausebsd":                           "BSD-3-Clause",
+	"bsd3":                                 "BSD-3-Clause",
+	"bsd3clause":                           "BSD-3-Clause",
+	"bsdnew":                               "BSD-3-Clause",
+	"modifiedbsd":                          "BSD-3-Clause",
+	"newbsd":                               "BSD-3-Clause",
+	"revisedbsd":                           "BSD-3-Clause",
+	"cc0":                                  "CC0-1.0",
+	"cc010":                                "CC0-1.0",
+	"cc010universal":                       "CC0-1.0",
+	"cc010universalpublicdomaindedication": "CC0-1.0",
+	"cddl10":                               "CDDL-1.0",
+	"commondevelopmentanddistribution10":   "CDDL-1.0",
+	"cddl11":                               "CDDL-1.1",
+	"commondevelopmentanddistribution11":   "CDDL-1.1",
+	"eclipsepublic10":                      "EPL-1.0",
+	"epl1":                                 "EPL-1.0",
+	"epl10":                                "EPL-1.0",
+	"eclipsepublic20":                      "EPL-2.0",
+	"epl2":                                 "EPL-2.0",
+	"epl20":                                "EPL-2.0",
+	"eupl12":                               "EUPL-1.2",
+	"europeanunionpublic12":                "EUPL-1.2",
+	"gnugeneralpublic2":                    "GPL-2.0-only",
+	"gnugeneralpublic20":                   "GPL-2.0-only",
+	"gpl2":                                 "GPL-2.0-only",
+	"gpl20":                                "GPL-2.0-only",
+	"gplv2":                                "GPL-2.0-only",
+	"gnugeneralpublic2orlater":             "GPL-2.0-or-later",
+	"gpl2orlater":                          "GPL-2.0-or-later",
+	"gplv2orlater":                         "GPL-2.0-or-later",
+	"gnugeneralpublic3":                    "GPL-3.0-only",
+	"gnugeneralpublic30":                   "GPL-3.0-only",
+	"gpl3":                                 "GPL-3.0-only",
+	"gpl30":                                "GPL-3.0-only",
+	"gplv3":                                "GPL-3.0-only",
+	"gnugeneralpublic3orlater":             "GPL-3.0-or-later",
+	"gpl3orlater":                          "GPL-3.0-or-later",
+	"gplv3orlater":                         "GPL-3.0-or-later",
+	"isc":                                  "ISC",
+	"iscl":                                 "ISC",
+	"gnulessergeneralpublic2":              "LGPL-2.0-only",
+	"gnulibrarygeneralpublic2":             "LGPL-2.0-only",
+	"lgpl2":                                "LGPL-2.0-only",
+	"lgplv2":                               "LGPL-2.0-only",
+	"gnulessergeneralpublic2orlater":       "LGPL-2.0-or-later",
+	"lgpl2orlater":                         "LGPL-2.0-or-later",
+	"lgplv2orlater":                        "LGPL-2.0-or-later",
+	"gnulessergeneralpublic21":             "LGPL-2.1-only",
+	"lgpl21":                               "LGPL-2.1-only",
+	"lgplv21":                              "LGPL-2.1-only",
+	"gnulessergeneralpublic21orlater":      "LGPL-2.1-or-later",
+	"lgpl21orlater":                        "LGPL-2.1-or-later",
+	"lgplv21orlater":                       "LGPL-2.1-or-later",
+	"gnulessergeneralpublic3":              "LGPL-3.0-only",
+	"lgpl3":                                "LGPL-3.0-only",
+	"lgplv3":                               "LGPL-3.0-only",
+	"gnulessergeneralpublic3orlater":       "LGPL-3.0-or-later",
+	"lgpl3orlater":                         "LGPL-3.0-or-later",
+	"lgplv3orlater":                        "LGPL-3.0-or-later",
+	"expat":                                "MIT",
+	"mit":                                  "MIT",
+	"mit0":                                 "MIT-0",
+	"mitnoattribution":                     "MIT-0",
+	"mozillapublic11":                      "MPL-1.1",
+	"mpl11":                                "MPL-1.1",
+	"mozillapublic20":                      "MPL-2.0",
+	"mpl2":                                 "MPL-2.0",
+	"mpl20":                                "MPL-2.0",
+	"microsoftpublic":                      "MS-PL",
+	"mspl":                                 "MS-PL",
+	"siloopenfont11":                       "OFL-1.1",
+	"ofl11":                                "OFL-1.1",
+	"postgresql":                           "PostgreSQL",
+	"psf":                                  "PSF-2.0",
+	"psf2":                                 "PSF-2.0",
+	"psf20":                                "PSF-2.0",
+	"pythonsoftwarefoundation":             "PSF-2.0",
+	"universalpermissive":                  "UPL-1.0",
+	"upl":                                  "UPL-1.0",
+	"upl10":                                "UPL-1.0",
+	"unlicense":                            "Unlicense",
+	"wtfpl":                                "WTFPL",
+	"zlib":                                 "Zlib",
+	"zliblibpng":                           "Zlib",
+}
+
+// urls maps normalized license URLs (see urlKey) to SPDX license identifiers. URLs of the SPDX License List and
+// of the Open Source Initiative that name the license are resolved without this table.
+//
+//nolint:gochecknoglobals // constant lookup table
+var urls = map[string]string{
+	"apache.org/licenses/license-1.1":           "Apache-1.1",
+	"apache.org/licenses/license-2.0":           "Apache-2.0",
+	"boost.org/license_1_0":                     "BSL-1.0",
+	"creativecommons.org/licenses/by/3.0":       "CC-BY-3.0",
+	"creativecommons.org/licenses/by/4.0":       "CC-BY-4.0",
+	"creativecommons.org/licenses/by-sa/4.0":    "CC-BY-SA-4.0",
+	"creativecommons.org/publicdomain/zero/1.0": "CC0-1.0",
+	"eclipse.org/legal/epl-2.0":                 "EPL-2.0",
+	"eclipse.org/legal/epl-v10":                 "EPL-1.0",
+	"eclipse.org/legal/epl-v20":                 "EPL-2.0",
+	"eclipse.org/org/documents/edl-v10":         "BSD-3-Clause",
+	"eclipse.org/org/documents/epl-v10":         "EPL-1.0",
+	"glassfish.dev.java.net/public/cddlv1.0":    "CDDL-1.0",
+	"gnu.org/licenses/agpl-3.0":                 "AGPL-3.0-only",
+	"gnu.org/licenses/gpl-2.0":                  "GPL-2.0-only",
+	"gnu.org/licenses/gpl-3.0":                  "GPL-3.0-only",
+	"gnu.org/licenses/lgpl-2.1":                 "LGPL-2.1-only",
+	"gnu.org/licenses/lgpl-3.0":                 "LGPL-3.0-only",
+	"gnu.org/licenses/old-licenses/gpl-2.0":     "GPL-2.0-only",
+	"gnu.org/licenses/old-licenses/lgpl-2.1":    "LGPL-2.1-only",
+	"mozilla.org/mpl/2.0":                       "MPL-2.0",
+	"opensource.org/licenses/bsd-license":       "BSD-2-Clause",
+	"opensource.org/licenses/cddl1":             "CDDL-1.0",
+	"opensource.org/licenses/eclipse-1.0":       "EPL-1.0",
+	"opensource.org/licenses/eclipse-2.0":       "EPL-2.0",
+	"oss.oracle.com/licenses/upl":               "UPL-1.0",
+	"scripts.sil.org/ofl":                       "OFL-1.1",
+	"unlicense.org":                             "Unlicense",
+	"wtfpl.net/about":                           "WTFPL",
+}
+
+// FromName maps a license name or SPDX identifier, such as "The Apache Software License, Version 2.0",
+// "GNU General Public License v2 or later (GPLv2+)" or "mit", to an SPDX license identifier. It returns false
+// if the name is unknown or ambiguous, such as "BSD".
+func FromName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", false
+	}
+	if _, ok := textName(textID(name)); ok && !strings.ContainsAny(name, " ,") {
+		return CanonicalID(NormalizeID(name)), true
+	}
+	id, ok := names[nameKey(name)]
+	return id, ok
+}
+
+// FromURL maps the URL of a license text, such as "https://www.apache.org/licenses/LICENSE-2.0.txt" or
+// "https://spdx.org/licenses/MIT.html", to an SPDX license identifier. It returns false if the URL is unknown.
+func FromURL(rawURL string) (string, bool) {
+	key := urlKey(rawURL)
+	if id, ok := urls[key]; ok {
+		return id, true
+	}
+
+	dir, last := path.Split(key)
+	switch dir {
+	case "spdx.org/licenses/":
+		// Keep the spelling of the identifier in the URL, since the key is lower case
+		i := strings.LastIndex(strings.ToLower(rawURL), last)
+		return CanonicalID(rawURL[i : i+len(last)]), true
+	case "opensource.org/licenses/":
+		return FromName(strings.TrimSuffix(last, "-license"))
+	default:
+		return "", false
+	}
+}
+
+// nameKey normalizes a license name for lookup: parenthesized abbreviations, punctuation and stop words are
+// dropped, repeated words are ignored, "+" becomes "or later", "v2" becomes "2" and the remaining words are
+// concatenated in lower case.
+func nameKey(name string) string {
+	name = strings.ToLower(name)
+	// Drop parenthesized abbreviations such as "(GPLv2+)", unless the name is only an abbreviation
+	if stripped := strings.TrimSpace(parenthesized.ReplaceAllString(name, " ")); stripped != "" {
+		name = stripped
+	}
+	name = strings.ReplaceAll(name, "+", " or later ")
+
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		// Versions are spelled "v2" as well as "version 2"
+		if len(word) > 1 && word[0] == 'v' && isDigits(word[1:]) {
+			word = word[1:]
+		}
+		if nameStopWords[word] || (seen[word] && !isDigits(word)) {
+			continue
+		}
+		seen[word] = true
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// parenthesized matches a parenthesized part of a license name.
+//
+//nolint:gochecknoglobals // compiled once
+var parenthesized = regexp.MustCompile(`\([^)]*\)`)
+
+// isDigits reports whether the word consists of digits only.
+func isDigits(word string) bool {
+	return strings.Trim(word, "0123456789") == ""
+}
+
+// urlKey normalizes a license URL for lookup: the scheme, "www.", the file extension and trailing slashes are
+// dropped, and the host and path are lower case.
+func urlKey(rawURL string) string {
+	key := strings.ToLower(strings.TrimSpace(rawURL))
+	for _, prefix := range []string{"https://", "http://"} {
+		key = strings.TrimPrefix(key, prefix)
+	}
+	key = strings.TrimPrefix(key, "www.")
+	if i := strings.IndexAny(key, "?#"); i >= 0 {
+		key = key[:i]
+	}
+	key = strings.TrimRight(key, "/")
+	for _, extension := range []string{".html", ".htm", ".txt", ".php", ".md"} {
+		key = strings.TrimSuffix(key, extension)
+	}
+	return key
+}
@@ -0,0 +1,74 @@
+package license_test
+
+import (
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// TestFromName tests mapping license names to SPDX identifiers.
+func TestFromName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "MIT", want: "MIT", wantOK: true},
+		{name: "mit", want: "MIT", wantOK: true},
+		{name: "The MIT License (MIT)", want: "MIT", wantOK: true},
+		{name: "apache-2.0", want: "Apache-2.0", wantOK: true},
+		{name: "The Apache Software License, Version 2.0", want: "Apache-2.0", wantOK: true},
+		{name: "Apache License, Version 2.0", want: "Apache-2.0", wantOK: true},
+		{name: "Apache 2", want: "Apache-2.0", wantOK: true},
+		{name: "GPL-2.0", want: "GPL-2.0-only", wantOK: true},
+		{name: "LGPL-2.1-or-later", want: "LGPL-2.1-or-later", wantOK: true},
+		{name: "GNU General Public License v2 or later (GPLv2+)", want: "GPL-2.0-or-later", wantOK: true},
+		{name: "GNU Lesser General Public License v3 (LGPLv3)", want: "LGPL-3.0-only", wantOK: true},
+		{name: "GPLv3+", want: "GPL-3.0-or-later", wantOK: true},
+		{name: "New BSD License", want: "BSD-3-Clause", wantOK: true},
+		{name: "Eclipse Public License - v 1.0", want: "EPL-1.0", wantOK: true},
+		{name: "Mozilla Public License 2.0 (MPL 2.0)", want: "MPL-2.0", wantOK: true},
+		{name: "CC0 1.0 Universal (CC0 1.0) Public Domain Dedication", want: "CC0-1.0", wantOK: true},
+		{name: "BSD", wantOK: false},
+		{name: "GPL", wantOK: false},
+		{name: "Proprietary", wantOK: false},
+		{name: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := license.FromName(tt.name)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("FromName(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestFromURL tests mapping license URLs to SPDX identifiers.
+func TestFromURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{url: "https://www.apache.org/licenses/LICENSE-2.0.txt", want: "Apache-2.0", wantOK: true},
+		{url: "http://www.apache.org/licenses/LICENSE-2.0", want: "Apache-2.0", wantOK: true},
+		{url: "https://opensource.org/licenses/MIT", want: "MIT", wantOK: true},
+		{url: "http://www.opensource.org/licenses/mit-license.php", want: "MIT", wantOK: true},
+		{url: "https://opensource.org/licenses/BSD-3-Clause", want: "BSD-3-Clause", wantOK: true},
+		{url: "https://spdx.org/licenses/MIT-0.html", want: "MIT-0", wantOK: true},
+		{url: "https://www.gnu.org/licenses/old-licenses/lgpl-2.1.html", want: "LGPL-2.1-only", wantOK: true},
+		{url: "https://www.eclipse.org/legal/epl-v10.html", want: "EPL-1.0", wantOK: true},
+		{url: "https://example.com/LICENSE", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := license.FromURL(tt.url)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("FromURL(%q) = %q, %v, want %q, %v", tt.url, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
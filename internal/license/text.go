@@ -0,0 +1,64 @@
+package license
+
+import (
+	"embed"
+	"path"
+	"strings"
+)
+
+// texts are the full texts of common licenses, one file per SPDX license identifier.
+//
+//go:embed texts/*.txt
+var texts embed.FS
+
+// Text returns the full text of the license with the given SPDX identifier.
+//
+// Identifiers are matched case-insensitively. The "-only", "-or-later" and "+" variants of a license share
+// the text of the base license, e.g. "GPL-2.0-or-later" returns the GPL-2.0 text. It returns false if the text
+// is not available, e.g. for LicenseRefs.
+func Text(id string) (string, bool) {
+	name, ok := textName(textID(id))
+	if !ok {
+		return "", false
+	}
+	data, err := texts.ReadFile(path.Join("texts", name+".txt"))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// CanonicalID returns the license identifier with the spelling of the SPDX License List if the text of the
+// license is embedded, e.g. "MIT" for "mit". Other identifiers are returned as they are.
+func CanonicalID(id string) string {
+	base := textID(id)
+	if name, ok := textName(base); ok {
+		return name + id[len(base):]
+	}
+	return id
+}
+
+// textName returns the name of the embedded text file of a license, without extension.
+func textName(id string) (string, bool) {
+	entries, err := texts.ReadDir("texts")
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if name := strings.TrimSuffix(entry.Name(), ".txt"); strings.EqualFold(name, id) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// textID returns the identifier of the text file of a license.
+func textID(id string) string {
+	id = strings.TrimSuffix(id, "+")
+	for _, suffix := range []string{"-only", "-or-later"} {
+		if len(id) > len(suffix) && strings.EqualFold(id[len(id)-len(suffix):], suffix) {
+			return id[:len(id)-len(suffix)]
+		}
+	}
+	return id
+}
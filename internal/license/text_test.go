@@ -0,0 +1,86 @@
+package license_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+// TestText tests looking up embedded license texts.
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id       string
+		wantOK   bool
+		contains string
+	}{
+		{id: "MIT", wantOK: true, contains: "Permission is hereby granted"},
+		{id: "apache-2.0", wantOK: true, contains: "Apache License"},
+		{id: "GPL-2.0-only", wantOK: true, contains: "GNU GENERAL PUBLIC LICENSE"},
+		{id: "GPL-2.0-or-later", wantOK: true, contains: "Version 2"},
+		{id: "LGPL-2.1+", wantOK: true, contains: "LESSER GENERAL PUBLIC LICENSE"},
+		{id: "0BSD", wantOK: true, contains: "Permission to use"},
+		{id: "LicenseRef-acme", wantOK: false},
+		{id: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		text, ok := license.Text(tt.id)
+		if ok != tt.wantOK {
+			t.Errorf("Text(%q) ok = %v, want %v", tt.id, ok, tt.wantOK)
+			continue
+		}
+		if !strings.Contains(text, tt.contains) {
+			t.Errorf("Text(%q) does not contain %q", tt.id, tt.contains)
+		}
+	}
+}
+
+// TestCanonicalID tests the spelling of license identifiers.
+func TestCanonicalID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "mit", want: "MIT"},
+		{id: "apache-2.0", want: "Apache-2.0"},
+		{id: "gpl-2.0-only", want: "GPL-2.0-only"},
+		{id: "LicenseRef-acme", want: "LicenseRef-acme"},
+	}
+
+	for _, tt := range tests {
+		if got := license.CanonicalID(tt.id); got != tt.want {
+			t.Errorf("CanonicalID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+// TestListed tests looking up identifiers of the SPDX License List.
+func TestListed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "MIT", want: true},
+		{id: "gpl-2.0-or-later", want: true},
+		{id: "LGPL-2.1+", want: true},
+		{id: "GPL-2.0", want: true},
+		{id: "Unicode-3.0", want: true},
+		{id: "BlueOak-1.0.0", want: true},
+		{id: "LicenseRef-acme", want: false},
+		{id: "NOASSERTION", want: false},
+		{id: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := license.Listed(tt.id); got != tt.want {
+			t.Errorf("Listed(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
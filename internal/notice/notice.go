@@ -0,0 +1,280 @@
+// Package notice generates third-party attribution notices from the packages of an SBOM.
+//
+// A notice groups the components by license expression, lists their copyright lines and includes the full
+// text of every license, taken from the texts embedded in the license package. It can be written as
+// Markdown, HTML, plain text or a directory with one file per license.
+package notice
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/license"
+)
+
+const (
+	// unknownLicense is the heading of the group of components without license information.
+	unknownLicense = "Unknown license"
+	// unknownFile is the name of the file listing the components without license information.
+	unknownFile = "UNKNOWN.txt"
+	// dirMode is the file mode for notice directories.
+	dirMode = 0o755
+	// fileMode is the file mode for notice files.
+	fileMode = 0o644
+)
+
+// Notice is the attribution notice of the third-party components of a project.
+type Notice struct {
+	// Title is the title of the notice, including the project name if known.
+	Title string
+	// Groups are the components grouped by license expression, sorted by license with the unknown
+	// license last.
+	Groups []Group
+	// Texts are the full texts of the licenses used by the components, sorted by license.
+	Texts []Text
+}
+
+// Group is a set of components with the same license expression.
+type Group struct {
+	// License is the license expression, or empty if the license is unknown.
+	License string
+	// Components are the components, sorted by name and version.
+	Components []Component
+}
+
+// Heading returns the heading of the group.
+func (g Group) Heading() string {
+	if g.License == "" {
+		return unknownLicense
+	}
+	return g.License
+}
+
+// Component is a third-party component.
+type Component struct {
+	// Name is the component name.
+	Name string
+	// Version is the component version.
+	Version string
+	// Homepage is the component homepage, if any.
+	Homepage string
+	// Copyright is the copyright text of the component, if any.
+	Copyright string
+}
+
+// Title returns the name and version of the component.
+func (c Component) Title() string {
+	if c.Version == "" {
+		return c.Name
+	}
+	return c.Name + " " + c.Version
+}
+
+// CopyrightLines returns the non-empty lines of the copyright text of the component.
+func (c Component) CopyrightLines() []string {
+	var lines []string
+	for _, line := range strings.Split(c.Copyright, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Text is the full text of a license.
+type Text struct {
+	// License is the SPDX license identifier.
+	License string
+	// Text is the full license text, or empty if it is not available, e.g. for LicenseRefs.
+	Text string
+}
+
+// Build builds the notice for the packages of an SBOM. The root package is the project itself and is not
+// listed.
+func Build(inventory *enricher.Inventory) *Notice {
+	n := &Notice{Title: "Third-party licenses"}
+	if inventory.Root != nil && inventory.Root.Name != "" {
+		n.Title += " of " + (Component{Name: inventory.Root.Name, Version: inventory.Root.Version}).Title()
+	}
+
+	groups := map[string]*Group{}
+	licenses := map[string]bool{}
+	for _, item := range inventory.Items {
+		expression, leaves := normalize(item.License)
+		group, ok := groups[expression]
+		if !ok {
+			group = &Group{License: expression}
+			groups[expression] = group
+		}
+		group.Components = append(group.Components, Component{
+			Name:      item.Name,
+			Version:   item.Version,
+			Homepage:  item.Homepage,
+			Copyright: item.Copyright,
+		})
+		for _, leaf := range leaves {
+			licenses[leaf] = true
+		}
+	}
+
+	for _, group := range groups {
+		sort.SliceStable(group.Components, func(i, j int) bool {
+			if group.Components[i].Name != group.Components[j].Name {
+				return group.Components[i].Name < group.Components[j].Name
+			}
+			return group.Components[i].Version < group.Components[j].Version
+		})
+		n.Groups = append(n.Groups, *group)
+	}
+	sort.Slice(n.Groups, func(i, j int) bool {
+		if (n.Groups[i].License == "") != (n.Groups[j].License == "") {
+			return n.Groups[j].License == ""
+		}
+		return n.Groups[i].License < n.Groups[j].License
+	})
+
+	for id := range licenses {
+		text, _ := license.Text(id)
+		n.Texts = append(n.Texts, Text{License: id, Text: strings.TrimSpace(text)})
+	}
+	sort.Slice(n.Texts, func(i, j int) bool {
+		return n.Texts[i].License < n.Texts[j].License
+	})
+	return n
+}
+
+// normalize returns the normalized license expression and its distinct license identifiers in their canonical
+// spelling, or empty values if the license is unknown. Licenses that are not valid SPDX expressions are used as they are.
+func normalize(licenseExpression string) (string, []string) {
+	if licenseExpression == "" || licenseExpression == "NONE" || licenseExpression == "NOASSERTION" {
+		return "", nil
+	}
+	expr, err := license.Parse(licenseExpression)
+	if err != nil {
+		return licenseExpression, nil
+	}
+	expr = expr.Normalize()
+
+	var ids []string
+	seen := map[string]bool{}
+	for _, leaf := range expr.Leaves() {
+		leaf.License = license.CanonicalID(leaf.License)
+		if !seen[leaf.License] {
+			seen[leaf.License] = true
+			ids = append(ids, leaf.License)
+		}
+	}
+	return expr.String(), ids
+}
+
+// WriteDir writes the notice to a directory, e.g. THIRD_PARTY_LICENSES. The directory gets one file per
+// license, named after the license identifier, listing the components under that license followed by the
+// license text. Components without license information are listed in UNKNOWN.txt.
+func (n *Notice) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return fmt.Errorf("create notice directory: %w", err)
+	}
+
+	for _, text := range n.Texts {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\n\n", text.License)
+		fmt.Fprintf(&b, "The following components are licensed under %s:\n\n", text.License)
+		for _, group := range n.Groups {
+			if !group.uses(text.License) {
+				continue
+			}
+			writeComponents(&b, group)
+		}
+		b.WriteString("\n")
+		if text.Text == "" {
+			b.WriteString("The full license text is not available. See the homepage of the components.\n")
+		} else {
+			b.WriteString(text.Text + "\n")
+		}
+		if err := writeFile(dir, fileName(text.License), b.String()); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range n.Groups {
+		if len(group.Components) == 0 || len(n.textsOf(group)) > 0 {
+			continue
+		}
+		var b strings.Builder
+		if group.License == "" {
+			b.WriteString("The following components have no license information:\n\n")
+		} else {
+			fmt.Fprintf(&b, "The following components are licensed under %s:\n\n", group.License)
+		}
+		writeComponents(&b, group)
+		name := unknownFile
+		if group.License != "" {
+			name = fileName(group.License)
+		}
+		if err := writeFile(dir, name, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uses reports whether the license expression of the group contains the license identifier.
+func (g Group) uses(id string) bool {
+	_, leaves := normalize(g.License)
+	for _, leaf := range leaves {
+		if leaf == id {
+			return true
+		}
+	}
+	return false
+}
+
+// textsOf returns the license texts used by a group.
+func (n *Notice) textsOf(group Group) []Text {
+	var texts []Text
+	for _, text := range n.Texts {
+		if group.uses(text.License) {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// writeComponents writes the components of a group as a list, with their copyright lines.
+func writeComponents(b *strings.Builder, group Group) {
+	for _, component := range group.Components {
+		fmt.Fprintf(b, "  - %s", component.Title())
+		if component.Homepage != "" {
+			fmt.Fprintf(b, " (%s)", component.Homepage)
+		}
+		b.WriteString("\n")
+		for _, line := range component.CopyrightLines() {
+			fmt.Fprintf(b, "    %s\n", line)
+		}
+	}
+}
+
+// fileName returns a safe file name for a license identifier or expression.
+func fileName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '+':
+			return r
+		default:
+			return '_'
+		}
+	}, id)
+	return name + ".txt"
+}
+
+// writeFile writes content to a file in dir.
+func writeFile(dir, name, content string) error {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), fileMode); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package notice_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/notice"
+)
+
+// testInventory returns the inventory of a project with components under various licenses.
+func testInventory() *enricher.Inventory {
+	return &enricher.Inventory{
+		Root: &enricher.InventoryItem{ID: "app", Name: "app", Version: "1.0.0", License: "Apache-2.0"},
+		Items: []enricher.InventoryItem{
+			{ID: "b", Name: "b", Version: "2.0.0", License: "MIT", Copyright: "Copyright (c) B\nCopyright (c) C"},
+			{ID: "a", Name: "a", Version: "1.0.0", License: "mit", Homepage: "https://a.example"},
+			{ID: "c", Name: "c", Version: "3.0.0", License: "GPL-2.0+ OR MIT"},
+			{ID: "d", Name: "d", License: "NOASSERTION"},
+			{ID: "e", Name: "e", Version: "1.0.0", License: "LicenseRef-acme"},
+		},
+	}
+}
+
+// TestBuild tests grouping components by license.
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	n := notice.Build(testInventory())
+
+	if n.Title != "Third-party licenses of app 1.0.0" {
+		t.Errorf("Build() Title = %q", n.Title)
+	}
+
+	var headings []string
+	for _, group := range n.Groups {
+		headings = append(headings, group.Heading())
+	}
+	wantHeadings := []string{"GPL-2.0-or-later OR MIT", "LicenseRef-acme", "MIT", "Unknown license"}
+	if strings.Join(headings, "|") != strings.Join(wantHeadings, "|") {
+		t.Errorf("Build() groups = %v, want %v", headings, wantHeadings)
+	}
+	if mit := n.Groups[2]; len(mit.Components) != 2 || mit.Components[0].Name != "a" {
+		t.Errorf("Build() MIT components = %+v, want a and b sorted by name", mit.Components)
+	}
+
+	var licenses []string
+	for _, text := range n.Texts {
+		licenses = append(licenses, text.License)
+		if (text.Text == "") != (text.License == "LicenseRef-acme") {
+			t.Errorf("Build() text of %s has length %d", text.License, len(text.Text))
+		}
+	}
+	wantLicenses := []string{"GPL-2.0-or-later", "LicenseRef-acme", "MIT"}
+	if strings.Join(licenses, "|") != strings.Join(wantLicenses, "|") {
+		t.Errorf("Build() texts = %v, want %v", licenses, wantLicenses)
+	}
+}
+
+// TestNotice_Write tests the Markdown, HTML and text output.
+func TestNotice_Write(t *testing.T) {
+	t.Parallel()
+
+	n := notice.Build(testInventory())
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer) error
+		want  []string
+	}{
+		{
+			name:  "markdown",
+			write: func(b *bytes.Buffer) error { return n.WriteMarkdown(b) },
+			want: []string{
+				"# Third-party licenses of app 1.0.0\n",
+				"## MIT\n\n- a 1.0.0 (https://a.example)\n- b 2.0.0\n  - Copyright (c) B\n  - Copyright (c) C\n",
+				"## Unknown license\n\n- d\n",
+				"### MIT\n\n````text\nPermission is hereby granted",
+				"### LicenseRef-acme\n\nThe full license text is not available.",
+			},
+		},
+		{
+			name:  "html",
+			write: func(b *bytes.Buffer) error { return n.WriteHTML(b) },
+			want: []string{
+				"<title>Third-party licenses of app 1.0.0</title>",
+				`<li><a href="https://a.example">a 1.0.0</a></li>`,
+				`<span class="copyright">Copyright (c) B</span>`,
+				`<h3 id="MIT">MIT</h3>`,
+				"<pre>Permission is hereby granted",
+			},
+		},
+		{
+			name:  "text",
+			write: func(b *bytes.Buffer) error { return n.WriteText(b) },
+			want: []string{
+				"Third-party licenses of app 1.0.0\n=================================\n",
+				"MIT\n---\n\n  - a 1.0.0 (https://a.example)\n  - b 2.0.0\n    Copyright (c) B\n",
+				"\nMIT\n" + strings.Repeat("=", 80) + "\n\nPermission is hereby granted",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var b bytes.Buffer
+			if err := tt.write(&b); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
+
+// TestNotice_WriteDir tests writing one file per license.
+func TestNotice_WriteDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "THIRD_PARTY_LICENSES")
+	if err := notice.Build(testInventory()).WriteDir(dir); err != nil {
+		t.Fatalf("WriteDir() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	wantNames := []string{"GPL-2.0-or-later.txt", "LicenseRef-acme.txt", "MIT.txt", "UNKNOWN.txt"}
+	if strings.Join(names, "|") != strings.Join(wantNames, "|") {
+		t.Errorf("WriteDir() files = %v, want %v", names, wantNames)
+	}
+
+	mit, err := os.ReadFile(filepath.Join(dir, "MIT.txt"))
+	if err != nil {
+		t.Fatalf("failed to read MIT.txt: %v", err)
+	}
+	for _, want := range []string{"  - a 1.0.0", "  - b 2.0.0", "  - c 3.0.0", "Permission is hereby granted"} {
+		if !strings.Contains(string(mit), want) {
+			t.Errorf("MIT.txt does not contain %q:\n%s", want, mit)
+		}
+	}
+}
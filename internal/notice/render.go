@@ -0,0 +1,105 @@
+package notice
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// markdownTemplate renders a notice as Markdown.
+const markdownTemplate = `# {{.Title}}
+{{range .Groups}}
+## {{.Heading}}
+{{range .Components}}
+- {{.Title}}{{if .Homepage}} ({{.Homepage}}){{end}}{{range .CopyrightLines}}
+  - {{.}}{{end}}{{end}}
+{{end}}{{if .Texts}}
+## License texts
+{{range .Texts}}
+### {{.License}}
+{{if .Text}}
+` + "````text" + `
+{{.Text}}
+` + "````" + `
+{{else}}
+The full license text is not available. See the homepage of the components.
+{{end}}{{end}}{{end}}`
+
+// textTemplate renders a notice as plain text.
+const textTemplate = `{{.Title}}
+{{rule .Title "="}}
+{{range .Groups}}
+{{.Heading}}
+{{rule .Heading "-"}}
+{{range .Components}}
+  - {{.Title}}{{if .Homepage}} ({{.Homepage}}){{end}}{{range .CopyrightLines}}
+    {{.}}{{end}}{{end}}
+{{end}}{{range .Texts}}
+{{rule "" "="}}
+{{.License}}
+{{rule "" "="}}
+
+{{if .Text}}{{.Text}}{{else}}The full license text is not available. See the homepage of the components.{{end}}
+{{end}}`
+
+// htmlTemplate renders a notice as a standalone HTML page.
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; }
+pre { white-space: pre-wrap; background: #f6f8fa; padding: 1rem; }
+.copyright { color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Groups}}
+<h2>{{.Heading}}</h2>
+<ul>
+{{- range .Components}}
+<li>{{if .Homepage}}<a href="{{.Homepage}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}
+{{- range .CopyrightLines}}<br><span class="copyright">{{.}}</span>{{end}}</li>
+{{- end}}
+</ul>
+{{end}}
+{{- if .Texts}}
+<h2>License texts</h2>
+{{range .Texts}}
+<h3 id="{{.License}}">{{.License}}</h3>
+{{if .Text}}<pre>{{.Text}}</pre>{{else}}<p>The full license text is not available. See the homepage of the components.</p>{{end}}
+{{end}}
+{{- end}}
+</body>
+</html>
+`
+
+// ruleWidth is the width of the horizontal rules in plain text notices.
+const ruleWidth = 80
+
+// WriteMarkdown writes the notice as Markdown.
+func (n *Notice) WriteMarkdown(w io.Writer) error {
+	return template.Must(template.New("markdown").Parse(markdownTemplate)).Execute(w, n)
+}
+
+// WriteText writes the notice as plain text.
+func (n *Notice) WriteText(w io.Writer) error {
+	funcs := template.FuncMap{
+		// rule returns a horizontal rule as wide as the title, or ruleWidth if the title is empty.
+		"rule": func(title, char string) string {
+			if title == "" {
+				return strings.Repeat(char, ruleWidth)
+			}
+			return strings.Repeat(char, len([]rune(title)))
+		},
+	}
+	return template.Must(template.New("text").Funcs(funcs).Parse(textTemplate)).Execute(w, n)
+}
+
+// WriteHTML writes the notice as a standalone HTML page.
+func (n *Notice) WriteHTML(w io.Writer) error {
+	return htmltemplate.Must(htmltemplate.New("html").Parse(htmlTemplate)).Execute(w, n)
+}
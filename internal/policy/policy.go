@@ -0,0 +1,296 @@
+// Package policy checks the licenses of SBOM packages against a license policy.
+//
+// A policy lists allowed, denied and review-required licenses. License expressions are evaluated with SPDX
+// semantics: "MIT OR GPL-3.0-only" is allowed if MIT is allowed, while "MIT AND GPL-3.0-only" is denied if
+// GPL-3.0-only is denied.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// Decision is the outcome of checking a license against a policy.
+type Decision string
+
+const (
+	// DecisionAllow means the license may be used.
+	DecisionAllow Decision = "allow"
+	// DecisionReview means the license must be reviewed before it is used.
+	DecisionReview Decision = "review"
+	// DecisionDeny means the license must not be used.
+	DecisionDeny Decision = "deny"
+)
+
+// severity orders decisions from allow to deny.
+func (d Decision) severity() int {
+	switch d {
+	case DecisionAllow:
+		return 0
+	case DecisionReview:
+		return 1
+	default:
+		return 2 //nolint:mnd // deny is the most severe decision
+	}
+}
+
+// Policy is a license policy.
+//
+// License lists contain SPDX license identifiers, which may use "*" as a wildcard (e.g. "GPL-*"), or
+// "<license> WITH <exception>" pairs. Identifiers are matched case-insensitively, and deprecated identifiers
+// such as "GPL-2.0" are normalized to their current form. A license on several lists gets the most severe
+// decision.
+type Policy struct {
+	// Allow lists the licenses that may be used.
+	Allow []string `yaml:"allow"`
+	// Deny lists the licenses that must not be used.
+	Deny []string `yaml:"deny"`
+	// Review lists the licenses that must be reviewed before they are used.
+	Review []string `yaml:"review"`
+	// Default is the decision for licenses that are on no list. Defaults to review.
+	Default Decision `yaml:"default"`
+	// Missing is the decision for packages without license information. Defaults to review.
+	Missing Decision `yaml:"missing"`
+	// FailOnReview makes packages that require review fail the check, not only denied ones.
+	FailOnReview bool `yaml:"failOnReview"`
+	// Exceptions allow licenses for specific packages.
+	Exceptions []Exception `yaml:"exceptions"`
+}
+
+// Exception allows licenses for the packages matching a purl pattern.
+type Exception struct {
+	// Purl is a purl glob, e.g. "pkg:npm/@acme/*". Percent-encoding may be omitted.
+	Purl string `yaml:"purl"`
+	// Licenses are the licenses allowed for the packages. If empty, any license is allowed.
+	Licenses []string `yaml:"licenses"`
+	// Reason documents why the exception was granted.
+	Reason string `yaml:"reason"`
+}
+
+// Finding is a package whose license is not allowed.
+type Finding struct {
+	// ID is the SPDX ID or BOM reference of the package.
+	ID string `json:"id"`
+	// Name is the package name.
+	Name string `json:"name"`
+	// Version is the package version.
+	Version string `json:"version,omitempty"`
+	// Purl is the package URL, if any.
+	Purl string `json:"purl,omitempty"`
+	// License is the license expression of the package.
+	License string `json:"license,omitempty"`
+	// Decision is the outcome of the check.
+	Decision Decision `json:"decision"`
+	// Reason explains the decision.
+	Reason string `json:"reason"`
+}
+
+// Result is the outcome of checking the packages of an SBOM against a policy.
+type Result struct {
+	// Passed is false if a package is denied, or requires review and the policy fails on review.
+	Passed bool `json:"passed"`
+	// Allowed is the number of allowed packages.
+	Allowed int `json:"allowed"`
+	// Review is the number of packages that require review.
+	Review int `json:"review"`
+	// Denied is the number of denied packages.
+	Denied int `json:"denied"`
+	// Findings are the packages that are not allowed, in document order.
+	Findings []Finding `json:"findings"`
+}
+
+// Load reads a policy from a YAML or JSON file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a policy from YAML or JSON and validates it.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the decisions and exceptions of the policy.
+func (p *Policy) Validate() error {
+	for field, d := range map[string]Decision{"default": p.Default, "missing": p.Missing} {
+		switch d {
+		case "", DecisionAllow, DecisionReview, DecisionDeny:
+		default:
+			return fmt.Errorf("invalid %s decision %q: must be allow, review or deny", field, d)
+		}
+	}
+	for i, exception := range p.Exceptions {
+		if !strings.HasPrefix(exception.Purl, "pkg:") {
+			return fmt.Errorf("invalid exception %d: purl %q must start with \"pkg:\"", i, exception.Purl)
+		}
+	}
+	return nil
+}
+
+// Evaluate checks the packages against the policy.
+func (p *Policy) Evaluate(items []enricher.InventoryItem) *Result {
+	result := &Result{Findings: []Finding{}}
+	for _, item := range items {
+		decision, reason := p.evaluateItem(item)
+		switch decision {
+		case DecisionAllow:
+			result.Allowed++
+			continue
+		case DecisionReview:
+			result.Review++
+		case DecisionDeny:
+			result.Denied++
+		}
+		result.Findings = append(result.Findings, Finding{
+			ID:       item.ID,
+			Name:     item.Name,
+			Version:  item.Version,
+			Purl:     item.Purl,
+			License:  item.License,
+			Decision: decision,
+			Reason:   reason,
+		})
+	}
+	result.Passed = result.Denied == 0 && (!p.FailOnReview || result.Review == 0)
+	return result
+}
+
+// evaluateItem decides on the license of a single package.
+func (p *Policy) evaluateItem(item enricher.InventoryItem) (Decision, string) {
+	var excepted []string
+	for _, exception := range p.Exceptions {
+		if !purl.Match(exception.Purl, item.Purl) {
+			continue
+		}
+		if len(exception.Licenses) == 0 {
+			return DecisionAllow, ""
+		}
+		excepted = append(excepted, exception.Licenses...)
+	}
+
+	if item.License == "" || item.License == "NONE" || item.License == "NOASSERTION" {
+		return orDefault(p.Missing), "no license information"
+	}
+
+	expr, err := license.Parse(item.License)
+	if err != nil {
+		// Not an SPDX expression, e.g. a license name: match it as a whole
+		return p.evaluateLicense(item.License, "", excepted)
+	}
+	return p.evaluateExpression(expr.Normalize(), excepted)
+}
+
+// evaluateExpression decides on an expression. AND takes the most severe decision of its operands,
+// OR the least severe one.
+func (p *Policy) evaluateExpression(expr *license.Expression, excepted []string) (Decision, string) {
+	if expr.IsLeaf() {
+		return p.evaluateLicense(expr.License, expr.Exception, excepted)
+	}
+
+	decision, reasons := Decision(""), []string(nil)
+	for _, operand := range expr.Operands {
+		d, reason := p.evaluateExpression(operand, excepted)
+		switch {
+		case decision == "",
+			expr.Operator == license.OperatorAnd && d.severity() > decision.severity(),
+			expr.Operator == license.OperatorOr && d.severity() < decision.severity():
+			decision, reasons = d, []string{reason}
+		case d == decision:
+			reasons = append(reasons, reason)
+		}
+	}
+	if decision == DecisionAllow {
+		return decision, ""
+	}
+	return decision, strings.Join(reasons, "; ")
+}
+
+// evaluateLicense decides on a single license with an optional exception.
+//
+// Entries naming the exception are more specific than entries for the bare license, so they are consulted
+// first: "GPL-2.0-only WITH Classpath-exception-2.0" may be allowed while "GPL-2.0-only" is denied.
+func (p *Policy) evaluateLicense(id, exception string, excepted []string) (Decision, string) {
+	name := id
+	if exception != "" {
+		name = id + " WITH " + exception
+		if decision, ok := p.lookup(id, exception, excepted); ok {
+			return decision, decisionReason(decision, name)
+		}
+	}
+	if decision, ok := p.lookup(id, "", excepted); ok {
+		return decision, decisionReason(decision, name)
+	}
+	return orDefault(p.Default), name + " is not covered by the policy"
+}
+
+// lookup returns the decision of the most severe list the license is on, after the package exceptions.
+func (p *Policy) lookup(id, exception string, excepted []string) (Decision, bool) {
+	switch {
+	case matchLicense(excepted, id, exception):
+		return DecisionAllow, true
+	case matchLicense(p.Deny, id, exception):
+		return DecisionDeny, true
+	case matchLicense(p.Review, id, exception):
+		return DecisionReview, true
+	case matchLicense(p.Allow, id, exception):
+		return DecisionAllow, true
+	default:
+		return "", false
+	}
+}
+
+// decisionReason explains a decision taken from the policy lists.
+func decisionReason(decision Decision, name string) string {
+	switch decision {
+	case DecisionDeny:
+		return name + " is denied"
+	case DecisionReview:
+		return name + " requires review"
+	default:
+		return ""
+	}
+}
+
+// matchLicense reports whether a list has an entry for the license with exactly the given exception,
+// or for the bare license if exception is empty.
+func matchLicense(list []string, id, exception string) bool {
+	for _, entry := range list {
+		entryID, entryException, _ := strings.Cut(entry, " WITH ")
+		entryID = license.NormalizeID(strings.TrimSpace(entryID))
+		if purl.MatchGlob(strings.ToLower(entryID), strings.ToLower(id)) &&
+			strings.EqualFold(strings.TrimSpace(entryException), exception) {
+			return true
+		}
+	}
+	return false
+}
+
+// orDefault returns the decision, or review if it is unset.
+func orDefault(d Decision) Decision {
+	if d == "" {
+		return DecisionReview
+	}
+	return d
+}
@@ -0,0 +1,197 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/policy"
+)
+
+// testPolicy is a policy in YAML form used by the tests.
+const testPolicy = `
+allow: [MIT, Apache-2.0, BSD-*, "GPL-2.0-only WITH Classpath-exception-2.0"]
+deny: [GPL-3.0-only, AGPL-*, GPL-2.0]
+review: [LGPL-2.1-only, MPL-2.0]
+exceptions:
+  - purl: pkg:npm/@acme/*
+    reason: first-party code
+  - purl: pkg:npm/readline-sync@*
+    licenses: [GPL-3.0-only]
+    reason: dev tool, approved by legal
+`
+
+// TestParse_Invalid tests that invalid policies are rejected.
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "invalid YAML", input: "allow: [MIT"},
+		{name: "unknown field", input: "allowed: [MIT]"},
+		{name: "invalid decision", input: "default: block"},
+		{name: "invalid exception", input: "exceptions: [{purl: npm/foo}]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := policy.Parse([]byte(tt.input)); err == nil {
+				t.Error("Parse() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestParse_JSON tests that policies can be written in JSON.
+func TestParse_JSON(t *testing.T) {
+	t.Parallel()
+
+	p, err := policy.Parse([]byte(`{"allow": ["MIT"], "deny": ["GPL-3.0-only"], "failOnReview": true}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(p.Allow) != 1 || len(p.Deny) != 1 || !p.FailOnReview {
+		t.Errorf("Parse() = %+v", p)
+	}
+}
+
+// TestPolicy_Evaluate tests the decision for each kind of license expression.
+func TestPolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	p, err := policy.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		item    enricher.InventoryItem
+		want    policy.Decision
+		wantWhy string
+	}{
+		{name: "allowed", item: enricher.InventoryItem{License: "MIT"}, want: policy.DecisionAllow},
+		{name: "glob", item: enricher.InventoryItem{License: "BSD-3-Clause"}, want: policy.DecisionAllow},
+		{name: "case-insensitive", item: enricher.InventoryItem{License: "mit"}, want: policy.DecisionAllow},
+		{
+			name:    "denied",
+			item:    enricher.InventoryItem{License: "GPL-3.0-only"},
+			want:    policy.DecisionDeny,
+			wantWhy: "GPL-3.0-only is denied",
+		},
+		{
+			name:    "deprecated identifier is normalized",
+			item:    enricher.InventoryItem{License: "GPL-3.0"},
+			want:    policy.DecisionDeny,
+			wantWhy: "GPL-3.0-only is denied",
+		},
+		{
+			name: "or with an allowed choice",
+			item: enricher.InventoryItem{License: "MIT OR GPL-3.0-only"},
+			want: policy.DecisionAllow,
+		},
+		{
+			name:    "or picks the least severe choice",
+			item:    enricher.InventoryItem{License: "AGPL-3.0-only OR LGPL-2.1-only"},
+			want:    policy.DecisionReview,
+			wantWhy: "LGPL-2.1-only requires review",
+		},
+		{
+			name:    "and picks the most severe operand",
+			item:    enricher.InventoryItem{License: "MIT AND (GPL-3.0-only OR AGPL-3.0-only)"},
+			want:    policy.DecisionDeny,
+			wantWhy: "GPL-3.0-only is denied; AGPL-3.0-only is denied",
+		},
+		{
+			name: "allowed exception",
+			item: enricher.InventoryItem{License: "GPL-2.0-only WITH Classpath-exception-2.0"},
+			want: policy.DecisionAllow,
+		},
+		{
+			name:    "other exception",
+			item:    enricher.InventoryItem{License: "GPL-2.0-only WITH GCC-exception-2.0"},
+			want:    policy.DecisionDeny,
+			wantWhy: "GPL-2.0-only WITH GCC-exception-2.0 is denied",
+		},
+		{
+			name:    "not covered",
+			item:    enricher.InventoryItem{License: "Unlicense"},
+			want:    policy.DecisionReview,
+			wantWhy: "Unlicense is not covered by the policy",
+		},
+		{
+			name:    "not an expression",
+			item:    enricher.InventoryItem{License: "Apache License 2.0"},
+			want:    policy.DecisionReview,
+			wantWhy: "Apache License 2.0 is not covered by the policy",
+		},
+		{
+			name:    "missing",
+			item:    enricher.InventoryItem{License: "NOASSERTION"},
+			want:    policy.DecisionReview,
+			wantWhy: "no license information",
+		},
+		{
+			name: "package exception for any license",
+			item: enricher.InventoryItem{Purl: "pkg:npm/%40acme/ui@1.0.0", License: "AGPL-3.0-only"},
+			want: policy.DecisionAllow,
+		},
+		{
+			name: "package exception for a license",
+			item: enricher.InventoryItem{Purl: "pkg:npm/readline-sync@1.4.10", License: "GPL-3.0-only"},
+			want: policy.DecisionAllow,
+		},
+		{
+			name:    "package exception for another license",
+			item:    enricher.InventoryItem{Purl: "pkg:npm/readline-sync@1.4.10", License: "AGPL-3.0-only"},
+			want:    policy.DecisionDeny,
+			wantWhy: "AGPL-3.0-only is denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := p.Evaluate([]enricher.InventoryItem{tt.item})
+			if tt.want == policy.DecisionAllow {
+				if result.Allowed != 1 || len(result.Findings) != 0 {
+					t.Errorf("Evaluate() = %+v, want allowed", result)
+				}
+				return
+			}
+			if len(result.Findings) != 1 {
+				t.Fatalf("Evaluate() findings = %+v, want 1", result.Findings)
+			}
+			finding := result.Findings[0]
+			if finding.Decision != tt.want || finding.Reason != tt.wantWhy {
+				t.Errorf("Evaluate() = %s (%s), want %s (%s)", finding.Decision, finding.Reason, tt.want, tt.wantWhy)
+			}
+		})
+	}
+}
+
+// TestPolicy_Evaluate_Passed tests when a result passes.
+func TestPolicy_Evaluate_Passed(t *testing.T) {
+	t.Parallel()
+
+	items := []enricher.InventoryItem{{License: "MIT"}, {License: "MPL-2.0"}}
+
+	p := &policy.Policy{Allow: []string{"MIT"}, Review: []string{"MPL-2.0"}}
+	if result := p.Evaluate(items); !result.Passed || result.Review != 1 || result.Allowed != 1 {
+		t.Errorf("Evaluate() = %+v, want passed with one review", result)
+	}
+
+	p.FailOnReview = true
+	if result := p.Evaluate(items); result.Passed {
+		t.Errorf("Evaluate() with FailOnReview = %+v, want failed", result)
+	}
+
+	p = &policy.Policy{Allow: []string{"MIT"}, Default: policy.DecisionDeny}
+	if result := p.Evaluate(items); result.Passed || result.Denied != 1 {
+		t.Errorf("Evaluate() with default deny = %+v, want failed", result)
+	}
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBreakerThreshold is the default number of consecutive failures that open a circuit.
+	defaultBreakerThreshold = 5
+	// defaultBreakerCooldown is the default time a circuit stays open before a lookup probes the provider.
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned when a provider is not asked because its circuit breaker is open.
+var ErrCircuitOpen = errors.New("provider circuit open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState string
+
+const (
+	// BreakerClosed means lookups reach the provider.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen means lookups fail with ErrCircuitOpen without reaching the provider.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen means one lookup probes the provider, and the others fail with ErrCircuitOpen.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerOptions are the options for the Breaker.
+type BreakerOptions struct {
+	// Name identifies the provider in logs and on the health endpoint, e.g. "ecosystems".
+	Name string
+	// Threshold is the number of consecutive failures that open the circuit.
+	// If zero, defaults to 5. If negative, the circuit never opens.
+	Threshold int
+	// Cooldown is the time the circuit stays open before a lookup probes the provider.
+	// If zero, defaults to 30 seconds.
+	Cooldown time.Duration
+	// Logger logs state changes at warning level.
+	// If nil, nothing is logged.
+	Logger *slog.Logger
+}
+
+// BreakerStatus is the state of a Breaker, as shown on the health endpoint.
+type BreakerStatus struct {
+	// Name is the name of the provider.
+	Name string `json:"name"`
+	// State is the state of the circuit.
+	State BreakerState `json:"state"`
+	// Failures is the number of consecutive failures.
+	Failures int `json:"failures"`
+}
+
+// Breaker is a Provider that stops asking the provider it wraps after consecutive failures, so that lookups
+// fail fast instead of waiting for the timeout of an unavailable API on every package.
+//
+// After Threshold consecutive failures the circuit opens, and lookups fail with ErrCircuitOpen; cached and
+// curated licenses are still served by Lookup. After Cooldown, one lookup probes the provider: if it succeeds
+// the circuit closes, otherwise it opens again. Packages without a license are not failures.
+//
+// It is safe for concurrent use.
+type Breaker struct {
+	provider  Provider
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+var _ BatchProvider = (*Breaker)(nil)
+
+// NewBreaker creates a new Breaker around provider, with a closed circuit.
+func NewBreaker(provider Provider, opts BreakerOptions) *Breaker {
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = defaultBreakerThreshold
+	}
+	cooldown := opts.Cooldown
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &Breaker{
+		provider:  provider,
+		name:      opts.Name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    opts.Logger,
+		state:     BreakerClosed,
+	}
+}
+
+// Get gets the license from the wrapped provider, unless the circuit is open.
+func (b *Breaker) Get(ctx context.Context, purl string) (string, error) {
+	if err := b.allow(time.Now()); err != nil {
+		return "", err
+	}
+	license, err := b.provider.Get(ctx, purl)
+	b.record(ctx, err)
+	return license, err
+}
+
+// GetMany gets the licenses from the wrapped provider in one batch, unless the circuit is open. It fails with
+// errors.ErrUnsupported if the wrapped provider is not a BatchProvider.
+func (b *Breaker) GetMany(ctx context.Context, purls []string) (map[string]BatchResult, error) {
+	batch, ok := b.provider.(BatchProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider does not support batch lookups", errors.ErrUnsupported)
+	}
+	if err := b.allow(time.Now()); err != nil {
+		return nil, err
+	}
+
+	results, err := batch.GetMany(ctx, purls)
+	failure := err
+	if failure == nil {
+		failure = batchFailure(results)
+	}
+	b.record(ctx, failure)
+	return results, err
+}
+
+// Status returns the state of the circuit.
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == BreakerOpen && !time.Now().Before(b.openedAt.Add(b.cooldown)) {
+		// The next lookup will probe the provider
+		state = BreakerHalfOpen
+	}
+	return BreakerStatus{Name: b.name, State: state, Failures: b.failures}
+}
+
+// allow returns ErrCircuitOpen if the lookup must not reach the provider. An open circuit turns half-open for
+// the first lookup after the cooldown.
+func (b *Breaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if retryAt := b.openedAt.Add(b.cooldown); now.Before(retryAt) {
+			return fmt.Errorf("%w: %s unavailable until %s", ErrCircuitOpen, b.name, retryAt.Format(time.RFC3339))
+		}
+		b.setState(BreakerHalfOpen)
+		return nil
+	case BreakerHalfOpen:
+		return fmt.Errorf("%w: %s is being probed", ErrCircuitOpen, b.name)
+	default:
+		return nil
+	}
+}
+
+// record updates the circuit with the outcome of a lookup.
+func (b *Breaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up, which says nothing about the provider, so let the next lookup probe again
+		if b.state == BreakerHalfOpen {
+			b.state = BreakerOpen
+		}
+		return
+	}
+	if !isFailure(err) {
+		b.failures = 0
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.openedAt = time.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// setState changes the state of the circuit and logs the change.
+func (b *Breaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if b.logger != nil {
+		b.logger.Warn("provider circuit breaker changed state",
+			"provider", b.name, "state", state, "failures", b.failures)
+	}
+}
+
+// isFailure reports whether the error of a lookup means the provider is failing. Packages without a license,
+// blocked purls and lookups refused by the rate limiter are not failures.
+func isFailure(err error) bool {
+	return err != nil && !errors.Is(err, ErrLicenseNotFound) && !errors.Is(err, ErrBlocked) &&
+		!errors.Is(err, errors.ErrUnsupported) && !errors.Is(err, errLimiterDeadline)
+}
+
+// batchFailure returns the error of the first package of a batch if no package was answered, e.g. because
+// the provider looked them up one by one and all requests failed.
+func batchFailure(results map[string]BatchResult) error {
+	var first error
+	for _, result := range results {
+		if !isFailure(result.Err) {
+			return nil
+		}
+		if first == nil {
+			first = result.Err
+		}
+	}
+	return first
+}
+
+// Breakers returns the circuit breakers of a provider and the providers it wraps, in order.
+func Breakers(p Provider) []*Breaker {
+	switch p := p.(type) {
+	case *Breaker:
+		return append([]*Breaker{p}, Breakers(p.provider)...)
+	case *Guard:
+		return Breakers(p.provider)
+	case *Chain:
+		return linkBreakers(p.links)
+	case *Consensus:
+		return linkBreakers(p.links)
+	default:
+		return nil
+	}
+}
+
+// linkBreakers returns the circuit breakers of the providers of links, in order.
+func linkBreakers(links []Link) []*Breaker {
+	var breakers []*Breaker
+	for _, link := range links {
+		breakers = append(breakers, Breakers(link.Provider)...)
+	}
+	return breakers
+}
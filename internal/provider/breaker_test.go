@@ -0,0 +1,167 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestBreaker_Get tests that the circuit opens after consecutive failures and closes after a successful probe.
+func TestBreaker_Get(t *testing.T) {
+	t.Parallel()
+
+	mockProv := &mockProvider{err: provider.ErrUnavailable}
+	breaker := provider.NewBreaker(mockProv, provider.BreakerOptions{
+		Name:      "ecosystems",
+		Threshold: 3,
+		Cooldown:  20 * time.Millisecond,
+	})
+
+	for range 3 {
+		if _, err := breaker.Get(context.Background(), "pkg:npm/test@1.0.0"); !errors.Is(err, provider.ErrUnavailable) {
+			t.Fatalf("Get() error = %v, want %v", err, provider.ErrUnavailable)
+		}
+	}
+	want := provider.BreakerStatus{Name: "ecosystems", State: provider.BreakerOpen, Failures: 3}
+	if status := breaker.Status(); status != want {
+		t.Errorf("Status() = %+v, want %+v", status, want)
+	}
+
+	// Open: the provider is not asked
+	if _, err := breaker.Get(context.Background(), "pkg:npm/test@1.0.0"); !errors.Is(err, provider.ErrCircuitOpen) {
+		t.Errorf("Get() error = %v, want %v", err, provider.ErrCircuitOpen)
+	}
+	if mockProv.getCalls != 3 {
+		t.Errorf("provider called %d times, want 3", mockProv.getCalls)
+	}
+
+	// Half-open: a failed probe opens the circuit again
+	time.Sleep(30 * time.Millisecond)
+	if status := breaker.Status(); status.State != provider.BreakerHalfOpen {
+		t.Errorf("Status() = %+v, want %s", status, provider.BreakerHalfOpen)
+	}
+	if _, err := breaker.Get(context.Background(), "pkg:npm/test@1.0.0"); !errors.Is(err, provider.ErrUnavailable) {
+		t.Errorf("Get() probe error = %v, want %v", err, provider.ErrUnavailable)
+	}
+	if status := breaker.Status(); status.State != provider.BreakerOpen {
+		t.Errorf("Status() = %+v, want %s", status, provider.BreakerOpen)
+	}
+
+	// Half-open: a successful probe closes the circuit
+	time.Sleep(30 * time.Millisecond)
+	mockProv.err, mockProv.license = nil, "MIT"
+	if license, err := breaker.Get(context.Background(), "pkg:npm/test@1.0.0"); err != nil || license != "MIT" {
+		t.Errorf("Get() probe = %q, %v, want MIT", license, err)
+	}
+	want = provider.BreakerStatus{Name: "ecosystems", State: provider.BreakerClosed}
+	if status := breaker.Status(); status != want {
+		t.Errorf("Status() = %+v, want %+v", status, want)
+	}
+}
+
+// TestBreaker_Failures tests which errors count as failures of the provider.
+func TestBreaker_Failures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		err       error
+		threshold int
+		cancel    bool
+		wantState provider.BreakerState
+	}{
+		{name: "unavailable", err: provider.ErrUnavailable, wantState: provider.BreakerOpen},
+		{name: "rate limited", err: provider.ErrRateLimited, wantState: provider.BreakerOpen},
+		{name: "invalid response", err: provider.ErrInvalidResponse, wantState: provider.BreakerOpen},
+		{name: "not found", err: provider.ErrLicenseNotFound, wantState: provider.BreakerClosed},
+		{name: "blocked", err: provider.ErrBlocked, wantState: provider.BreakerClosed},
+		{name: "cancelled", err: context.Canceled, cancel: true, wantState: provider.BreakerClosed},
+		{name: "disabled", err: provider.ErrUnavailable, threshold: -1, wantState: provider.BreakerClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			threshold := tt.threshold
+			if threshold == 0 {
+				threshold = 2
+			}
+			breaker := provider.NewBreaker(&mockProvider{err: tt.err}, provider.BreakerOptions{Threshold: threshold})
+			for range 2 {
+				_, _ = breaker.Get(ctx, "pkg:npm/test@1.0.0")
+			}
+			if status := breaker.Status(); status.State != tt.wantState {
+				t.Errorf("Status() = %+v, want %s", status, tt.wantState)
+			}
+		})
+	}
+}
+
+// TestBreaker_GetMany tests that a batch in which every package failed counts as a failure.
+func TestBreaker_GetMany(t *testing.T) {
+	t.Parallel()
+
+	mockProv := &mockBatchProvider{}
+	breaker := provider.NewBreaker(mockProv, provider.BreakerOptions{Threshold: 1, Cooldown: time.Hour})
+
+	if _, err := breaker.GetMany(context.Background(), []string{"pkg:npm/test@1.0.0"}); err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if status := breaker.Status(); status.State != provider.BreakerClosed {
+		t.Errorf("Status() = %+v, want %s", status, provider.BreakerClosed)
+	}
+
+	mockProv.err = provider.ErrUnavailable
+	if _, err := breaker.GetMany(context.Background(), []string{"pkg:npm/test@1.0.0"}); err == nil {
+		t.Fatal("GetMany() error = nil, want failed batch")
+	}
+	_, err := breaker.GetMany(context.Background(), []string{"pkg:npm/test@1.0.0"})
+	if !errors.Is(err, provider.ErrCircuitOpen) {
+		t.Errorf("GetMany() error = %v, want %v", err, provider.ErrCircuitOpen)
+	}
+	if len(mockProv.batches) != 2 {
+		t.Errorf("provider batches = %d, want 2", len(mockProv.batches))
+	}
+}
+
+// TestBreakers tests that the circuit breakers of a provider chain are found.
+func TestBreakers(t *testing.T) {
+	t.Parallel()
+
+	config, err := provider.ParseChainConfig([]byte(`providers:
+  - provider: filesystem
+    root: .
+  - provider: consensus
+    providers:
+      - provider: ecosystems
+      - provider: clearlydefined
+        name: cd
+  - provider: depsdev
+`))
+	if err != nil {
+		t.Fatalf("ParseChainConfig() error = %v", err)
+	}
+	chain, err := config.Chain(provider.ChainOptions{})
+	if err != nil {
+		t.Fatalf("Chain() error = %v", err)
+	}
+
+	var names []string
+	for _, breaker := range provider.Breakers(chain) {
+		names = append(names, breaker.Status().Name)
+	}
+	if want := []string{"ecosystems", "cd", "depsdev"}; !slices.Equal(names, want) {
+		t.Errorf("Breakers() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// ErrorPolicy decides how a Chain handles errors of a provider other than ErrLicenseNotFound and ErrBlocked.
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyStop returns the error without asking the remaining providers. This is the default.
+	ErrorPolicyStop ErrorPolicy = "stop"
+	// ErrorPolicyContinue asks the remaining providers, and returns the error only if none of them finds the
+	// license.
+	ErrorPolicyContinue ErrorPolicy = "continue"
+)
+
+// Resolution is the license of a package and the name of the provider that found it.
+type Resolution struct {
+	// License is the license of the package.
+	License string
+	// Provider is the name of the provider that found the license.
+	Provider string
+	// Disagreement are the licenses found by each provider, if they disagree. See Consensus.
+	Disagreement []Answer
+}
+
+// Resolver is a Provider that reports which provider found a license, such as a Chain.
+type Resolver interface {
+	Provider
+	// Resolve returns the license for a package and the name of the provider that found it.
+	Resolve(ctx context.Context, purl string) (Resolution, error)
+}
+
+// Link is a provider of a Chain.
+type Link struct {
+	// Name identifies the provider in lookup results, e.g. "depsdev".
+	Name string
+	// Provider is the provider to ask.
+	Provider Provider
+	// Types are the purl types the provider is asked for, e.g. "golang".
+	//
+	// If empty, the provider is asked for all purl types.
+	Types []string
+	// OnError decides what happens when the provider fails.
+	//
+	// If empty, defaults to ErrorPolicyStop.
+	OnError ErrorPolicy
+}
+
+// Chain is a Provider that asks a list of providers in order and returns the first license found.
+//
+// Each provider is only asked for the purl types it is routed to. A provider that returns ErrLicenseNotFound or
+// ErrBlocked is skipped. Other errors stop the chain, unless the provider continues on error.
+type Chain struct {
+	links []Link
+}
+
+var (
+	_ Resolver      = (*Chain)(nil)
+	_ BatchProvider = (*Chain)(nil)
+)
+
+// NewChain creates a new Chain asking the providers of the links in order.
+func NewChain(links []Link) *Chain {
+	c := &Chain{links: make([]Link, len(links))}
+	for i, link := range links {
+		types := make([]string, len(link.Types))
+		for j, typ := range link.Types {
+			types[j] = strings.ToLower(typ)
+		}
+		link.Types = types
+		c.links[i] = link
+	}
+	return c
+}
+
+// Get gets the license from the first provider that finds it.
+func (c *Chain) Get(ctx context.Context, purl string) (string, error) {
+	resolution, err := c.Resolve(ctx, purl)
+	return resolution.License, err
+}
+
+// Resolve gets the license from the first provider that finds it, and the name of that provider.
+//
+// If no provider finds the license, the errors of all providers that were asked are returned joined, so
+// errors.Is reports ErrLicenseNotFound, ErrBlocked or the errors of providers that continue on error.
+func (c *Chain) Resolve(ctx context.Context, rawPurl string) (Resolution, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	var errs []error
+	for _, link := range c.links {
+		if len(link.Types) > 0 && !slices.Contains(link.Types, parsed.Type) {
+			continue
+		}
+
+		resolution, err := resolve(ctx, link.Provider, rawPurl)
+		if err == nil && resolution.License != "" {
+			return Resolution{License: resolution.License, Provider: link.Name, Disagreement: resolution.Disagreement}, nil
+		}
+		if err == nil {
+			err = ErrLicenseNotFound
+		}
+		err = fmt.Errorf("%s: %w", link.Name, err)
+
+		// Do not ask the remaining providers once the lookup is cancelled or timed out
+		if ctx.Err() != nil {
+			return Resolution{}, err
+		}
+		if !errors.Is(err, ErrLicenseNotFound) && !errors.Is(err, ErrBlocked) && link.OnError != ErrorPolicyContinue {
+			return Resolution{}, err
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return Resolution{}, fmt.Errorf("%w: no provider for purl type %q", ErrLicenseNotFound, parsed.Type)
+	}
+	return Resolution{}, errors.Join(errs...)
+}
+
+// GetMany gets the licenses of the purls from the first provider that finds them, as Resolve does. Providers that
+// support batch lookups are asked for all their purls at once, and the others one purl at a time. It fails with
+// errors.ErrUnsupported if no provider supports batch lookups.
+//
+// Purls are left out of the results if the batch of a provider fails or has no result for them, so that they can
+// be looked up with Resolve.
+func (c *Chain) GetMany(ctx context.Context, purls []string) (map[string]BatchResult, error) {
+	if !SupportsBatch(c) {
+		return nil, fmt.Errorf("%w: no provider of the chain supports batch lookups", errors.ErrUnsupported)
+	}
+
+	results := make(map[string]BatchResult, len(purls))
+	types := make(map[string]string, len(purls))
+	pending := make([]string, 0, len(purls))
+	for _, rawPurl := range purls {
+		parsed, err := purl.Parse(rawPurl)
+		if err != nil {
+			results[rawPurl] = BatchResult{Err: err}
+			continue
+		}
+		types[rawPurl] = parsed.Type
+		pending = append(pending, rawPurl)
+	}
+
+	errs := map[string][]error{}
+	for _, link := range c.links {
+		var routed []string
+		for _, rawPurl := range pending {
+			if len(link.Types) == 0 || slices.Contains(link.Types, types[rawPurl]) {
+				routed = append(routed, rawPurl)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+
+		answers := getMany(ctx, link.Provider, routed)
+		// Do not ask the remaining providers once the lookup is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		done := make(map[string]bool, len(routed))
+		for _, rawPurl := range routed {
+			answer, ok := answers[rawPurl]
+			if !ok {
+				// Left to Resolve
+				done[rawPurl] = true
+				continue
+			}
+			if answer.Err == nil && answer.License != "" {
+				results[rawPurl] = BatchResult{
+					License:      answer.License,
+					Provider:     link.Name,
+					Disagreement: answer.Disagreement,
+				}
+				done[rawPurl] = true
+				continue
+			}
+			err := answer.Err
+			if err == nil {
+				err = ErrLicenseNotFound
+			}
+			err = fmt.Errorf("%s: %w", link.Name, err)
+			if !errors.Is(err, ErrLicenseNotFound) && !errors.Is(err, ErrBlocked) && link.OnError != ErrorPolicyContinue {
+				results[rawPurl] = BatchResult{Err: err}
+				done[rawPurl] = true
+				continue
+			}
+			errs[rawPurl] = append(errs[rawPurl], err)
+		}
+		pending = slices.DeleteFunc(pending, func(rawPurl string) bool { return done[rawPurl] })
+	}
+
+	for _, rawPurl := range pending {
+		if len(errs[rawPurl]) == 0 {
+			results[rawPurl] = BatchResult{
+				Err: fmt.Errorf("%w: no provider for purl type %q", ErrLicenseNotFound, types[rawPurl]),
+			}
+			continue
+		}
+		results[rawPurl] = BatchResult{Err: errors.Join(errs[rawPurl]...)}
+	}
+	return results, nil
+}
+
+// getMany gets the licenses of the purls from the provider, in one batch if it supports batch lookups and one purl
+// at a time otherwise. If the batch fails, there are no results.
+func getMany(ctx context.Context, p Provider, purls []string) map[string]BatchResult {
+	if batch, ok := p.(BatchProvider); ok && SupportsBatch(p) {
+		results, err := batch.GetMany(ctx, purls)
+		if err != nil {
+			return nil
+		}
+		return results
+	}
+
+	results := make(map[string]BatchResult, len(purls))
+	for _, rawPurl := range purls {
+		if ctx.Err() != nil {
+			break
+		}
+		resolution, err := resolve(ctx, p, rawPurl)
+		results[rawPurl] = BatchResult{License: resolution.License, Disagreement: resolution.Disagreement, Err: err}
+	}
+	return results
+}
@@ -0,0 +1,241 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestChain_Resolve tests routing by purl type, fallback and error policies.
+func TestChain_Resolve(t *testing.T) {
+	t.Parallel()
+
+	errUnavailable := errors.New("service unavailable")
+
+	tests := []struct {
+		name         string
+		purl         string
+		links        func() []provider.Link
+		want         provider.Resolution
+		wantErrIs    error
+		wantGetCalls []int
+	}{
+		{
+			name: "first provider",
+			purl: "pkg:npm/lodash@4.17.21",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "first", Provider: &mockProvider{license: "MIT"}},
+					{Name: "second", Provider: &mockProvider{license: "ISC"}},
+				}
+			},
+			want:         provider.Resolution{License: "MIT", Provider: "first"},
+			wantGetCalls: []int{1, 0},
+		},
+		{
+			name: "not found falls back",
+			purl: "pkg:npm/lodash@4.17.21",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "first", Provider: &mockProvider{err: provider.ErrLicenseNotFound}},
+					{Name: "empty", Provider: &mockProvider{}},
+					{Name: "blocked", Provider: &mockProvider{err: provider.ErrBlocked}},
+					{Name: "last", Provider: &mockProvider{license: "ISC"}},
+				}
+			},
+			want:         provider.Resolution{License: "ISC", Provider: "last"},
+			wantGetCalls: []int{1, 1, 1, 1},
+		},
+		{
+			name: "routed by type",
+			purl: "pkg:golang/github.com/example/mod@v1.0.0",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "npm", Provider: &mockProvider{license: "MIT"}, Types: []string{"npm"}},
+					{Name: "go", Provider: &mockProvider{license: "BSD-3-Clause"}, Types: []string{"NPM", "Golang"}},
+				}
+			},
+			want:         provider.Resolution{License: "BSD-3-Clause", Provider: "go"},
+			wantGetCalls: []int{0, 1},
+		},
+		{
+			name: "error stops",
+			purl: "pkg:npm/lodash@4.17.21",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "first", Provider: &mockProvider{err: errUnavailable}},
+					{Name: "second", Provider: &mockProvider{license: "MIT"}},
+				}
+			},
+			wantErrIs:    errUnavailable,
+			wantGetCalls: []int{1, 0},
+		},
+		{
+			name: "error continues",
+			purl: "pkg:npm/lodash@4.17.21",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "first", Provider: &mockProvider{err: errUnavailable}, OnError: provider.ErrorPolicyContinue},
+					{Name: "second", Provider: &mockProvider{license: "MIT"}},
+				}
+			},
+			want:         provider.Resolution{License: "MIT", Provider: "second"},
+			wantGetCalls: []int{1, 1},
+		},
+		{
+			name: "error continues without license",
+			purl: "pkg:npm/lodash@4.17.21",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "first", Provider: &mockProvider{err: errUnavailable}, OnError: provider.ErrorPolicyContinue},
+					{Name: "second", Provider: &mockProvider{err: provider.ErrLicenseNotFound}},
+				}
+			},
+			wantErrIs:    errUnavailable,
+			wantGetCalls: []int{1, 1},
+		},
+		{
+			name: "no provider for type",
+			purl: "pkg:cargo/serde@1.0.0",
+			links: func() []provider.Link {
+				return []provider.Link{
+					{Name: "npm", Provider: &mockProvider{license: "MIT"}, Types: []string{"npm"}},
+				}
+			},
+			wantErrIs:    provider.ErrLicenseNotFound,
+			wantGetCalls: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			links := tt.links()
+			got, err := provider.NewChain(links).Resolve(context.Background(), tt.purl)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Resolve() error = %v, want %v", err, tt.wantErrIs)
+				}
+			} else if err != nil {
+				t.Fatalf("Resolve() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+			for i, link := range links {
+				if calls := link.Provider.(*mockProvider).getCalls; calls != tt.wantGetCalls[i] {
+					t.Errorf("provider %s called %d times, want %d", link.Name, calls, tt.wantGetCalls[i])
+				}
+			}
+		})
+	}
+}
+
+// TestChain_Resolve_Cancelled tests that a cancelled lookup does not ask the remaining providers.
+func TestChain_Resolve_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	second := &mockProvider{license: "MIT"}
+	chain := provider.NewChain([]provider.Link{
+		{Name: "first", Provider: &mockProvider{err: context.Canceled}, OnError: provider.ErrorPolicyContinue},
+		{Name: "second", Provider: second},
+	})
+	if _, err := chain.Get(ctx, "pkg:npm/lodash@4.17.21"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() error = %v, want %v", err, context.Canceled)
+	}
+	if second.getCalls != 0 {
+		t.Errorf("second provider called %d times, want 0", second.getCalls)
+	}
+}
+
+// TestChain_GetMany tests that providers supporting batch lookups are asked for all their purls at once, and the
+// others one purl at a time.
+func TestChain_GetMany(t *testing.T) {
+	t.Parallel()
+
+	local := &mockProvider{err: provider.ErrLicenseNotFound}
+	remote := &mockBatchProvider{licenses: map[string]string{
+		"pkg:npm/lodash@4.17.21":   "MIT",
+		"pkg:npm/express@4.18.2":   "",
+		"pkg:pypi/requests@2.31.0": "Apache-2.0",
+	}}
+	fallback := &mockProvider{license: "BSD-3-Clause"}
+	guard, err := provider.NewGuard(remote, nil)
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+	chain := provider.NewChain([]provider.Link{
+		{Name: "local", Provider: local, Types: []string{"npm"}},
+		{Name: "remote", Provider: guard, Types: []string{"npm", "pypi"}},
+		{Name: "fallback", Provider: fallback, Types: []string{"npm"}},
+	})
+
+	purls := []string{
+		"pkg:npm/lodash@4.17.21",
+		"pkg:npm/express@4.18.2",
+		"pkg:pypi/requests@2.31.0",
+		"pkg:npm/unanswered@1.0.0",
+		"pkg:cargo/serde@1.0.0",
+	}
+	results, err := chain.GetMany(context.Background(), purls)
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+
+	want := map[string]provider.BatchResult{
+		"pkg:npm/lodash@4.17.21":   {License: "MIT", Provider: "remote"},
+		"pkg:npm/express@4.18.2":   {License: "BSD-3-Clause", Provider: "fallback"},
+		"pkg:pypi/requests@2.31.0": {License: "Apache-2.0", Provider: "remote"},
+	}
+	for purl, result := range want {
+		if !reflect.DeepEqual(results[purl], result) {
+			t.Errorf("GetMany()[%s] = %+v, want %+v", purl, results[purl], result)
+		}
+	}
+	if result := results["pkg:cargo/serde@1.0.0"]; !errors.Is(result.Err, provider.ErrLicenseNotFound) {
+		t.Errorf("GetMany()[pkg:cargo/serde@1.0.0] = %+v, want %v", result, provider.ErrLicenseNotFound)
+	}
+	// Purls the batch has no result for are left to Resolve
+	if result, ok := results["pkg:npm/unanswered@1.0.0"]; ok {
+		t.Errorf("GetMany()[pkg:npm/unanswered@1.0.0] = %+v, want no result", result)
+	}
+
+	wantBatches := [][]string{{
+		"pkg:npm/lodash@4.17.21", "pkg:npm/express@4.18.2", "pkg:pypi/requests@2.31.0", "pkg:npm/unanswered@1.0.0",
+	}}
+	if !reflect.DeepEqual(remote.batches, wantBatches) {
+		t.Errorf("batches = %v, want %v", remote.batches, wantBatches)
+	}
+	if local.getCalls != 3 || fallback.getCalls != 1 {
+		t.Errorf("providers called %d and %d times, want 3 and 1", local.getCalls, fallback.getCalls)
+	}
+}
+
+// TestChain_GetMany_Unsupported tests that a chain without providers supporting batch lookups does not batch.
+func TestChain_GetMany_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	guard, err := provider.NewGuard(&mockProvider{license: "MIT"}, nil)
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+	chain := provider.NewChain([]provider.Link{
+		{Name: "local", Provider: &mockProvider{license: "MIT"}},
+		{Name: "remote", Provider: provider.NewBreaker(guard, provider.BreakerOptions{})},
+	})
+
+	if provider.SupportsBatch(chain) {
+		t.Error("SupportsBatch() = true, want false")
+	}
+	_, err = chain.GetMany(context.Background(), []string{"pkg:npm/lodash@4.17.21"})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("GetMany() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+}
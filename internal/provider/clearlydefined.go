@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// clearlyDefinedBaseURL is the base URL for the ClearlyDefined API.
+	//
+	// See https://api.clearlydefined.io/api-docs/
+	clearlyDefinedBaseURL = "https://api.clearlydefined.io"
+	// clearlyDefinedAPIPath is the API path for definitions.
+	clearlyDefinedAPIPath = "/definitions"
+)
+
+// clearlyDefinedProviders maps purl types to ClearlyDefined coordinate types and providers.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var clearlyDefinedProviders = map[string][2]string{
+	"cargo":     {"crate", "cratesio"},
+	"cocoapods": {"pod", "cocoapods"},
+	"composer":  {"composer", "packagist"},
+	"gem":       {"gem", "rubygems"},
+	"github":    {"git", "github"},
+	"golang":    {"go", "golang"},
+	"maven":     {"maven", "mavencentral"},
+	"npm":       {"npm", "npmjs"},
+	"nuget":     {"nuget", "nuget"},
+	"pypi":      {"pypi", "pypi"},
+}
+
+// ClearlyDefinedClient is the client for the ClearlyDefined API.
+type ClearlyDefinedClient struct {
+	baseURL    string
+	client     *http.Client
+	discovered bool
+}
+
+var _ Provider = (*ClearlyDefinedClient)(nil)
+
+// ClearlyDefinedOptions are the options for the ClearlyDefinedClient.
+type ClearlyDefinedOptions struct {
+	// BaseURL is the base URL for the ClearlyDefined API.
+	// If empty, defaults to the public ClearlyDefined API.
+	BaseURL string
+	// Client is the HTTP client to use for the ClearlyDefined API.
+	// If nil, defaults to an HTTP client with timeout.
+	Client *http.Client
+	// Discovered falls back to the licenses discovered in the package files if no license is declared.
+	// The discovered licenses are combined with AND.
+	Discovered bool
+}
+
+// NewClearlyDefinedClient creates a new ClearlyDefinedClient.
+func NewClearlyDefinedClient(opts ClearlyDefinedOptions) *ClearlyDefinedClient {
+	baseURL := clearlyDefinedBaseURL
+	if opts.BaseURL != "" {
+		baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
+	return &ClearlyDefinedClient{
+		baseURL:    baseURL,
+		client:     client,
+		discovered: opts.Discovered,
+	}
+}
+
+// clearlyDefinedDefinition is a definition of the ClearlyDefined API.
+type clearlyDefinedDefinition struct {
+	Licensed struct {
+		Declared string `json:"declared"`
+		Facets   struct {
+			Core struct {
+				Discovered struct {
+					Expressions []string `json:"expressions"`
+				} `json:"discovered"`
+			} `json:"core"`
+		} `json:"facets"`
+	} `json:"licensed"`
+}
+
+// Get gets the license for a package from the ClearlyDefined API.
+func (c *ClearlyDefinedClient) Get(ctx context.Context, rawPurl string) (string, error) {
+	coordinates, err := ClearlyDefinedCoordinates(rawPurl)
+	if err != nil {
+		return "", err
+	}
+
+	var definition clearlyDefinedDefinition
+	if err = getJSON(ctx, c.client, c.baseURL+clearlyDefinedAPIPath+"/"+coordinates, nil, &definition); err != nil {
+		return "", err
+	}
+
+	// Unknown components are returned as definitions without license information
+	if declared := definition.Licensed.Declared; isClearlyDefinedLicense(declared) {
+		return declared, nil
+	}
+	if c.discovered {
+		var discovered []string
+		for _, expression := range definition.Licensed.Facets.Core.Discovered.Expressions {
+			if isClearlyDefinedLicense(expression) && !slices.Contains(discovered, expression) {
+				discovered = append(discovered, expression)
+			}
+		}
+		if len(discovered) > 0 {
+			return license.Join(license.OperatorAnd, discovered), nil
+		}
+	}
+	return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, rawPurl)
+}
+
+// ClearlyDefinedCoordinates converts a purl into ClearlyDefined coordinates, e.g. "npm/npmjs/-/lodash/4.17.21".
+// Each coordinate is escaped for use in a URL path, so the slashes of Go namespaces become "%2F".
+//
+// ClearlyDefined only has definitions of specific versions, so the purl must have a version.
+func ClearlyDefinedCoordinates(rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	provider, ok := clearlyDefinedProviders[parsed.Type]
+	if !ok {
+		return "", fmt.Errorf("%w: purl type %q is not supported by ClearlyDefined", ErrLicenseNotFound, parsed.Type)
+	}
+	if parsed.Version == "" {
+		return "", fmt.Errorf("%w: ClearlyDefined requires a version: %s", ErrLicenseNotFound, rawPurl)
+	}
+
+	namespace := parsed.Namespace
+	if namespace == "" {
+		namespace = "-"
+	}
+	coordinates := []string{provider[0], provider[1], namespace, parsed.Name, parsed.Version}
+	for i, coordinate := range coordinates {
+		coordinates[i] = url.PathEscape(coordinate)
+	}
+	return strings.Join(coordinates, "/"), nil
+}
+
+// isClearlyDefinedLicense reports whether a ClearlyDefined license expression names a license.
+func isClearlyDefinedLicense(expression string) bool {
+	switch strings.TrimSpace(expression) {
+	case "", "NOASSERTION", "NONE", "OTHER":
+		return false
+	default:
+		return true
+	}
+}
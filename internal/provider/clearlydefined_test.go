@@ -0,0 +1,145 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestClearlyDefinedCoordinates tests converting purls to ClearlyDefined coordinates.
+func TestClearlyDefinedCoordinates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		purl    string
+		want    string
+		wantErr bool
+	}{
+		{purl: "pkg:npm/lodash@4.17.21", want: "npm/npmjs/-/lodash/4.17.21"},
+		{purl: "pkg:npm/%40types/node@18.0.0", want: "npm/npmjs/@types/node/18.0.0"},
+		{purl: "pkg:maven/org.apache.commons/commons-lang3@3.12.0?type=jar",
+			want: "maven/mavencentral/org.apache.commons/commons-lang3/3.12.0"},
+		{purl: "pkg:pypi/requests@2.28.0", want: "pypi/pypi/-/requests/2.28.0"},
+		{purl: "pkg:cargo/serde@1.0.0", want: "crate/cratesio/-/serde/1.0.0"},
+		{purl: "pkg:nuget/Newtonsoft.Json@13.0.1", want: "nuget/nuget/-/Newtonsoft.Json/13.0.1"},
+		{purl: "pkg:golang/github.com/gorilla/mux@v1.8.0", want: "go/golang/github.com%2Fgorilla/mux/v1.8.0"},
+		{purl: "pkg:npm/lodash", wantErr: true},
+		{purl: "pkg:deb/debian/curl@7.88.1", wantErr: true},
+		{purl: "not-a-purl", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := provider.ClearlyDefinedCoordinates(tt.purl)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ClearlyDefinedCoordinates(%q) error = %v, wantErr %v", tt.purl, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ClearlyDefinedCoordinates(%q) = %q, want %q", tt.purl, got, tt.want)
+		}
+	}
+}
+
+// TestClearlyDefinedClient_Get tests getting licenses from a stub ClearlyDefined API.
+func TestClearlyDefinedClient_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		discovered   bool
+		mockResponse string
+		want         string
+		wantErrIs    error
+	}{
+		{
+			name:         "declared license",
+			mockResponse: `{"licensed": {"declared": "MIT"}}`,
+			want:         "MIT",
+		},
+		{
+			name: "declared license preferred over discovered",
+			mockResponse: `{"licensed": {"declared": "Apache-2.0",
+				"facets": {"core": {"discovered": {"expressions": ["MIT"]}}}}}`,
+			discovered: true,
+			want:       "Apache-2.0",
+		},
+		{
+			name: "discovered licenses",
+			mockResponse: `{"licensed": {"declared": "NOASSERTION",
+				"facets": {"core": {"discovered": {"expressions": ["MIT", "BSD-2-Clause OR MIT", "MIT", "NONE"]}}}}}`,
+			discovered: true,
+			want:       "MIT AND (BSD-2-Clause OR MIT)",
+		},
+		{
+			name: "discovered licenses disabled",
+			mockResponse: `{"licensed": {"declared": "NOASSERTION",
+				"facets": {"core": {"discovered": {"expressions": ["MIT"]}}}}}`,
+			wantErrIs: provider.ErrLicenseNotFound,
+		},
+		{
+			name:         "unknown component",
+			mockResponse: `{"described": {"tools": []}, "licensed": {"toolScore": {"total": 0}}}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+		{
+			name:         "invalid response",
+			mockResponse: `not json`,
+			wantErrIs:    provider.ErrInvalidResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != "/definitions/go/golang/github.com%2Fgorilla/mux/v1.8.0" {
+					t.Errorf("path = %q, want the coordinates of the purl", r.URL.EscapedPath())
+				}
+				if r.Header.Get("User-Agent") != "sbomlicense/dev" {
+					t.Errorf("User-Agent = %q, want %q", r.Header.Get("User-Agent"), "sbomlicense/dev")
+				}
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			t.Cleanup(server.Close)
+
+			client := provider.NewClearlyDefinedClient(provider.ClearlyDefinedOptions{
+				BaseURL:    server.URL + "/",
+				Discovered: tt.discovered,
+			})
+
+			got, err := client.Get(context.Background(), "pkg:golang/github.com/gorilla/mux@v1.8.0")
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClearlyDefinedClient_Get_HTTPErrors tests that HTTP errors are reported.
+func TestClearlyDefinedClient_Get_HTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	client := provider.NewClearlyDefinedClient(provider.ClearlyDefinedOptions{BaseURL: server.URL})
+	if _, err := client.Get(context.Background(), "pkg:npm/lodash@4.17.21"); !errors.Is(err, provider.ErrLicenseNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, provider.ErrLicenseNotFound)
+	}
+}
@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the kind of a provider in a chain configuration.
+type Kind string
+
+const (
+	// KindEcosystems is the Ecosyste.ms API.
+	KindEcosystems Kind = "ecosystems"
+	// KindClearlyDefined is the ClearlyDefined API.
+	KindClearlyDefined Kind = "clearlydefined"
+	// KindDepsDev is the deps.dev API.
+	KindDepsDev Kind = "depsdev"
+	// KindNPM is an npm registry.
+	KindNPM Kind = "npm"
+	// KindPyPI is a Python package index.
+	KindPyPI Kind = "pypi"
+	// KindMaven is a Maven repository.
+	KindMaven Kind = "maven"
+	// KindGoProxy is a Go module proxy.
+	KindGoProxy Kind = "goproxy"
+	// KindFilesystem is the dependencies installed in local directories.
+	KindFilesystem Kind = "filesystem"
+	// KindDistro is the packages installed by the distribution package manager.
+	KindDistro Kind = "distro"
+	// KindConsensus asks the nested providers concurrently and combines their licenses.
+	KindConsensus Kind = "consensus"
+)
+
+// ChainConfig is the configuration of a provider chain.
+//
+// Example:
+//
+//	providers:
+//	  - provider: filesystem
+//	    nodeModules: [./node_modules]
+//	  - provider: goproxy
+//	    types: [golang]
+//	  - provider: consensus
+//	    strategy: majority
+//	    providers:
+//	      - provider: depsdev
+//	        onError: continue
+//	      - provider: clearlydefined
+//	      - provider: ecosystems
+type ChainConfig struct {
+	// Providers are asked in order.
+	Providers []LinkConfig `yaml:"providers"`
+}
+
+// LinkConfig is the configuration of a provider in a chain.
+type LinkConfig struct {
+	// Provider is the kind of the provider.
+	Provider Kind `yaml:"provider"`
+	// Name identifies the provider in lookup results.
+	// If empty, defaults to the kind of the provider.
+	Name string `yaml:"name"`
+	// Types are the purl types the provider is asked for.
+	// If empty, the provider is asked for all purl types.
+	Types []string `yaml:"types"`
+	// OnError is "stop" or "continue".
+	// If empty, defaults to "stop".
+	OnError ErrorPolicy `yaml:"onError"`
+	// URL is the base URL of the API, registry, index, repository or proxy.
+	// If empty, defaults to the public instance.
+	URL string `yaml:"url"`
+	// Token is sent as bearer token to an npm registry.
+	Token string `yaml:"token"`
+	// Discovered falls back to the licenses discovered by ClearlyDefined if no license is declared.
+	Discovered bool `yaml:"discovered"`
+	// Root is the root directory of a distro provider.
+	// If empty, defaults to "/".
+	Root string `yaml:"root"`
+	// NodeModules are the node_modules directories of a filesystem provider.
+	NodeModules []string `yaml:"nodeModules"`
+	// Vendor are the Go vendor directories of a filesystem provider.
+	Vendor []string `yaml:"vendor"`
+	// GoModCache is the Go module cache directory of a filesystem provider.
+	GoModCache string `yaml:"goModCache"`
+	// SitePackages are the Python site-packages directories of a filesystem provider.
+	SitePackages []string `yaml:"sitePackages"`
+	// MavenRepository is the local Maven repository directory of a filesystem provider.
+	MavenRepository string `yaml:"mavenRepository"`
+	// Strategy is the strategy of a consensus provider: "majority", "union" or "most-specific".
+	// If empty, defaults to "majority".
+	Strategy Strategy `yaml:"strategy"`
+	// Providers are the nested providers of a consensus provider.
+	Providers []LinkConfig `yaml:"providers"`
+}
+
+// ChainOptions are the options for building a Chain from a ChainConfig.
+type ChainOptions struct {
+	// Email is the email address for the Ecosyste.ms polite pool.
+	Email string
+	// Private are the private purl patterns, see NewGuard.
+	//
+	// Providers that send purls outside of the organisation are wrapped in a Guard.
+	Private []string
+	// RateLimiter limits the requests to the Ecosyste.ms API.
+	// If nil, requests are not limited.
+	RateLimiter *RateLimiter
+	// Breaker configures the circuit breaker around each provider that sends purls outside of the machine.
+	// The name defaults to the name of the provider.
+	Breaker BreakerOptions
+}
+
+// LoadChainConfig loads a chain configuration from a YAML or JSON file.
+func LoadChainConfig(path string) (*ChainConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read providers: %w", err)
+	}
+	return ParseChainConfig(data)
+}
+
+// ParseChainConfig parses a chain configuration from YAML or JSON and validates it.
+func ParseChainConfig(data []byte) (*ChainConfig, error) {
+	var c ChainConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse providers: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the kinds, names, error policies and strategies of the providers.
+func (c *ChainConfig) Validate() error {
+	return validateLinks(c.Providers, "")
+}
+
+// validateLinks checks a list of providers, numbered with the prefix in errors.
+func validateLinks(links []LinkConfig, prefix string) error {
+	if len(links) == 0 && prefix != "" {
+		return fmt.Errorf("invalid provider %s: no providers", strings.TrimSuffix(prefix, "."))
+	}
+	if len(links) == 0 {
+		return errors.New("no providers")
+	}
+	names := map[string]bool{}
+	for i, link := range links {
+		number := fmt.Sprintf("%s%d", prefix, i)
+		switch link.Provider {
+		case KindEcosystems, KindClearlyDefined, KindDepsDev, KindNPM, KindPyPI, KindMaven, KindGoProxy,
+			KindFilesystem, KindDistro, KindConsensus:
+		default:
+			return fmt.Errorf("invalid provider %s: unknown provider %q", number, link.Provider)
+		}
+		switch link.OnError {
+		case "", ErrorPolicyStop, ErrorPolicyContinue:
+		default:
+			return fmt.Errorf("invalid provider %s: onError %q must be stop or continue", number, link.OnError)
+		}
+		name := link.name()
+		if names[name] {
+			return fmt.Errorf("invalid provider %s: duplicate name %q", number, name)
+		}
+		names[name] = true
+
+		if link.Provider != KindConsensus {
+			if link.Strategy != "" || len(link.Providers) > 0 {
+				return fmt.Errorf("invalid provider %s: only consensus has a strategy and providers", number)
+			}
+			continue
+		}
+		switch link.Strategy {
+		case "", StrategyMajority, StrategyUnion, StrategyMostSpecific:
+		default:
+			return fmt.Errorf("invalid provider %s: strategy %q must be majority, union or most-specific", number,
+				link.Strategy)
+		}
+		if err := validateLinks(link.Providers, number+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Uses reports whether one of the providers, including nested providers, is of the kind.
+func (c *ChainConfig) Uses(kind Kind) bool {
+	return usesKind(c.Providers, kind)
+}
+
+// usesKind reports whether one of the providers, including nested providers, is of the kind.
+func usesKind(links []LinkConfig, kind Kind) bool {
+	for _, link := range links {
+		if link.Provider == kind || usesKind(link.Providers, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Chain builds the Chain of the configured providers.
+func (c *ChainConfig) Chain(opts ChainOptions) (*Chain, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	links, err := buildLinks(c.Providers, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewChain(links), nil
+}
+
+// buildLinks creates the configured providers.
+func buildLinks(configs []LinkConfig, opts ChainOptions) ([]Link, error) {
+	links := make([]Link, 0, len(configs))
+	for _, config := range configs {
+		var p Provider
+		if config.Provider == KindConsensus {
+			nested, err := buildLinks(config.Providers, opts)
+			if err != nil {
+				return nil, err
+			}
+			p = NewConsensus(nested, config.Strategy)
+		} else {
+			var remote bool
+			p, remote = config.provider(opts)
+			if remote {
+				breaker := opts.Breaker
+				breaker.Name = config.name()
+				guard, err := NewGuard(NewBreaker(p, breaker), opts.Private)
+				if err != nil {
+					return nil, err
+				}
+				p = guard
+			}
+		}
+		links = append(links, Link{Name: config.name(), Provider: p, Types: config.Types, OnError: config.OnError})
+	}
+	return links, nil
+}
+
+// name returns the name of the provider, defaulting to its kind.
+func (l LinkConfig) name() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return string(l.Provider)
+}
+
+// provider creates the configured provider, and reports whether it sends purls outside of the machine.
+func (l LinkConfig) provider(opts ChainOptions) (Provider, bool) {
+	remote := !strings.HasPrefix(l.URL, fileScheme)
+	switch l.Provider {
+	case KindEcosystems:
+		return NewClient(ClientOptions{BaseURL: l.URL, Email: opts.Email, RateLimiter: opts.RateLimiter}), true
+	case KindClearlyDefined:
+		return NewClearlyDefinedClient(ClearlyDefinedOptions{BaseURL: l.URL, Discovered: l.Discovered}), true
+	case KindDepsDev:
+		return NewDepsDevClient(DepsDevOptions{BaseURL: l.URL}), true
+	case KindNPM:
+		return NewNPMClient(NPMOptions{RegistryURL: l.URL, Token: l.Token}), true
+	case KindPyPI:
+		return NewPyPIClient(PyPIOptions{IndexURL: l.URL}), true
+	case KindMaven:
+		return NewMavenClient(MavenOptions{RepositoryURL: l.URL}), remote
+	case KindGoProxy:
+		return NewGoProxyClient(GoProxyOptions{ProxyURL: l.URL}), remote
+	case KindDistro:
+		root := l.Root
+		if root == "" {
+			root = "/"
+		}
+		return NewDistro(DistroOptions{Root: root}), false
+	default:
+		return NewFilesystem(FilesystemOptions{
+			NodeModules:     l.NodeModules,
+			Vendor:          l.Vendor,
+			GoModCache:      l.GoModCache,
+			SitePackages:    l.SitePackages,
+			MavenRepository: l.MavenRepository,
+		}), false
+	}
+}
@@ -0,0 +1,152 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestParseChainConfig tests parsing and validating chain configurations.
+func TestParseChainConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			data: `providers:
+  - provider: filesystem
+    nodeModules: [./node_modules]
+  - provider: goproxy
+    types: [golang]
+  - provider: depsdev
+    onError: continue
+  - provider: npm
+    name: artifactory
+    url: https://artifactory.example.com/api/npm/npm
+  - provider: npm
+`,
+			want: 5,
+		},
+		{name: "json", data: `{"providers": [{"provider": "ecosystems"}]}`, want: 1},
+		{name: "empty", data: ``, wantErr: true},
+		{name: "unknown provider", data: "providers:\n  - provider: bogus\n", wantErr: true},
+		{name: "unknown field", data: "providers:\n  - provider: npm\n    registry: x\n", wantErr: true},
+		{name: "invalid onError", data: "providers:\n  - provider: npm\n    onError: retry\n", wantErr: true},
+		{name: "duplicate name", data: "providers:\n  - provider: npm\n  - provider: npm\n", wantErr: true},
+		{
+			name: "consensus",
+			data: `providers:
+  - provider: consensus
+    strategy: union
+    providers:
+      - provider: ecosystems
+      - provider: clearlydefined
+        discovered: true
+`,
+			want: 1,
+		},
+		{
+			name:    "invalid strategy",
+			data:    "providers:\n  - provider: consensus\n    strategy: vote\n    providers: [{provider: npm}]\n",
+			wantErr: true,
+		},
+		{name: "strategy without consensus", data: "providers:\n  - provider: npm\n    strategy: union\n", wantErr: true},
+		{name: "consensus without providers", data: "providers:\n  - provider: consensus\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := provider.ParseChainConfig([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChainConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(got.Providers) != tt.want {
+				t.Errorf("ParseChainConfig() = %d providers, want %d", len(got.Providers), tt.want)
+			}
+		})
+	}
+}
+
+// TestChainConfig_Chain tests that a configured chain resolves licenses and guards remote providers.
+func TestChainConfig_Chain(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFilesystemTestFiles(t, dir, map[string]string{
+		"node_modules/lodash/package.json": `{"name": "lodash", "version": "4.17.21", "license": "MIT"}`,
+		"m2/org/example/core/1.0.0/core-1.0.0.pom": `<project>
+			<licenses><license><name>Apache-2.0</name></license></licenses>
+		</project>`,
+		"providers.yaml": `providers:
+  - provider: filesystem
+    types: [npm]
+    nodeModules: [` + filepath.Join(dir, "node_modules") + `]
+  - provider: maven
+    name: local-maven
+    url: file://` + filepath.ToSlash(filepath.Join(dir, "m2")) + `
+  - provider: depsdev
+    url: http://127.0.0.1:1
+`,
+	})
+
+	config, err := provider.LoadChainConfig(filepath.Join(dir, "providers.yaml"))
+	if err != nil {
+		t.Fatalf("LoadChainConfig() error = %v", err)
+	}
+	if !config.Uses(provider.KindDepsDev) || config.Uses(provider.KindEcosystems) {
+		t.Errorf("Uses() reports the wrong providers")
+	}
+	chain, err := config.Chain(provider.ChainOptions{Private: []string{"pkg:*/org.example/*", "pkg:npm/*"}})
+	if err != nil {
+		t.Fatalf("Chain() error = %v", err)
+	}
+
+	tests := []struct {
+		purl      string
+		want      provider.Resolution
+		wantErrIs error
+	}{
+		// Local providers are not guarded
+		{purl: "pkg:npm/lodash@4.17.21", want: provider.Resolution{License: "MIT", Provider: "filesystem"}},
+		{purl: "pkg:maven/org.example/core@1.0.0", want: provider.Resolution{License: "Apache-2.0", Provider: "local-maven"}},
+		// The remote provider is guarded, so the unreachable URL is never requested
+		{purl: "pkg:maven/org.example/missing@1.0.0", wantErrIs: provider.ErrBlocked},
+	}
+
+	for _, tt := range tests {
+		got, resolveErr := chain.Resolve(context.Background(), tt.purl)
+		if tt.wantErrIs != nil {
+			if !errors.Is(resolveErr, tt.wantErrIs) {
+				t.Errorf("Resolve(%q) error = %v, want %v", tt.purl, resolveErr, tt.wantErrIs)
+			}
+			continue
+		}
+		if resolveErr != nil {
+			t.Fatalf("Resolve(%q) unexpected error = %v", tt.purl, resolveErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Resolve(%q) = %+v, want %+v", tt.purl, got, tt.want)
+		}
+	}
+}
+
+// TestLoadChainConfig_Missing tests that a missing file is an error.
+func TestLoadChainConfig_Missing(t *testing.T) {
+	t.Parallel()
+
+	if _, err := provider.LoadChainConfig(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadChainConfig() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// Strategy decides which license a Consensus returns when its providers disagree.
+type Strategy string
+
+const (
+	// StrategyMajority returns the license found by most providers. Ties go to the provider listed first.
+	// This is the default.
+	StrategyMajority Strategy = "majority"
+	// StrategyUnion returns all licenses found combined with AND.
+	StrategyUnion Strategy = "union"
+	// StrategyMostSpecific returns the license naming the most licenses of the SPDX License List, e.g.
+	// "MIT AND BSD-3-Clause" over "MIT". Ties go to the provider listed first.
+	StrategyMostSpecific Strategy = "most-specific"
+)
+
+// Answer is the license found by one provider.
+type Answer struct {
+	// Provider is the name of the provider.
+	Provider string `json:"provider"`
+	// License is the license the provider found.
+	License string `json:"license"`
+}
+
+// Consensus is a Provider that asks several providers concurrently and combines their licenses.
+//
+// Licenses are compared after normalization, so "GPL-2.0" agrees with "GPL-2.0-only" and "MIT AND ISC" with
+// "ISC AND MIT". When the providers disagree, the license is chosen by the strategy and all answers are
+// reported in Resolution.Disagreement for review.
+//
+// As in a Chain, each provider is only asked for the purl types it is routed to, ErrLicenseNotFound and
+// ErrBlocked count as no answer, and other errors fail the lookup unless the provider continues on error.
+type Consensus struct {
+	links    []Link
+	strategy Strategy
+}
+
+var _ Resolver = (*Consensus)(nil)
+
+// NewConsensus creates a new Consensus of the providers of the links.
+//
+// If strategy is empty, defaults to StrategyMajority.
+func NewConsensus(links []Link, strategy Strategy) *Consensus {
+	if strategy == "" {
+		strategy = StrategyMajority
+	}
+	return &Consensus{links: NewChain(links).links, strategy: strategy}
+}
+
+// Get gets the license the providers agree on.
+func (c *Consensus) Get(ctx context.Context, purl string) (string, error) {
+	resolution, err := c.Resolve(ctx, purl)
+	return resolution.License, err
+}
+
+// Resolve gets the license the providers agree on, the names of the providers that found it and, if they
+// disagree, all answers.
+func (c *Consensus) Resolve(ctx context.Context, rawPurl string) (Resolution, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	links := make([]Link, 0, len(c.links))
+	for _, link := range c.links {
+		if len(link.Types) == 0 || slices.Contains(link.Types, parsed.Type) {
+			links = append(links, link)
+		}
+	}
+	if len(links) == 0 {
+		return Resolution{}, fmt.Errorf("%w: no provider for purl type %q", ErrLicenseNotFound, parsed.Type)
+	}
+
+	licenses := make([]string, len(links))
+	errs := make([]error, len(links))
+	var wg sync.WaitGroup
+	for i, link := range links {
+		wg.Go(func() {
+			licenses[i], errs[i] = link.Provider.Get(ctx, rawPurl)
+		})
+	}
+	wg.Wait()
+
+	// Errors are handled in the order of the providers, so the outcome does not depend on timing
+	var answers []Answer
+	var skipped []error
+	for i, link := range links {
+		err := errs[i]
+		if err == nil && licenses[i] != "" {
+			answers = append(answers, Answer{Provider: link.Name, License: licenses[i]})
+			continue
+		}
+		if err == nil {
+			err = ErrLicenseNotFound
+		}
+		err = fmt.Errorf("%s: %w", link.Name, err)
+		if ctx.Err() != nil {
+			return Resolution{}, err
+		}
+		if !errors.Is(err, ErrLicenseNotFound) && !errors.Is(err, ErrBlocked) && link.OnError != ErrorPolicyContinue {
+			return Resolution{}, err
+		}
+		skipped = append(skipped, err)
+	}
+	if len(answers) == 0 {
+		return Resolution{}, errors.Join(skipped...)
+	}
+
+	return c.decide(answers), nil
+}
+
+// consensusGroup is the answers that agree on a license.
+type consensusGroup struct {
+	license    string
+	expression *license.Expression
+	providers  []string
+}
+
+// decide chooses the license of the answers by the strategy.
+func (c *Consensus) decide(answers []Answer) Resolution {
+	// Group the answers by normalized license, in the order of the providers
+	var groups []*consensusGroup
+	byKey := map[string]*consensusGroup{}
+	for _, answer := range answers {
+		expression, err := license.Parse(answer.License)
+		key := answer.License
+		if err == nil {
+			expression = canonicalExpression(expression.Normalize())
+			key = expression.String()
+		}
+		group, ok := byKey[key]
+		if !ok {
+			group = &consensusGroup{license: answer.License, expression: expression}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.providers = append(group.providers, answer.Provider)
+	}
+
+	if len(groups) == 1 {
+		return Resolution{License: groups[0].license, Provider: strings.Join(groups[0].providers, ",")}
+	}
+
+	resolution := Resolution{Disagreement: answers}
+	switch c.strategy {
+	case StrategyUnion:
+		resolution.License = unionLicense(groups)
+		providers := make([]string, len(answers))
+		for i, answer := range answers {
+			providers[i] = answer.Provider
+		}
+		resolution.Provider = strings.Join(providers, ",")
+	case StrategyMostSpecific:
+		best := groups[0]
+		for _, group := range groups[1:] {
+			if specificity(group.expression) > specificity(best.expression) {
+				best = group
+			}
+		}
+		resolution.License, resolution.Provider = best.license, strings.Join(best.providers, ",")
+	default:
+		best := groups[0]
+		for _, group := range groups[1:] {
+			if len(group.providers) > len(best.providers) {
+				best = group
+			}
+		}
+		resolution.License, resolution.Provider = best.license, strings.Join(best.providers, ",")
+	}
+	return resolution
+}
+
+// unionLicense combines the licenses of the groups with AND, without repeating licenses.
+func unionLicense(groups []*consensusGroup) string {
+	union := &license.Expression{Operator: license.OperatorAnd}
+	seen := map[string]bool{}
+	for _, group := range groups {
+		if group.expression == nil {
+			// Licenses that are not valid expressions cannot be merged, so they are joined as they are
+			licenses := make([]string, len(groups))
+			for i, g := range groups {
+				licenses[i] = g.license
+			}
+			return license.Join(license.OperatorAnd, licenses)
+		}
+		operands := []*license.Expression{group.expression}
+		if group.expression.Operator == license.OperatorAnd {
+			operands = group.expression.Operands
+		}
+		for _, operand := range operands {
+			if key := operand.String(); !seen[key] {
+				seen[key] = true
+				union.Operands = append(union.Operands, operand)
+			}
+		}
+	}
+	if len(union.Operands) == 1 {
+		return union.Operands[0].String()
+	}
+	return union.String()
+}
+
+// specificity counts the distinct licenses of the expression that are on the SPDX License List, scaled so that
+// more licenses of any kind break ties.
+func specificity(expression *license.Expression) int {
+	if expression == nil {
+		return 0
+	}
+	const listedWeight = 1000
+	score := 0
+	for _, leaf := range expression.Leaves() {
+		score++
+		if license.Listed(leaf.License) {
+			score += listedWeight
+		}
+	}
+	return score
+}
+
+// canonicalExpression returns the expression with canonical identifiers and sorted, distinct operands, so that
+// equivalent expressions have the same string.
+func canonicalExpression(expression *license.Expression) *license.Expression {
+	if expression.IsLeaf() {
+		return &license.Expression{
+			License:   license.CanonicalID(expression.License),
+			Exception: expression.Exception,
+		}
+	}
+	canonical := &license.Expression{Operator: expression.Operator}
+	seen := map[string]bool{}
+	for _, operand := range expression.Operands {
+		operand = canonicalExpression(operand)
+		if key := operand.String(); !seen[key] {
+			seen[key] = true
+			canonical.Operands = append(canonical.Operands, operand)
+		}
+	}
+	slices.SortFunc(canonical.Operands, func(a, b *license.Expression) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	if len(canonical.Operands) == 1 {
+		return canonical.Operands[0]
+	}
+	return canonical
+}
@@ -0,0 +1,191 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestConsensus_Resolve tests the strategies and the disagreements they report.
+func TestConsensus_Resolve(t *testing.T) {
+	t.Parallel()
+
+	errUnavailable := errors.New("service unavailable")
+
+	tests := []struct {
+		name      string
+		strategy  provider.Strategy
+		answers   []provider.Answer
+		errs      map[string]error
+		onError   provider.ErrorPolicy
+		want      provider.Resolution
+		disagree  bool
+		wantErrIs error
+	}{
+		{
+			name: "agreement",
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT AND GPL-2.0"},
+				{Provider: "clearlydefined", License: "GPL-2.0-only AND mit"},
+			},
+			want: provider.Resolution{License: "MIT AND GPL-2.0", Provider: "ecosystems,clearlydefined"},
+		},
+		{
+			name: "majority",
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT"},
+				{Provider: "clearlydefined", License: "MIT AND BSD-3-Clause"},
+				{Provider: "depsdev", License: "BSD-3-Clause AND MIT"},
+			},
+			want:     provider.Resolution{License: "MIT AND BSD-3-Clause", Provider: "clearlydefined,depsdev"},
+			disagree: true,
+		},
+		{
+			name:     "majority tie",
+			strategy: provider.StrategyMajority,
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT"},
+				{Provider: "clearlydefined", License: "MIT AND BSD-3-Clause"},
+			},
+			want:     provider.Resolution{License: "MIT", Provider: "ecosystems"},
+			disagree: true,
+		},
+		{
+			name:     "union",
+			strategy: provider.StrategyUnion,
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT"},
+				{Provider: "clearlydefined", License: "MIT AND BSD-3-Clause"},
+				{Provider: "depsdev", License: "Apache-2.0 OR MIT"},
+			},
+			want: provider.Resolution{
+				License:  "MIT AND BSD-3-Clause AND (Apache-2.0 OR MIT)",
+				Provider: "ecosystems,clearlydefined,depsdev",
+			},
+			disagree: true,
+		},
+		{
+			name:     "most specific",
+			strategy: provider.StrategyMostSpecific,
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "LicenseRef-custom AND LicenseRef-other"},
+				{Provider: "clearlydefined", License: "MIT"},
+				{Provider: "depsdev", License: "MIT AND BSD-3-Clause"},
+			},
+			want:     provider.Resolution{License: "MIT AND BSD-3-Clause", Provider: "depsdev"},
+			disagree: true,
+		},
+		{
+			name:     "most specific listed without text",
+			strategy: provider.StrategyMostSpecific,
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "LicenseRef-custom AND LicenseRef-other"},
+				{Provider: "clearlydefined", License: "Unicode-3.0"},
+			},
+			want:     provider.Resolution{License: "Unicode-3.0", Provider: "clearlydefined"},
+			disagree: true,
+		},
+		{
+			name: "not found abstains",
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT"},
+				{Provider: "clearlydefined"},
+			},
+			errs: map[string]error{"clearlydefined": provider.ErrLicenseNotFound},
+			want: provider.Resolution{License: "MIT", Provider: "ecosystems"},
+		},
+		{
+			name: "error stops",
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT"},
+				{Provider: "clearlydefined"},
+			},
+			errs:      map[string]error{"clearlydefined": errUnavailable},
+			wantErrIs: errUnavailable,
+		},
+		{
+			name: "error continues",
+			answers: []provider.Answer{
+				{Provider: "ecosystems", License: "MIT"},
+				{Provider: "clearlydefined"},
+			},
+			errs:    map[string]error{"clearlydefined": errUnavailable},
+			onError: provider.ErrorPolicyContinue,
+			want:    provider.Resolution{License: "MIT", Provider: "ecosystems"},
+		},
+		{
+			name: "no answers",
+			answers: []provider.Answer{
+				{Provider: "ecosystems"},
+				{Provider: "clearlydefined"},
+			},
+			errs: map[string]error{
+				"ecosystems":     provider.ErrLicenseNotFound,
+				"clearlydefined": provider.ErrBlocked,
+			},
+			wantErrIs: provider.ErrBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			links := make([]provider.Link, len(tt.answers))
+			var disagreement []provider.Answer
+			for i, answer := range tt.answers {
+				links[i] = provider.Link{
+					Name:     answer.Provider,
+					Provider: &mockProvider{license: answer.License, err: tt.errs[answer.Provider]},
+					OnError:  tt.onError,
+				}
+				if answer.License != "" && tt.errs[answer.Provider] == nil {
+					disagreement = append(disagreement, answer)
+				}
+			}
+			want := tt.want
+			if tt.disagree {
+				want.Disagreement = disagreement
+			}
+
+			got, err := provider.NewConsensus(links, tt.strategy).Resolve(context.Background(), "pkg:npm/lodash@4.17.21")
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Resolve() error = %v, want %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Resolve() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestConsensus_Resolve_Types tests that providers are only asked for the purl types they are routed to.
+func TestConsensus_Resolve_Types(t *testing.T) {
+	t.Parallel()
+
+	golang := &mockProvider{license: "BSD-3-Clause"}
+	consensus := provider.NewConsensus([]provider.Link{
+		{Name: "npm", Provider: &mockProvider{license: "MIT"}, Types: []string{"npm"}},
+		{Name: "golang", Provider: golang, Types: []string{"golang"}},
+	}, provider.StrategyUnion)
+
+	got, err := consensus.Get(context.Background(), "pkg:npm/lodash@4.17.21")
+	if err != nil || got != "MIT" {
+		t.Errorf("Get() = %q, %v, want %q", got, err, "MIT")
+	}
+	if golang.getCalls != 0 {
+		t.Errorf("golang provider called %d times, want 0", golang.getCalls)
+	}
+	if _, err = consensus.Get(context.Background(), "pkg:cargo/serde@1.0.0"); !errors.Is(err, provider.ErrLicenseNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, provider.ErrLicenseNotFound)
+	}
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// depsDevBaseURL is the base URL for the deps.dev API.
+	//
+	// See https://docs.deps.dev/api/v3/
+	depsDevBaseURL = "https://api.deps.dev"
+	// depsDevAPIPath is the API path for package systems.
+	depsDevAPIPath = "/v3/systems"
+	// depsDevNonStandard is the license deps.dev reports for licenses that are not SPDX expressions.
+	depsDevNonStandard = "non-standard"
+)
+
+// depsDevSystems maps purl types to deps.dev package systems.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var depsDevSystems = map[string]string{
+	"cargo":  "CARGO",
+	"golang": "GO",
+	"maven":  "MAVEN",
+	"npm":    "NPM",
+	"nuget":  "NUGET",
+	"pypi":   "PYPI",
+}
+
+// DepsDevClient is the client for the deps.dev API.
+type DepsDevClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+var _ Provider = (*DepsDevClient)(nil)
+
+// DepsDevOptions are the options for the DepsDevClient.
+type DepsDevOptions struct {
+	// BaseURL is the base URL for the deps.dev API.
+	// If empty, defaults to the public deps.dev API.
+	BaseURL string
+	// Client is the HTTP client to use for the deps.dev API.
+	// If nil, defaults to an HTTP client with timeout.
+	Client *http.Client
+}
+
+// NewDepsDevClient creates a new DepsDevClient.
+func NewDepsDevClient(opts DepsDevOptions) *DepsDevClient {
+	baseURL := depsDevBaseURL
+	if opts.BaseURL != "" {
+		baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
+	return &DepsDevClient{
+		baseURL: baseURL,
+		client:  client,
+	}
+}
+
+// depsDevVersion is a package version of the deps.dev API.
+type depsDevVersion struct {
+	Licenses       []string `json:"licenses"`
+	LicenseDetails []struct {
+		License string `json:"license"`
+		SPDX    string `json:"spdx"`
+	} `json:"licenseDetails"`
+}
+
+// Get gets the license of a package version from the deps.dev API.
+//
+// If the version has several licenses, they are combined with AND.
+func (c *DepsDevClient) Get(ctx context.Context, rawPurl string) (string, error) {
+	path, err := DepsDevPath(rawPurl)
+	if err != nil {
+		return "", err
+	}
+
+	var version depsDevVersion
+	if err = getJSON(ctx, c.client, c.baseURL+depsDevAPIPath+path, nil, &version); err != nil {
+		return "", err
+	}
+
+	// The details have the SPDX form of licenses that deps.dev could map
+	licenses := version.Licenses
+	if len(version.LicenseDetails) > 0 {
+		licenses = nil
+		for _, detail := range version.LicenseDetails {
+			licenses = append(licenses, detail.SPDX)
+		}
+	}
+
+	var found []string
+	for _, l := range licenses {
+		if l != "" && l != depsDevNonStandard && !slices.Contains(found, l) {
+			found = append(found, l)
+		}
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, rawPurl)
+	}
+	return license.Join(license.OperatorAnd, found), nil
+}
+
+// DepsDevPath returns the path of a package version in the deps.dev API, e.g.
+// "/MAVEN/packages/org.apache.commons%3Acommons-lang3/versions/3.12.0".
+//
+// Names are percent-encoded as a single path segment, so Maven names of the form "group:artifact", scoped npm
+// names and Go module paths keep their separators. deps.dev only has licenses of specific versions, so the purl
+// must have a version.
+func DepsDevPath(rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	system, ok := depsDevSystems[parsed.Type]
+	if !ok {
+		return "", fmt.Errorf("%w: purl type %q is not supported by deps.dev", ErrLicenseNotFound, parsed.Type)
+	}
+	if parsed.Version == "" {
+		return "", fmt.Errorf("%w: deps.dev requires a version: %s", ErrLicenseNotFound, rawPurl)
+	}
+
+	name := parsed.FullName()
+	if parsed.Type == "maven" && parsed.Namespace != "" {
+		name = parsed.Namespace + ":" + parsed.Name
+	}
+	return "/" + system + "/packages/" + escapeSegment(name) + "/versions/" + escapeSegment(parsed.Version), nil
+}
+
+// escapeSegment percent-encodes a string as a single URL path segment, including "/", ":" and "@".
+func escapeSegment(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,116 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestDepsDevPath tests converting purls to deps.dev API paths.
+func TestDepsDevPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		purl    string
+		want    string
+		wantErr bool
+	}{
+		{purl: "pkg:npm/lodash@4.17.21", want: "/NPM/packages/lodash/versions/4.17.21"},
+		{purl: "pkg:npm/%40colors/colors@1.5.0", want: "/NPM/packages/%40colors%2Fcolors/versions/1.5.0"},
+		{
+			purl: "pkg:maven/org.apache.commons/commons-lang3@3.12.0?type=jar",
+			want: "/MAVEN/packages/org.apache.commons%3Acommons-lang3/versions/3.12.0",
+		},
+		{purl: "pkg:golang/github.com/gorilla/mux@v1.8.0", want: "/GO/packages/github.com%2Fgorilla%2Fmux/versions/v1.8.0"},
+		{purl: "pkg:pypi/requests@2.28.0", want: "/PYPI/packages/requests/versions/2.28.0"},
+		{purl: "pkg:cargo/serde@1.0.0", want: "/CARGO/packages/serde/versions/1.0.0"},
+		{purl: "pkg:nuget/Newtonsoft.Json@13.0.1", want: "/NUGET/packages/Newtonsoft.Json/versions/13.0.1"},
+		{purl: "pkg:npm/lodash", wantErr: true},
+		{purl: "pkg:gem/rails@7.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := provider.DepsDevPath(tt.purl)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DepsDevPath(%q) error = %v, wantErr %v", tt.purl, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("DepsDevPath(%q) = %q, want %q", tt.purl, got, tt.want)
+		}
+	}
+}
+
+// TestDepsDevClient_Get tests getting licenses from a stub deps.dev API.
+func TestDepsDevClient_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		mockResponse string
+		want         string
+		wantErrIs    error
+	}{
+		{
+			name:         "single license",
+			mockResponse: `{"licenses": ["MIT"]}`,
+			want:         "MIT",
+		},
+		{
+			name:         "several licenses",
+			mockResponse: `{"licenses": ["Apache-2.0", "MIT OR BSD-3-Clause", "Apache-2.0"]}`,
+			want:         "Apache-2.0 AND (MIT OR BSD-3-Clause)",
+		},
+		{
+			name: "license details preferred",
+			mockResponse: `{"licenses": ["non-standard"],
+				"licenseDetails": [{"license": "Apache 2", "spdx": "Apache-2.0"}]}`,
+			want: "Apache-2.0",
+		},
+		{
+			name:         "non-standard license",
+			mockResponse: `{"licenses": ["non-standard"]}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+		{
+			name:         "no licenses",
+			mockResponse: `{"licenses": []}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				want := "/v3/systems/MAVEN/packages/org.example%3Alib/versions/1.0.0"
+				if r.URL.EscapedPath() != want {
+					t.Errorf("path = %q, want %q", r.URL.EscapedPath(), want)
+				}
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			t.Cleanup(server.Close)
+
+			client := provider.NewDepsDevClient(provider.DepsDevOptions{BaseURL: server.URL})
+
+			got, err := client.Get(context.Background(), "pkg:maven/org.example/lib@1.0.0")
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// dpkgStatus is the database of the packages installed by dpkg.
+	dpkgStatus = "var/lib/dpkg/status"
+	// dpkgStatusDir holds one status file per package in distroless images.
+	dpkgStatusDir = "var/lib/dpkg/status.d"
+	// debDocDir holds the copyright files of Debian packages.
+	debDocDir = "usr/share/doc"
+	// apkInstalled is the database of the packages installed by apk.
+	apkInstalled = "lib/apk/db/installed"
+	// licenseRefPrefix is the prefix of license identifiers that are not on the SPDX License List.
+	licenseRefPrefix = "LicenseRef-"
+)
+
+// debCommonLicenses maps the license files in /usr/share/common-licenses to SPDX license identifiers. The
+// unversioned GPL, LGPL and GFDL files are missing, since they point to the latest version.
+//
+//nolint:gochecknoglobals // constant lookup table
+var debCommonLicenses = map[string]string{
+	"Apache-2.0": "Apache-2.0",
+	"Artistic":   "Artistic-1.0-Perl",
+	"BSD":        "BSD-3-Clause",
+	"CC0-1.0":    "CC0-1.0",
+	"GFDL-1.2":   "GFDL-1.2-only",
+	"GFDL-1.3":   "GFDL-1.3-only",
+	"GPL-1":      "GPL-1.0-only",
+	"GPL-2":      "GPL-2.0-only",
+	"GPL-3":      "GPL-3.0-only",
+	"LGPL-2":     "LGPL-2.0-only",
+	"LGPL-2.1":   "LGPL-2.1-only",
+	"LGPL-3":     "LGPL-3.0-only",
+	"MPL-1.1":    "MPL-1.1",
+	"MPL-2.0":    "MPL-2.0",
+}
+
+// distroNames maps lower case license names used by distributions, but not by package registries, to SPDX
+// license identifiers: Debian short names and Fedora's legacy abbreviations.
+//
+//nolint:gochecknoglobals // constant lookup table
+var distroNames = map[string]string{
+	"artistic":  "Artistic-1.0-Perl",
+	"boost":     "BSL-1.0",
+	"gfdl-1.2":  "GFDL-1.2-only",
+	"gfdl-1.3":  "GFDL-1.3-only",
+	"gpl+":      "GPL-1.0-or-later",
+	"gpl-1":     "GPL-1.0-only",
+	"gplv1":     "GPL-1.0-only",
+	"python":    "Python-2.0",
+	"sleepycat": "Sleepycat",
+	"vim":       "Vim",
+}
+
+// debCommonLicense matches references to the license files of Debian systems.
+//
+//nolint:gochecknoglobals // compiled once
+var debCommonLicense = regexp.MustCompile(`/usr/share/common-licenses/([A-Za-z0-9.-]*[A-Za-z0-9])`)
+
+// distroLicenseOperator matches the operators and parentheses of the license fields of distributions. Commas
+// separate licenses that all apply in Debian copyright files.
+//
+//nolint:gochecknoglobals // compiled once
+var distroLicenseOperator = regexp.MustCompile(`(?i)\s*,\s*(?:and\s+)?|\s+(?:and|or)\s+|\s*[()]\s*`)
+
+// distroLicenseWith matches the exception operator in a license name.
+//
+//nolint:gochecknoglobals // compiled once
+var distroLicenseWith = regexp.MustCompile(`(?i)\s+with\s+`)
+
+// spdxVersionedID matches license identifiers in the form of the SPDX License List that end with a version,
+// e.g. "bzip2-1.0.6" or "PHP-3.01".
+//
+//nolint:gochecknoglobals // compiled once
+var spdxVersionedID = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9.-]*-[0-9][A-Za-z0-9.]*$`)
+
+// spdxException matches exception identifiers in the form of the SPDX License List, e.g.
+// "Classpath-exception-2.0" or "Linux-syscall-note".
+//
+//nolint:gochecknoglobals // compiled once
+var spdxException = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9.-]*-(exception|note)(-[0-9][0-9.]*)?$`)
+
+// licenseRefInvalid matches the characters that are not allowed in LicenseRefs.
+//
+//nolint:gochecknoglobals // compiled once
+var licenseRefInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Distro gets licenses of the packages installed by the package manager of a Linux distribution, from its root
+// file system, such as an extracted container image: the copyright files of Debian packages, the License tag
+// of rpm headers in the rpm database, and the license field of the apk database.
+//
+// Licenses that are not on the SPDX License List become LicenseRefs, e.g. "LicenseRef-public-domain".
+type Distro struct {
+	root func() (*os.Root, error)
+
+	debOnce     sync.Once
+	debVersions map[string]string
+
+	rpmOnce     sync.Once
+	rpmPackages map[string][]rpmPackage
+	rpmErr      error
+
+	apkOnce     sync.Once
+	apkPackages map[string][]apkPackage
+}
+
+var _ Provider = (*Distro)(nil)
+
+// DistroOptions are the options for the Distro provider.
+type DistroOptions struct {
+	// Root is the root directory of the file system, e.g. an extracted container image or "/".
+	Root string
+}
+
+// apkPackage is a package in the apk database.
+type apkPackage struct {
+	version string
+	license string
+}
+
+// NewDistro creates a new Distro provider. The root directory is opened on first use.
+func NewDistro(opts DistroOptions) *Distro {
+	return &Distro{
+		root: sync.OnceValues(func() (*os.Root, error) {
+			return os.OpenRoot(opts.Root)
+		}),
+	}
+}
+
+// Get gets the license of an installed deb, rpm or apk package. Purls without version match any installed
+// version.
+func (d *Distro) Get(_ context.Context, rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	root, err := d.root()
+	if err != nil {
+		return "", fmt.Errorf("failed to open root file system: %w", err)
+	}
+
+	var l string
+	switch parsed.Type {
+	case "deb":
+		l, err = d.deb(root, parsed)
+	case "rpm":
+		l, err = d.rpm(root, parsed)
+	case "apk":
+		l = d.apk(root, parsed)
+	default:
+		return "", fmt.Errorf("%w: purl type %q is not supported by distribution package managers",
+			ErrLicenseNotFound, parsed.Type)
+	}
+	if err != nil {
+		return "", err
+	}
+	if l == "" {
+		return "", fmt.Errorf("%w: %s is not installed or has no license", ErrLicenseNotFound, rawPurl)
+	}
+	return l, nil
+}
+
+// deb returns the license of a Debian package from its copyright file.
+func (d *Distro) deb(root *os.Root, parsed purl.PURL) (string, error) {
+	d.debOnce.Do(func() {
+		d.debVersions = readDpkgStatus(root)
+	})
+	if version, ok := d.debVersions[parsed.Name]; ok && !distroVersionMatch(parsed.Version, version) {
+		return "", nil
+	}
+
+	data, err := root.ReadFile(path.Join(debDocDir, parsed.Name, "copyright"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read copyright file of %s: %w", parsed.Name, err)
+	}
+	return debCopyrightLicense(string(data)), nil
+}
+
+// readDpkgStatus returns the versions of the installed Debian packages, by package name.
+func readDpkgStatus(root *os.Root) map[string]string {
+	var paragraphs []map[string]string
+	if data, err := root.ReadFile(dpkgStatus); err == nil {
+		paragraphs = parseControl(string(data))
+	}
+	if entries, err := fs.ReadDir(root.FS(), dpkgStatusDir); err == nil {
+		for _, entry := range entries {
+			// The directory also holds "<package>.md5sums" files
+			if entry.IsDir() || strings.Contains(entry.Name(), ".") {
+				continue
+			}
+			if data, err := root.ReadFile(path.Join(dpkgStatusDir, entry.Name())); err == nil {
+				paragraphs = append(paragraphs, parseControl(string(data))...)
+			}
+		}
+	}
+
+	versions := make(map[string]string)
+	for _, paragraph := range paragraphs {
+		status, ok := paragraph["status"]
+		if ok && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		if name := paragraph["package"]; name != "" {
+			versions[name] = paragraph["version"]
+		}
+	}
+	return versions
+}
+
+// parseControl parses the paragraphs of a Debian control file, such as the dpkg status database or a
+// machine-readable copyright file. Field names are lower case; continuation lines are joined with newlines.
+func parseControl(text string) []map[string]string {
+	var (
+		paragraphs []map[string]string
+		paragraph  map[string]string
+		field      string
+	)
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			paragraph = nil
+		case line[0] == ' ' || line[0] == '\t':
+			if paragraph != nil && field != "" {
+				paragraph[field] += "\n" + strings.TrimSpace(line)
+			}
+		case line[0] == '#':
+			// Comment
+		default:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			if paragraph == nil {
+				paragraph = make(map[string]string)
+				paragraphs = append(paragraphs, paragraph)
+			}
+			field = strings.ToLower(strings.TrimSpace(name))
+			paragraph[field] = strings.TrimSpace(value)
+		}
+	}
+	return paragraphs
+}
+
+// debCopyrightLicense returns the license of a Debian copyright file, or "" if it has none.
+//
+// Machine-readable copyright files (DEP-5) declare the license of each set of files; the licenses are
+// combined with AND. Otherwise the text is classified and references to the license files in
+// /usr/share/common-licenses are resolved.
+//
+// See https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+func debCopyrightLicense(text string) string {
+	paragraphs := parseControl(text)
+	if len(paragraphs) > 0 && (strings.Contains(paragraphs[0]["format"], "copyright-format") ||
+		strings.Contains(paragraphs[0]["format"], "dep5")) {
+		var licenses []string
+		for _, paragraph := range paragraphs {
+			if _, ok := paragraph["files"]; !ok {
+				continue
+			}
+			// The first line is the license name, the other lines its text
+			name, _, _ := strings.Cut(paragraph["license"], "\n")
+			if l := distroLicense(name); l != "" && !slices.Contains(licenses, l) {
+				licenses = append(licenses, l)
+			}
+		}
+		// The header paragraph may summarize the license when no files are declared
+		if len(licenses) == 0 {
+			name, _, _ := strings.Cut(paragraphs[0]["license"], "\n")
+			return distroLicense(name)
+		}
+		slices.Sort(licenses)
+		return license.Join(license.OperatorAnd, licenses)
+	}
+
+	var licenses []string
+	for _, match := range license.Classify(text) {
+		licenses = append(licenses, match.ID)
+	}
+	laterVersions := strings.Contains(strings.ToLower(text), "any later version")
+	for _, match := range debCommonLicense.FindAllStringSubmatch(text, -1) {
+		id, ok := debCommonLicenses[match[1]]
+		if !ok {
+			continue
+		}
+		if base, only := strings.CutSuffix(id, "-only"); only && laterVersions {
+			id = base + "-or-later"
+		}
+		licenses = append(licenses, id)
+	}
+	slices.Sort(licenses)
+	return license.Join(license.OperatorAnd, slices.Compact(licenses))
+}
+
+// rpm returns the license of an rpm package from the License tag of its header in the rpm database.
+func (d *Distro) rpm(root *os.Root, parsed purl.PURL) (string, error) {
+	d.rpmOnce.Do(func() {
+		d.rpmPackages, d.rpmErr = readRPMDatabase(root)
+	})
+	if d.rpmErr != nil {
+		return "", d.rpmErr
+	}
+
+	for _, pkg := range d.rpmPackages[parsed.Name] {
+		installed := pkg.version + "-" + pkg.release
+		if pkg.epoch != "" {
+			installed = pkg.epoch + ":" + installed
+		}
+		if distroVersionMatch(parsed.Version, installed) || distroVersionMatch(parsed.Version, pkg.version) {
+			return distroLicense(pkg.license), nil
+		}
+	}
+	return "", nil
+}
+
+// apk returns the license of an Alpine package from the apk database.
+func (d *Distro) apk(root *os.Root, parsed purl.PURL) string {
+	d.apkOnce.Do(func() {
+		d.apkPackages = readAPKInstalled(root)
+	})
+
+	for _, pkg := range d.apkPackages[parsed.Name] {
+		if distroVersionMatch(parsed.Version, pkg.version) {
+			return distroLicense(pkg.license)
+		}
+	}
+	return ""
+}
+
+// readAPKInstalled returns the packages in the apk database, by package name.
+//
+// See https://wiki.alpinelinux.org/wiki/Apk_spec
+func readAPKInstalled(root *os.Root) map[string][]apkPackage {
+	packages := make(map[string][]apkPackage)
+	data, err := root.ReadFile(apkInstalled)
+	if err != nil {
+		return packages
+	}
+
+	// Packages are separated by blank lines; fields are "<letter>:<value>"
+	var name string
+	var pkg apkPackage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+		if !more || line == "" {
+			if name != "" {
+				packages[name] = append(packages[name], pkg)
+			}
+			name, pkg = "", apkPackage{}
+			if !more {
+				return packages
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "P":
+			name = value
+		case "V":
+			pkg.version = value
+		case "L":
+			pkg.license = value
+		}
+	}
+}
+
+// distroVersionMatch reports whether an installed version matches the version of a purl. An empty purl version
+// matches any version, and the epoch, e.g. "1:" in "1:2.3-4", may be omitted from either.
+func distroVersionMatch(want, installed string) bool {
+	if want == "" || want == installed {
+		return true
+	}
+	_, wantVersion, wantEpoch := strings.Cut(want, ":")
+	_, installedVersion, installedEpoch := strings.Cut(installed, ":")
+	switch {
+	case wantEpoch && !installedEpoch:
+		return wantVersion == installed
+	case installedEpoch && !wantEpoch:
+		return want == installedVersion
+	default:
+		return false
+	}
+}
+
+// distroLicense maps the license field of a distribution package to an SPDX expression, or "" if the field is
+// empty or invalid. The field is an SPDX expression, a Debian copyright license specification such as
+// "GPL-2+ or Artistic", or a legacy Fedora expression such as "ASL 2.0 and GPLv2+".
+func distroLicense(field string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range distroLicenseOperator.FindAllStringIndex(field, -1) {
+		b.WriteString(distroLicenseID(field[last:loc[0]]))
+		switch operator := strings.ToLower(strings.TrimSpace(field[loc[0]:loc[1]])); {
+		case operator == "(" || operator == ")":
+			b.WriteString(" " + operator + " ")
+		case operator == "or":
+			b.WriteString(" OR ")
+		default:
+			b.WriteString(" AND ")
+		}
+		last = loc[1]
+	}
+	b.WriteString(distroLicenseID(field[last:]))
+
+	expression, err := license.Parse(b.String())
+	if err != nil {
+		return ""
+	}
+	return expression.Normalize().String()
+}
+
+// distroLicenseID maps a license name of a distribution package, optionally with an exception, to an SPDX
+// license identifier. Unknown names become LicenseRefs.
+func distroLicenseID(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	if base, exception, ok := cutWith(name); ok && spdxException.MatchString(exception) {
+		if id, ok := distroKnownID(base); ok {
+			return id + " WITH " + exception
+		}
+	}
+	if id, ok := distroKnownID(name); ok {
+		return id
+	}
+	if spdxVersionedID.MatchString(name) {
+		return name
+	}
+	return licenseRefPrefix + strings.Trim(licenseRefInvalid.ReplaceAllString(name, "-"), "-")
+}
+
+// distroKnownID maps a known license name of a distribution package to an SPDX license identifier. A "+"
+// suffix means the version or any later version.
+func distroKnownID(name string) (string, bool) {
+	if id, ok := distroNames[strings.ToLower(name)]; ok {
+		return id, true
+	}
+	if id, ok := license.FromName(name); ok {
+		return id, true
+	}
+	base, later := strings.CutSuffix(name, "+")
+	if !later {
+		return "", false
+	}
+	id, ok := distroNames[strings.ToLower(base)]
+	if !ok {
+		if id, ok = license.FromName(base); !ok {
+			return "", false
+		}
+	}
+	if only, ok := strings.CutSuffix(id, "-only"); ok {
+		return only + "-or-later", true
+	}
+	if strings.HasSuffix(id, "-or-later") {
+		return id, true
+	}
+	return license.NormalizeID(id + "+"), true
+}
+
+// cutWith splits a license name with an exception, e.g. "GPL-2.0-only WITH Classpath-exception-2.0".
+func cutWith(name string) (string, string, bool) {
+	loc := distroLicenseWith.FindStringIndex(name)
+	if loc == nil {
+		return name, "", false
+	}
+	return name[:loc[0]], name[loc[1]:], true
+}
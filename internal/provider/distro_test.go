@@ -0,0 +1,294 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// rpmTestHeader returns an rpm header with the string tags, by tag number.
+func rpmTestHeader(tags map[uint32]string) []byte {
+	var index, data []byte
+	for tag, value := range tags {
+		index = binary.BigEndian.AppendUint32(index, tag)
+		index = binary.BigEndian.AppendUint32(index, 6) // string
+		index = binary.BigEndian.AppendUint32(index, uint32(len(data)))
+		index = binary.BigEndian.AppendUint32(index, 1)
+		data = append(append(data, value...), 0)
+	}
+	header := binary.BigEndian.AppendUint32(nil, uint32(len(tags)))
+	header = binary.BigEndian.AppendUint32(header, uint32(len(data)))
+	return append(append(header, index...), data...)
+}
+
+// bdbTestDatabase returns a little-endian BerkeleyDB hash database with the values on a single hash page.
+// Values that do not fit next to the others are stored on overflow pages.
+func bdbTestDatabase(values [][]byte) []byte {
+	const (
+		pageSize   = 512
+		headerSize = 26
+	)
+	le := binary.LittleEndian
+
+	meta := make([]byte, pageSize)
+	le.PutUint32(meta[12:], 0x061561)
+	le.PutUint32(meta[20:], pageSize)
+	meta[25] = 8
+	hash := make([]byte, pageSize)
+	hash[25] = 13
+	pages := [][]byte{meta, hash}
+
+	end := pageSize
+	var index []int
+	put := func(item []byte) {
+		end -= len(item)
+		copy(hash[end:], item)
+		index = append(index, end)
+	}
+	for i, value := range values {
+		put(le.AppendUint32([]byte{1}, uint32(i+1)))
+		if len(value) < 64 {
+			put(append([]byte{1}, value...))
+			continue
+		}
+		item := make([]byte, 12)
+		item[0] = 3
+		le.PutUint32(item[4:], uint32(len(pages)))
+		le.PutUint32(item[8:], uint32(len(value)))
+		put(item)
+		for len(value) > 0 {
+			overflow := make([]byte, pageSize)
+			overflow[25] = 7
+			n := copy(overflow[headerSize:], value)
+			le.PutUint16(overflow[22:], uint16(n))
+			value = value[n:]
+			if len(value) > 0 {
+				le.PutUint32(overflow[16:], uint32(len(pages)+1))
+			}
+			pages = append(pages, overflow)
+		}
+	}
+	le.PutUint16(hash[20:], uint16(len(index)))
+	for i, offset := range index {
+		le.PutUint16(hash[headerSize+2*i:], uint16(offset))
+	}
+	le.PutUint32(meta[32:], uint32(len(pages)-1))
+
+	var db []byte
+	for _, page := range pages {
+		db = append(db, page...)
+	}
+	return db
+}
+
+// distroTestRoot returns a root file system with Debian, Alpine and rpm package databases.
+func distroTestRoot(t *testing.T) string {
+	t.Helper()
+
+	mit, _ := license.Text("MIT")
+	rpmdb, err := os.ReadFile(filepath.Join("testdata", "rpmdb.sqlite"))
+	if err != nil {
+		t.Fatalf("failed to read rpm database: %v", err)
+	}
+
+	root := t.TempDir()
+	writeFilesystemTestFiles(t, root, map[string]string{
+		"var/lib/dpkg/status": `Package: libc6
+Status: install ok installed
+Architecture: amd64
+Version: 2.36-9+deb12u4
+Description: GNU C Library: Shared libraries
+ Contains the standard libraries that are used by nearly all programs on
+ the system.
+
+Package: perl-base
+Status: install ok installed
+Version: 5.36.0-7+deb12u1
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0-1
+
+Package: zlib1g
+Status: install ok installed
+Version: 1:1.2.13.dfsg-1
+`,
+		"var/lib/dpkg/status.d/base-files":         "Package: base-files\nVersion: 12.4+deb12u5\n",
+		"var/lib/dpkg/status.d/base-files.md5sums": "d41d8cd98f00b204e9800998ecf8427e  etc/debian_version\n",
+		"usr/share/doc/libc6/copyright": `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: glibc
+
+Files: *
+Copyright: 1991-2023 Free Software Foundation, Inc.
+License: LGPL-2.1+
+
+Files: debian/*
+Copyright: 1998 Joel Klecker
+License: GPL-2+ or Artistic
+ This program is free software.
+
+Files: sunrpc/*
+Copyright: 2010 Oracle
+License: BSD-3-clause
+
+Files: timezone/*
+Copyright: none
+License: public-domain
+
+License: LGPL-2.1+
+ The text of the license.
+`,
+		"usr/share/doc/perl-base/copyright": "This is perl.\n\nOn Debian systems, the complete text of the GNU " +
+			"General Public License version 1 can be found in `/usr/share/common-licenses/GPL-1'.\n" +
+			"Perl is distributed under either version 1 of the GPL, or (at your option) any later version,\n" +
+			"or the Artistic License, see /usr/share/common-licenses/Artistic.\n",
+		"usr/share/doc/zlib1g/copyright":     "Copyright (C) 1995-2022 Jean-loup Gailly and Mark Adler\n\n" + mit,
+		"usr/share/doc/base-files/copyright": "Format: http://dep.debian.net/deps/dep5\nLicense: GPL-2+\n",
+		"usr/share/doc/removed/copyright":    "Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/\n",
+		"lib/apk/db/installed": `C:Q1abc=
+P:musl
+V:1.2.4_git20230717-r4
+A:x86_64
+L:MIT
+T:the musl c library (libc) implementation
+
+P:busybox
+V:1.36.1-r15
+L:GPL-2.0-only
+
+P:ca-certificates-bundle
+V:20240226-r0
+L:MPL-2.0 AND MIT
+
+P:libcrypto3
+V:3.1.4-r5
+L:Apache-2.0 WITH LLVM-exception OR custom
+`,
+		"var/lib/rpm/rpmdb.sqlite": string(rpmdb),
+	})
+	return root
+}
+
+// TestDistro_Get tests getting licenses of deb, apk and rpm packages from a root file system.
+func TestDistro_Get(t *testing.T) {
+	t.Parallel()
+
+	distro := provider.NewDistro(provider.DistroOptions{Root: distroTestRoot(t)})
+
+	tests := []struct {
+		purl      string
+		want      string
+		wantErrIs error
+	}{
+		{
+			purl: "pkg:deb/debian/libc6@2.36-9+deb12u4?arch=amd64&distro=debian-12",
+			want: "BSD-3-Clause AND (GPL-2.0-or-later OR Artistic-1.0-Perl) AND LGPL-2.1-or-later AND " +
+				"LicenseRef-public-domain",
+		},
+		{purl: "pkg:deb/debian/libc6", want: "BSD-3-Clause AND (GPL-2.0-or-later OR Artistic-1.0-Perl) AND " +
+			"LGPL-2.1-or-later AND LicenseRef-public-domain"},
+		{purl: "pkg:deb/debian/perl-base@5.36.0-7+deb12u1", want: "Artistic-1.0-Perl AND GPL-1.0-or-later"},
+		{purl: "pkg:deb/debian/zlib1g@1.2.13.dfsg-1", want: "MIT"},
+		{purl: "pkg:deb/debian/zlib1g@1:1.2.13.dfsg-1", want: "MIT"},
+		{purl: "pkg:deb/debian/base-files@12.4+deb12u5", want: "GPL-2.0-or-later"},
+		{purl: "pkg:deb/debian/libc6@2.31-13", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:deb/debian/removed@1.0-1", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:deb/debian/missing@1.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:apk/alpine/musl@1.2.4_git20230717-r4?arch=x86_64", want: "MIT"},
+		{purl: "pkg:apk/alpine/busybox", want: "GPL-2.0-only"},
+		{purl: "pkg:apk/alpine/ca-certificates-bundle@20240226-r0", want: "MPL-2.0 AND MIT"},
+		{purl: "pkg:apk/alpine/libcrypto3@3.1.4-r5", want: "Apache-2.0 WITH LLVM-exception OR LicenseRef-custom"},
+		{purl: "pkg:apk/alpine/musl@1.2.3-r0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:rpm/fedora/bash@5.2.26-1.fc40?arch=x86_64", want: "GPL-3.0-or-later"},
+		{purl: "pkg:rpm/fedora/openssl-libs@3.2.1-2.fc40?epoch=1", want: "Apache-2.0"},
+		{purl: "pkg:rpm/fedora/openssl-libs@1:3.2.1-2.fc40", want: "Apache-2.0"},
+		{purl: "pkg:rpm/fedora/openssl-libs@2:3.2.1-2.fc40", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:rpm/redhat/zlib@1.2.11", want: "Zlib AND BSL-1.0"},
+		{purl: "pkg:rpm/fedora/tzdata", want: "LicenseRef-Public-Domain"},
+		{purl: "pkg:rpm/fedora/filler39@1.0-39.fc40", want: "MIT"},
+		{purl: "pkg:rpm/fedora/bash@4.4.20-1.el8", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:npm/lodash@4.17.21", wantErrIs: provider.ErrLicenseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.ReplaceAll(tt.purl, "/", "_"), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := distro.Get(context.Background(), tt.purl)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get(%q) error = %v, want %v", tt.purl, err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get(%q) unexpected error = %v", tt.purl, err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.purl, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDistro_Get_BerkeleyDB tests getting licenses from an rpm database in the BerkeleyDB format.
+func TestDistro_Get_BerkeleyDB(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFilesystemTestFiles(t, root, map[string]string{
+		"var/lib/rpm/Packages": string(bdbTestDatabase([][]byte{
+			rpmTestHeader(map[uint32]string{1000: "bash", 1001: "4.4.20", 1002: "4.el8", 1014: "GPLv3+"}),
+			rpmTestHeader(map[uint32]string{
+				1000: "glibc", 1001: "2.28", 1002: "236.el8",
+				1014: "LGPLv2+ and LGPLv2+ with exceptions and GPLv2+",
+				1005: strings.Repeat("The GNU C library. ", 100),
+			}),
+		})),
+	})
+	distro := provider.NewDistro(provider.DistroOptions{Root: root})
+
+	tests := []struct {
+		purl string
+		want string
+	}{
+		{purl: "pkg:rpm/redhat/bash@4.4.20-4.el8", want: "GPL-3.0-or-later"},
+		{
+			purl: "pkg:rpm/redhat/glibc@2.28-236.el8",
+			want: "LGPL-2.0-or-later AND LicenseRef-LGPLv2-with-exceptions AND GPL-2.0-or-later",
+		},
+	}
+	for _, tt := range tests {
+		got, err := distro.Get(context.Background(), tt.purl)
+		if err != nil {
+			t.Errorf("Get(%q) unexpected error = %v", tt.purl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Get(%q) = %q, want %q", tt.purl, got, tt.want)
+		}
+	}
+}
+
+// TestDistro_Get_InvalidDatabase tests that a corrupt rpm database is reported.
+func TestDistro_Get_InvalidDatabase(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFilesystemTestFiles(t, root, map[string]string{
+		"var/lib/rpm/rpmdb.sqlite": "SQLite format 3\x00" + strings.Repeat("\x00", 200),
+	})
+	distro := provider.NewDistro(provider.DistroOptions{Root: root})
+
+	_, err := distro.Get(context.Background(), "pkg:rpm/fedora/bash@5.2.26-1.fc40")
+	if !errors.Is(err, provider.ErrInvalidResponse) {
+		t.Errorf("Get() error = %v, want %v", err, provider.ErrInvalidResponse)
+	}
+}
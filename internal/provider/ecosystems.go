@@ -2,14 +2,17 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/boringbin/sbomlicense/internal/version"
+	"github.com/boringbin/sbomlicense/internal/purl"
 )
 
 const (
@@ -19,6 +22,10 @@ const (
 	ecosystemsBaseURL = "https://packages.ecosyste.ms"
 	// ecosystemsAPIPath is the API path for package lookup.
 	ecosystemsAPIPath = "/api/v1/packages/lookup"
+	// ecosystemsBulkAPIPath is the API path for looking up many packages at once.
+	ecosystemsBulkAPIPath = "/api/v1/packages/bulk_lookup"
+	// ecosystemsBatchSize is the maximum number of purls of a bulk lookup.
+	ecosystemsBatchSize = 100
 	// defaultHTTPTimeout is the default timeout for HTTP requests.
 	defaultHTTPTimeout = 30 * time.Second
 )
@@ -28,9 +35,13 @@ type Client struct {
 	baseURL string
 	client  *http.Client
 	email   string
+	retry   RetryOptions
+
+	// noBulkLookup is set once the API turned out to have no bulk lookup.
+	noBulkLookup atomic.Bool
 }
 
-var _ Provider = (*Client)(nil)
+var _ BatchProvider = (*Client)(nil)
 
 // ClientOptions are the options for the Client.
 type ClientOptions struct {
@@ -43,6 +54,12 @@ type ClientOptions struct {
 	// Email is the email address for the polite pool.
 	// If empty, requests will not include polite pool identification.
 	Email string
+	// Retry configures retries of rate limited requests and requests to an unavailable API.
+	// If zero, requests are retried 3 times with exponential backoff.
+	Retry RetryOptions
+	// RateLimiter limits the requests to the API. Share one limiter between all clients of a process.
+	// If nil, requests are not limited.
+	RateLimiter *RateLimiter
 }
 
 // NewClient creates a new Client.
@@ -62,63 +79,130 @@ func NewClient(opts ClientOptions) *Client {
 
 	return &Client{
 		baseURL: baseURL,
-		client:  client,
+		client:  limitClient(client, opts.RateLimiter),
 		email:   opts.Email,
+		retry:   opts.Retry,
 	}
 }
 
 // ecosystemsPackagesLookupResponse is the response from the Ecosystems API.
 type ecosystemsPackagesLookupResponse struct {
+	Purl               string   `json:"purl"`
 	NormalizedLicenses []string `json:"normalized_licenses"`
 }
 
+// ecosystemsBulkLookupRequest is the request body of a bulk lookup.
+type ecosystemsBulkLookupRequest struct {
+	Purls []string `json:"purls"`
+}
+
 // Get gets the license for a package from the Ecosystems API.
+//
+// Rate limited requests and requests to an unavailable API are retried, see RetryOptions.
 func (s *Client) Get(ctx context.Context, purl string) (string, error) {
 	apiURL := fmt.Sprintf("%s%s?purl=%s", s.baseURL, ecosystemsAPIPath, url.QueryEscape(purl))
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+
+	// Parse the response (it's an array)
+	var results []ecosystemsPackagesLookupResponse
+	err := retry(ctx, s.retry, func() error {
+		return getJSON(ctx, s.client, apiURL, s.header(), &results)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+		return "", err
 	}
 
-	// Set User-Agent header
-	userAgent := fmt.Sprintf("sbomlicense/%s", version.Get())
-	if s.email != "" {
-		// See https://ecosyste.ms/api
-		userAgent = fmt.Sprintf("sbomlicense/%s (mailto:%s)", version.Get(), s.email)
+	// Check if we got any results and if the first result has licenses
+	if len(results) == 0 || len(results[0].NormalizedLicenses) == 0 {
+		return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, purl)
 	}
-	req.Header.Set("User-Agent", userAgent)
 
-	response, err := s.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make HTTP request: %w", err)
+	// Return the first normalized license
+	return results[0].NormalizedLicenses[0], nil
+}
+
+// GetMany gets the licenses for packages from the Ecosystems API.
+//
+// The purls are grouped by purl type and looked up with the bulk lookup endpoint, in batches of 100. If the API
+// has no bulk lookup, the purls without a result are left out, to be looked up one by one with Get, and later
+// calls fail with errors.ErrUnsupported.
+func (s *Client) GetMany(ctx context.Context, purls []string) (map[string]BatchResult, error) {
+	if s.noBulkLookup.Load() {
+		return nil, fmt.Errorf("%w: API has no bulk lookup", errors.ErrUnsupported)
 	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		switch response.StatusCode {
-		case http.StatusNotFound:
-			return "", fmt.Errorf("%w: HTTP 404", ErrLicenseNotFound)
-		case http.StatusTooManyRequests:
-			return "", errors.New("rate limited by API: HTTP 429")
-		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
-			return "", fmt.Errorf("API service unavailable: HTTP %d", response.StatusCode)
-		default:
-			return "", fmt.Errorf("API error: HTTP %d", response.StatusCode)
+	results := make(map[string]BatchResult, len(purls))
+
+	// Group the purls by registry, so that each batch is answered by one registry
+	byType := map[string][]string{}
+	for _, rawPurl := range purls {
+		parsed, err := purl.Parse(rawPurl)
+		if err != nil {
+			results[rawPurl] = BatchResult{Err: err}
+			continue
 		}
+		byType[parsed.Type] = append(byType[parsed.Type], rawPurl)
 	}
 
-	// Parse the response (it's an array)
-	var results []ecosystemsPackagesLookupResponse
-	err = json.NewDecoder(response.Body).Decode(&results)
+	for _, typ := range slices.Sorted(maps.Keys(byType)) {
+		for batch := range slices.Chunk(byType[typ], ecosystemsBatchSize) {
+			err := s.getBulk(ctx, batch, results)
+			if errors.Is(err, ErrLicenseNotFound) || errors.Is(err, errors.ErrUnsupported) {
+				// The API has no bulk lookup, so leave the purls to be looked up one by one from now on
+				s.noBulkLookup.Store(true)
+				return results, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// getBulk looks up a batch of purls with the bulk lookup endpoint and adds their results.
+func (s *Client) getBulk(ctx context.Context, batch []string, results map[string]BatchResult) error {
+	apiURL := s.baseURL + ecosystemsBulkAPIPath
+
+	var packages []ecosystemsPackagesLookupResponse
+	err := retry(ctx, s.retry, func() error {
+		return postJSON(ctx, s.client, apiURL, s.header(), ecosystemsBulkLookupRequest{Purls: batch}, &packages)
+	})
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+		return err
 	}
 
-	// Check if we got any results and if the first result has licenses
-	if len(results) == 0 || len(results[0].NormalizedLicenses) == 0 {
-		return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, purl)
+	// Packages are returned without version, so match them by type, namespace and name
+	licenses := map[string]string{}
+	for _, pkg := range packages {
+		if key, ok := ecosystemsPackageKey(pkg.Purl); ok && len(pkg.NormalizedLicenses) > 0 {
+			licenses[key] = pkg.NormalizedLicenses[0]
+		}
 	}
+	for _, rawPurl := range batch {
+		key, _ := ecosystemsPackageKey(rawPurl)
+		if license, ok := licenses[key]; ok {
+			results[rawPurl] = BatchResult{License: license}
+			continue
+		}
+		results[rawPurl] = BatchResult{Err: fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, rawPurl)}
+	}
+	return nil
+}
 
-	// Return the first normalized license
-	return results[0].NormalizedLicenses[0], nil
+// header returns the header of requests to the Ecosystems API.
+func (s *Client) header() http.Header {
+	header := http.Header{}
+	if s.email != "" {
+		// See https://ecosyste.ms/api
+		header.Set("User-Agent", fmt.Sprintf("%s (mailto:%s)", userAgent(), s.email))
+	}
+	return header
+}
+
+// ecosystemsPackageKey returns the type, namespace and name of a purl, in lower case.
+func ecosystemsPackageKey(rawPurl string) (string, bool) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", false
+	}
+	return strings.ToLower(parsed.Type + "/" + parsed.FullName()), true
 }
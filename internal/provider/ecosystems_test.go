@@ -2,11 +2,13 @@ package provider_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -313,6 +315,7 @@ func TestClient_Get_HTTPErrors(t *testing.T) {
 			statusCode:      http.StatusTooManyRequests,
 			mockResponse:    `{"error": "too many requests"}`,
 			wantErr:         true,
+			wantErrIs:       provider.ErrRateLimited,
 			wantErrContains: "rate limited",
 		},
 		{
@@ -320,6 +323,7 @@ func TestClient_Get_HTTPErrors(t *testing.T) {
 			statusCode:      http.StatusBadGateway,
 			mockResponse:    `{"error": "bad gateway"}`,
 			wantErr:         true,
+			wantErrIs:       provider.ErrUnavailable,
 			wantErrContains: "service unavailable",
 		},
 		{
@@ -327,6 +331,7 @@ func TestClient_Get_HTTPErrors(t *testing.T) {
 			statusCode:      http.StatusServiceUnavailable,
 			mockResponse:    `{"error": "service unavailable"}`,
 			wantErr:         true,
+			wantErrIs:       provider.ErrUnavailable,
 			wantErrContains: "service unavailable",
 		},
 		{
@@ -334,6 +339,7 @@ func TestClient_Get_HTTPErrors(t *testing.T) {
 			statusCode:      http.StatusGatewayTimeout,
 			mockResponse:    `{"error": "gateway timeout"}`,
 			wantErr:         true,
+			wantErrIs:       provider.ErrUnavailable,
 			wantErrContains: "service unavailable",
 		},
 		{
@@ -357,6 +363,7 @@ func TestClient_Get_HTTPErrors(t *testing.T) {
 
 			client := provider.NewClient(provider.ClientOptions{
 				BaseURL: server.URL,
+				Retry:   provider.RetryOptions{MaxRetries: -1},
 			})
 
 			ctx := context.Background()
@@ -438,6 +445,7 @@ func TestClient_Get_ResponseErrors(t *testing.T) {
 
 			client := provider.NewClient(provider.ClientOptions{
 				BaseURL: server.URL,
+				Retry:   provider.RetryOptions{MaxRetries: -1},
 			})
 
 			ctx := context.Background()
@@ -494,11 +502,13 @@ func TestClient_Get_ContextCancellation(t *testing.T) {
 	}
 }
 
-// TestClient_Get_Timeout tests timeout handling.
+// TestClient_Get_Timeout tests that requests that time out are not retried.
 func TestClient_Get_Timeout(t *testing.T) {
 	t.Parallel()
 
+	var calls atomic.Int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
 		time.Sleep(200 * time.Millisecond)
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`[{"normalized_licenses": ["MIT"]}]`))
@@ -508,6 +518,7 @@ func TestClient_Get_Timeout(t *testing.T) {
 	client := provider.NewClient(provider.ClientOptions{
 		BaseURL: server.URL,
 		Client:  &http.Client{Timeout: 50 * time.Millisecond},
+		Retry:   provider.RetryOptions{InitialBackoff: time.Millisecond},
 	})
 
 	ctx := context.Background()
@@ -515,6 +526,12 @@ func TestClient_Get_Timeout(t *testing.T) {
 	if err == nil {
 		t.Error("Get() with timeout should return error")
 	}
+	if errors.Is(err, provider.ErrUnavailable) {
+		t.Errorf("Get() error = %v, want a timeout that is not retried", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server called %d times, want 1", calls.Load())
+	}
 }
 
 // mockCache is a mock implementation of cache.Cache for testing.
@@ -819,3 +836,255 @@ func TestGet_EmptyLicenseNotCached(t *testing.T) {
 		t.Error("empty license should not be stored in cache")
 	}
 }
+
+// TestClient_Get_Retry tests retries of rate limited and unavailable responses.
+func TestClient_Get_Retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		maxRetries int
+		timeout    time.Duration
+		wantCalls  int
+		wantErrIs  error
+	}{
+		{
+			name:       "rate limited then ok",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "0",
+			wantCalls:  2,
+		},
+		{
+			name:       "retry after date",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusOK},
+			retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT",
+			wantCalls:  2,
+		},
+		{
+			name:      "unavailable then ok",
+			statuses:  []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusOK},
+			wantCalls: 4,
+		},
+		{
+			name:       "retries exhausted",
+			statuses:   []int{http.StatusServiceUnavailable},
+			maxRetries: 2,
+			wantCalls:  3,
+			wantErrIs:  provider.ErrUnavailable,
+		},
+		{
+			name:       "retry disabled",
+			statuses:   []int{http.StatusTooManyRequests},
+			maxRetries: -1,
+			wantCalls:  1,
+			wantErrIs:  provider.ErrRateLimited,
+		},
+		{
+			name:      "not retryable",
+			statuses:  []int{http.StatusInternalServerError},
+			wantCalls: 1,
+		},
+		{
+			name:      "not found",
+			statuses:  []int{http.StatusNotFound},
+			wantCalls: 1,
+			wantErrIs: provider.ErrLicenseNotFound,
+		},
+		{
+			name:       "retry after exceeds maximum backoff",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "3600",
+			wantCalls:  1,
+			wantErrIs:  provider.ErrRateLimited,
+		},
+		{
+			name:       "retry after exceeds deadline",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "10",
+			timeout:    time.Second,
+			wantCalls:  1,
+			wantErrIs:  provider.ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				call := int(calls.Add(1))
+				status := tt.statuses[min(call, len(tt.statuses))-1]
+				if status != http.StatusOK {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(status)
+					return
+				}
+				_, _ = w.Write([]byte(`[{"normalized_licenses": ["MIT"]}]`))
+			}))
+			t.Cleanup(server.Close)
+
+			client := provider.NewClient(provider.ClientOptions{
+				BaseURL: server.URL,
+				Retry: provider.RetryOptions{
+					MaxRetries:     tt.maxRetries,
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     5 * time.Millisecond,
+				},
+			})
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				t.Cleanup(cancel)
+			}
+			got, err := client.Get(ctx, "pkg:npm/test@1.0.0")
+			if int(calls.Load()) != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", calls.Load(), tt.wantCalls)
+			}
+			switch {
+			case tt.wantErrIs != nil:
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantErrIs)
+				}
+			case tt.statuses[len(tt.statuses)-1] != http.StatusOK:
+				if err == nil {
+					t.Error("Get() expected error, got nil")
+				}
+			case err != nil || got != "MIT":
+				t.Errorf("Get() = %q, %v, want %q", got, err, "MIT")
+			}
+		})
+	}
+}
+
+// TestClient_GetMany tests batch lookups with the bulk lookup endpoint and without it.
+func TestClient_GetMany(t *testing.T) {
+	t.Parallel()
+
+	licenses := map[string][]string{
+		"pkg:npm/lodash":          {"MIT"},
+		"pkg:npm/%40types/node":   {"MIT"},
+		"pkg:pypi/requests":       {"Apache-2.0"},
+		"pkg:npm/no-license-info": {},
+	}
+	purls := []string{
+		"pkg:npm/lodash@4.17.21",
+		"pkg:npm/%40types/node@18.0.0",
+		"pkg:pypi/requests@2.31.0",
+		"pkg:npm/no-license-info@1.0.0",
+		"pkg:npm/unknown@1.0.0",
+	}
+	want := map[string]string{
+		"pkg:npm/lodash@4.17.21":       "MIT",
+		"pkg:npm/%40types/node@18.0.0": "MIT",
+		"pkg:pypi/requests@2.31.0":     "Apache-2.0",
+	}
+
+	tests := []struct {
+		name          string
+		bulkStatus    int
+		wantBulkCalls int32
+	}{
+		{name: "bulk lookup", bulkStatus: http.StatusOK, wantBulkCalls: 2},
+		{name: "no bulk lookup", bulkStatus: http.StatusNotFound, wantBulkCalls: 1},
+		{name: "bulk lookup not allowed", bulkStatus: http.StatusMethodNotAllowed, wantBulkCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var bulkCalls, getCalls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/packages/bulk_lookup" {
+					bulkCalls.Add(1)
+					if tt.bulkStatus != http.StatusOK {
+						w.WriteHeader(tt.bulkStatus)
+						return
+					}
+					var request struct {
+						Purls []string `json:"purls"`
+					}
+					if err := json.NewDecoder(r.Body).Decode(&request); err != nil || r.Method != http.MethodPost {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					packages := []map[string]any{}
+					for _, purl := range request.Purls {
+						name, _, _ := strings.Cut(purl, "@")
+						if license, ok := licenses[name]; ok {
+							packages = append(packages, map[string]any{"purl": name, "normalized_licenses": license})
+						}
+					}
+					_ = json.NewEncoder(w).Encode(packages)
+					return
+				}
+
+				getCalls.Add(1)
+				name, _, _ := strings.Cut(r.URL.Query().Get("purl"), "@")
+				license, ok := licenses[name]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode([]map[string]any{{"normalized_licenses": license}})
+			}))
+			t.Cleanup(server.Close)
+
+			client := provider.NewClient(provider.ClientOptions{BaseURL: server.URL})
+			results, err := client.GetMany(context.Background(), append(purls, "not a purl"))
+			if err != nil {
+				t.Fatalf("GetMany() error = %v", err)
+			}
+
+			for _, purl := range purls {
+				result, ok := results[purl]
+				if tt.bulkStatus != http.StatusOK {
+					// Left to be looked up one by one
+					if ok {
+						t.Errorf("GetMany()[%s] = %+v, want no result", purl, result)
+					}
+					continue
+				}
+				if !ok {
+					t.Errorf("GetMany() has no result for %s", purl)
+					continue
+				}
+				if license, found := want[purl]; found {
+					if result.Err != nil || result.License != license {
+						t.Errorf("GetMany()[%s] = %+v, want %s", purl, result, license)
+					}
+				} else if !errors.Is(result.Err, provider.ErrLicenseNotFound) {
+					t.Errorf("GetMany()[%s] = %+v, want ErrLicenseNotFound", purl, result)
+				}
+			}
+			if result := results["not a purl"]; result.Err == nil {
+				t.Errorf("GetMany() invalid purl = %+v, want error", result)
+			}
+			if bulkCalls.Load() != tt.wantBulkCalls || getCalls.Load() != 0 {
+				t.Errorf("bulk lookups = %d, lookups = %d, want %d and 0",
+					bulkCalls.Load(), getCalls.Load(), tt.wantBulkCalls)
+			}
+			if supported := provider.SupportsBatch(client); supported != (tt.bulkStatus == http.StatusOK) {
+				t.Errorf("SupportsBatch() = %v after bulk lookup with HTTP %d", supported, tt.bulkStatus)
+			}
+			if tt.bulkStatus == http.StatusOK {
+				return
+			}
+
+			// Once the API turned out to have no bulk lookup, it is not tried again
+			if _, err = client.GetMany(context.Background(), purls[:1]); !errors.Is(err, errors.ErrUnsupported) {
+				t.Errorf("GetMany() error = %v, want %v", err, errors.ErrUnsupported)
+			}
+			if bulkCalls.Load() != tt.wantBulkCalls {
+				t.Errorf("bulk lookups = %d after fallback, want %d", bulkCalls.Load(), tt.wantBulkCalls)
+			}
+		})
+	}
+}
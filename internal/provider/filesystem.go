@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// nodeModules is the directory npm installs packages into.
+	nodeModules = "node_modules"
+	// goVendorModules is the file listing the modules of a Go vendor directory.
+	goVendorModules = "modules.txt"
+	// maxLicenseFileSize is the maximum size of a license file that is read.
+	maxLicenseFileSize = 1 << 20
+)
+
+// licenseFileName matches the names of license files, such as "LICENSE", "LICENSE.md", "LICENSE-APACHE" and
+// "COPYING".
+//
+//nolint:gochecknoglobals // compiled once
+var licenseFileName = regexp.MustCompile(`(?i)^((un)?licen[cs]e|copying)([-._].*)?$`)
+
+// pypiNameSeparators matches the separators that are equivalent in Python package names.
+//
+//nolint:gochecknoglobals // compiled once
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Filesystem gets licenses from dependencies installed on disk, without network access: npm packages in
+// node_modules, Go modules in vendor directories and the module cache, Python distributions in site-packages
+// and Maven artifacts in a local repository.
+//
+// The license declared in the package manifest is preferred; otherwise the license files of the package are
+// classified.
+type Filesystem struct {
+	nodeModules  []string
+	vendor       []string
+	goModCache   string
+	sitePackages []string
+	maven        *MavenClient
+
+	npmOnce  sync.Once
+	npmIndex map[string][]string
+
+	vendorOnce    sync.Once
+	vendorModules map[string]map[string]string
+}
+
+var _ Provider = (*Filesystem)(nil)
+
+// FilesystemOptions are the options for the Filesystem provider. Empty options disable the layout.
+type FilesystemOptions struct {
+	// NodeModules are node_modules directories. Nested node_modules directories are searched as well.
+	NodeModules []string
+	// Vendor are Go vendor directories, as written by "go mod vendor".
+	Vendor []string
+	// GoModCache is the Go module cache, usually $GOMODCACHE or $GOPATH/pkg/mod.
+	GoModCache string
+	// SitePackages are Python site-packages directories with installed distributions.
+	SitePackages []string
+	// MavenRepository is a local Maven repository, usually ~/.m2/repository.
+	MavenRepository string
+}
+
+// NewFilesystem creates a new Filesystem provider.
+func NewFilesystem(opts FilesystemOptions) *Filesystem {
+	f := &Filesystem{
+		nodeModules:  opts.NodeModules,
+		vendor:       opts.Vendor,
+		goModCache:   opts.GoModCache,
+		sitePackages: opts.SitePackages,
+	}
+	if opts.MavenRepository != "" {
+		abs, err := filepath.Abs(opts.MavenRepository)
+		if err != nil {
+			abs = opts.MavenRepository
+		}
+		f.maven = NewMavenClient(MavenOptions{RepositoryURL: fileScheme + filepath.ToSlash(abs)})
+	}
+	return f
+}
+
+// Get gets the license of a package installed in one of the configured directories. Purls without version match
+// any installed version.
+func (f *Filesystem) Get(ctx context.Context, rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+
+	var l string
+	switch parsed.Type {
+	case "npm":
+		l, err = f.npm(parsed)
+	case "golang":
+		l, err = f.golang(parsed)
+	case "pypi":
+		l, err = f.pypi(parsed)
+	case "maven":
+		if f.maven == nil {
+			return "", fmt.Errorf("%w: no local Maven repository", ErrLicenseNotFound)
+		}
+		return f.maven.Get(ctx, rawPurl)
+	default:
+		return "", fmt.Errorf("%w: purl type %q is not supported on the file system",
+			ErrLicenseNotFound, parsed.Type)
+	}
+	if err != nil {
+		return "", err
+	}
+	if l == "" {
+		return "", fmt.Errorf("%w: %s is not installed or has no license", ErrLicenseNotFound, rawPurl)
+	}
+	return l, nil
+}
+
+// npm returns the license of an npm package in node_modules.
+func (f *Filesystem) npm(parsed purl.PURL) (string, error) {
+	f.npmOnce.Do(func() {
+		f.npmIndex = make(map[string][]string)
+		visited := make(map[string]bool)
+		for _, dir := range f.nodeModules {
+			indexNodeModules(dir, f.npmIndex, visited)
+		}
+	})
+
+	for _, dir := range f.npmIndex[parsed.FullName()] {
+		data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+		if err != nil {
+			continue
+		}
+		var pkg npmPackage
+		if err = json.Unmarshal(data, &pkg); err != nil {
+			continue
+		}
+		if parsed.Version != "" && pkg.Version != parsed.Version {
+			continue
+		}
+		if l := pkg.license(); l != "" {
+			return l, nil
+		}
+		return classifyDir(dir)
+	}
+	return "", nil
+}
+
+// indexNodeModules adds the package directories in a node_modules directory and its nested node_modules
+// directories to the index, by package name. Visited are the real paths of the package directories already
+// indexed, so that symlinked workspace packages that link to each other are indexed once.
+func indexNodeModules(dir string, index map[string][]string, visited map[string]bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if strings.HasPrefix(name, "@") {
+			scoped, err := os.ReadDir(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			for _, scopedEntry := range scoped {
+				indexNodePackage(filepath.Join(dir, name, scopedEntry.Name()), name+"/"+scopedEntry.Name(), index,
+					visited)
+			}
+			continue
+		}
+		indexNodePackage(filepath.Join(dir, name), name, index, visited)
+	}
+}
+
+// indexNodePackage adds a package directory to the index and indexes its nested node_modules directory.
+func indexNodePackage(dir, name string, index map[string][]string, visited map[string]bool) {
+	if info, err := os.Stat(filepath.Join(dir, "package.json")); err != nil || info.IsDir() {
+		return
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil || visited[realDir] {
+		return
+	}
+	visited[realDir] = true
+	index[name] = append(index[name], dir)
+	indexNodeModules(filepath.Join(dir, nodeModules), index, visited)
+}
+
+// golang returns the license of a Go module in a vendor directory or the module cache.
+func (f *Filesystem) golang(parsed purl.PURL) (string, error) {
+	f.vendorOnce.Do(func() {
+		f.vendorModules = make(map[string]map[string]string)
+		for _, dir := range f.vendor {
+			f.vendorModules[dir] = readVendorModules(filepath.Join(dir, goVendorModules))
+		}
+	})
+
+	importPath := parsed.FullName()
+	candidates := goModuleCandidates(importPath, parsed.Version)
+	for _, dir := range f.vendor {
+		for _, modulePath := range candidates {
+			version, ok := f.vendorModules[dir][modulePath]
+			if !ok || (parsed.Version != "" && version != parsed.Version) {
+				continue
+			}
+			return classifyDir(filepath.Join(dir, filepath.FromSlash(modulePath)))
+		}
+	}
+
+	if f.goModCache == "" || parsed.Version == "" {
+		return "", nil
+	}
+	for _, modulePath := range candidates {
+		name := goProxyEscape(modulePath) + "@" + goProxyEscape(parsed.Version)
+		dir := filepath.Join(f.goModCache, filepath.FromSlash(name))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return classifyDir(dir)
+		}
+	}
+	return "", nil
+}
+
+// readVendorModules reads the modules and versions listed in vendor/modules.txt, by module path.
+func readVendorModules(path string) map[string]string {
+	modules := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return modules
+	}
+	defer file.Close()
+
+	// Module lines look like "# golang.org/x/text v0.14.0", optionally followed by a replacement
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && fields[0] == "#" && strings.HasPrefix(fields[2], "v") {
+			modules[fields[1]] = fields[2]
+		}
+	}
+	return modules
+}
+
+// pypi returns the license of a Python distribution in site-packages.
+func (f *Filesystem) pypi(parsed purl.PURL) (string, error) {
+	name := pypiNormalizeName(parsed.Name)
+	for _, dir := range f.sitePackages {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			// Distributions are "<name>-<version>.dist-info", or "<name>-<version>[-pyX.Y].egg-info"
+			base, metadataFile := strings.TrimSuffix(entry.Name(), ".dist-info"), "METADATA"
+			if base == entry.Name() {
+				base, metadataFile = strings.TrimSuffix(entry.Name(), ".egg-info"), "PKG-INFO"
+				if base == entry.Name() {
+					continue
+				}
+			}
+			parts := strings.Split(base, "-")
+			if len(parts) < 2 || pypiNormalizeName(parts[0]) != name ||
+				(parsed.Version != "" && parts[1] != parsed.Version) {
+				continue
+			}
+
+			distInfo := filepath.Join(dir, entry.Name())
+			if l := readPythonMetadata(filepath.Join(distInfo, metadataFile)); l != "" {
+				return l, nil
+			}
+			// PEP 639 puts license files into a licenses directory, older tools into the metadata directory
+			if l, err := classifyDir(filepath.Join(distInfo, "licenses")); err != nil || l != "" {
+				return l, err
+			}
+			return classifyDir(distInfo)
+		}
+	}
+	return "", nil
+}
+
+// readPythonMetadata returns the license of a core metadata file, or "" if it has none.
+func readPythonMetadata(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	return pypiLicense(message.Header.Get("License-Expression"), message.Header["Classifier"],
+		message.Header.Get("License"))
+}
+
+// pypiNormalizeName normalizes a Python package name as in PEP 503.
+func pypiNormalizeName(name string) string {
+	return strings.ToLower(pypiNameSeparators.ReplaceAllString(name, "-"))
+}
+
+// classifyDir returns the licenses of the license files in a directory, or "" if it has none.
+func classifyDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var texts []string
+	for _, entry := range entries {
+		if entry.IsDir() || !licenseFileName.MatchString(entry.Name()) {
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxLicenseFileSize))
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		texts = append(texts, string(data))
+	}
+	return classifyLicenses(texts), nil
+}
+
+// classifyLicenses returns the licenses found in license texts, sorted and combined with AND, or "" if none is
+// found.
+func classifyLicenses(texts []string) string {
+	var licenses []string
+	for _, text := range texts {
+		for _, match := range license.Classify(text) {
+			if !slices.Contains(licenses, match.ID) {
+				licenses = append(licenses, match.ID)
+			}
+		}
+	}
+	slices.Sort(licenses)
+	return license.Join(license.OperatorAnd, licenses)
+}
@@ -0,0 +1,186 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// writeFilesystemTestFiles writes the files, by slash-separated path, into dir.
+func writeFilesystemTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
+
+// TestFilesystem_Get tests getting licenses from dependencies installed in a temporary directory.
+func TestFilesystem_Get(t *testing.T) {
+	t.Parallel()
+
+	mit, _ := license.Text("MIT")
+	apache, _ := license.Text("Apache-2.0")
+	isc, _ := license.Text("ISC")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		// npm
+		"node_modules/lodash/package.json": `{"name": "lodash", "version": "4.17.21", "license": "MIT"}`,
+		"node_modules/legacy/package.json": `{"name": "legacy", "version": "1.0.0",
+			"licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}]}`,
+		"node_modules/@babel/core/package.json": `{"name": "@babel/core", "version": "7.24.0", "license": "MIT"}`,
+		"node_modules/unlicensed/package.json":  `{"name": "unlicensed", "version": "1.0.0"}`,
+		"node_modules/unlicensed/LICENSE.md":    "ISC License\n\n" + isc,
+		"node_modules/lodash/node_modules/nested/package.json": `{"name": "nested", "license": "ISC",
+			"version": "2.0.0"}`,
+		"node_modules/.bin/package.json": `{"name": ".bin", "version": "1.0.0", "license": "MIT"}`,
+		// Go vendor directory and module cache
+		"vendor/modules.txt": "# github.com/example/mod v1.2.0\n## explicit; go 1.21\n" +
+			"github.com/example/mod\ngithub.com/example/mod/sub\n",
+		"vendor/github.com/example/mod/LICENSE":                       mit,
+		"gomodcache/github.com/!burnt!sushi/toml@v1.3.2/COPYING":      mit,
+		"gomodcache/github.com/example/dual/v2@v2.0.0/LICENSE-APACHE": apache,
+		"gomodcache/github.com/example/dual/v2@v2.0.0/LICENSE-MIT":    mit,
+		"gomodcache/github.com/example/dual/v2@v2.0.0/sub/LICENSE":    isc,
+		"gomodcache/github.com/example/unlicensed@v1.0.0/README.md":   "# unlicensed",
+		// Python site-packages
+		"site-packages/requests-2.31.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: requests\n" +
+			"Version: 2.31.0\nLicense: Apache 2.0\n" +
+			"Classifier: License :: OSI Approved :: Apache Software License\n\nLong description\n",
+		"site-packages/typing_extensions-4.9.0.dist-info/METADATA": "Metadata-Version: 2.4\n" +
+			"Name: typing_extensions\nVersion: 4.9.0\nLicense-Expression: PSF-2.0\n",
+		"site-packages/attrs-23.2.0.dist-info/METADATA":         "Metadata-Version: 2.1\nName: attrs\nVersion: 23.2.0\n",
+		"site-packages/attrs-23.2.0.dist-info/licenses/LICENSE": mit,
+		"site-packages/six-1.16.0-py3.12.egg-info/PKG-INFO": "Metadata-Version: 1.2\nName: six\n" +
+			"Version: 1.16.0\nLicense: MIT\n",
+		"site-packages/unknown-1.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: unknown\n" +
+			"License: UNKNOWN\n",
+		// Maven local repository
+		"m2/org/example/core/1.0.0/core-1.0.0.pom": `<project>
+			<licenses><license><name>The MIT License</name></license></licenses>
+		</project>`,
+	}
+	writeFilesystemTestFiles(t, dir, files)
+
+	fsProvider := provider.NewFilesystem(provider.FilesystemOptions{
+		NodeModules:     []string{filepath.Join(dir, "node_modules")},
+		Vendor:          []string{filepath.Join(dir, "vendor")},
+		GoModCache:      filepath.Join(dir, "gomodcache"),
+		SitePackages:    []string{filepath.Join(dir, "site-packages")},
+		MavenRepository: filepath.Join(dir, "m2"),
+	})
+
+	tests := []struct {
+		purl      string
+		want      string
+		wantErrIs error
+	}{
+		{purl: "pkg:npm/lodash@4.17.21", want: "MIT"},
+		{purl: "pkg:npm/lodash", want: "MIT"},
+		{purl: "pkg:npm/legacy@1.0.0", want: "MIT OR Apache-2.0"},
+		{purl: "pkg:npm/%40babel/core@7.24.0", want: "MIT"},
+		{purl: "pkg:npm/unlicensed@1.0.0", want: "ISC"},
+		{purl: "pkg:npm/nested@2.0.0", want: "ISC"},
+		{purl: "pkg:npm/lodash@4.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:npm/.bin@1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:golang/github.com/example/mod@v1.2.0", want: "MIT"},
+		{purl: "pkg:golang/github.com/example/mod/sub", want: "MIT"},
+		{purl: "pkg:golang/github.com/BurntSushi/toml@v1.3.2", want: "MIT"},
+		{purl: "pkg:golang/github.com/example/dual/v2@v2.0.0", want: "Apache-2.0 AND MIT"},
+		{purl: "pkg:golang/github.com/example/dual@v2.0.0", want: "Apache-2.0 AND MIT"},
+		{purl: "pkg:golang/github.com/example/unlicensed@v1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:golang/github.com/example/missing@v1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:pypi/requests@2.31.0", want: "Apache-2.0"},
+		{purl: "pkg:pypi/typing-extensions@4.9.0", want: "PSF-2.0"},
+		{purl: "pkg:pypi/attrs", want: "MIT"},
+		{purl: "pkg:pypi/six@1.16.0", want: "MIT"},
+		{purl: "pkg:pypi/unknown@1.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:pypi/requests@2.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:maven/org.example/core@1.0.0", want: "MIT"},
+		{purl: "pkg:maven/org.example/missing@1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:cargo/serde@1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.ReplaceAll(tt.purl, "/", "_"), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := fsProvider.Get(context.Background(), tt.purl)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get(%q) error = %v, want %v", tt.purl, err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get(%q) unexpected error = %v", tt.purl, err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.purl, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFilesystem_Get_NoDirectories tests that an empty provider finds nothing.
+func TestFilesystem_Get_NoDirectories(t *testing.T) {
+	t.Parallel()
+
+	fsProvider := provider.NewFilesystem(provider.FilesystemOptions{})
+	for _, purl := range []string{
+		"pkg:npm/lodash@4.17.21",
+		"pkg:golang/github.com/example/mod@v1.2.0",
+		"pkg:pypi/requests@2.31.0",
+		"pkg:maven/org.example/core@1.0.0",
+	} {
+		if _, err := fsProvider.Get(context.Background(), purl); !errors.Is(err, provider.ErrLicenseNotFound) {
+			t.Errorf("Get(%q) error = %v, want %v", purl, err, provider.ErrLicenseNotFound)
+		}
+	}
+}
+
+// TestFilesystem_Get_SymlinkCycle tests that workspace packages that link to each other are indexed once.
+func TestFilesystem_Get_SymlinkCycle(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFilesystemTestFiles(t, dir, map[string]string{
+		"packages/a/package.json": `{"name": "a", "version": "1.0.0", "license": "MIT"}`,
+		"packages/b/package.json": `{"name": "b", "version": "1.0.0", "license": "ISC"}`,
+	})
+	for _, link := range []struct{ from, to string }{
+		{from: "packages/a/node_modules/b", to: "../../b"},
+		{from: "packages/b/node_modules/a", to: "../../a"},
+		{from: "node_modules/a", to: "../packages/a"},
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(link.from))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.Symlink(filepath.FromSlash(link.to), path); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	fsProvider := provider.NewFilesystem(provider.FilesystemOptions{
+		NodeModules: []string{filepath.Join(dir, "node_modules")},
+	})
+	for purl, want := range map[string]string{"pkg:npm/a@1.0.0": "MIT", "pkg:npm/b@1.0.0": "ISC"} {
+		if got, err := fsProvider.Get(context.Background(), purl); err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v, want %s", purl, got, err, want)
+		}
+	}
+}
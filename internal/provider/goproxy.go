@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// goProxyURL is the URL of the public Go module proxy.
+	//
+	// See https://go.dev/ref/mod#goproxy-protocol
+	goProxyURL = "https://proxy.golang.org"
+	// goProxyMaxZipSize is the maximum size of a module zip, as enforced by the go command.
+	goProxyMaxZipSize = 500 << 20
+	// goProxyMaxLicenseSize is the maximum size of a license file that is read.
+	goProxyMaxLicenseSize = 1 << 20
+	// goProxyMinModuleElements is the minimum number of path elements of a module path.
+	goProxyMinModuleElements = 2
+)
+
+// goMajorSuffix matches the major version suffix of a module path, such as "/v2", or ".v2" for gopkg.in.
+//
+//nolint:gochecknoglobals // compiled once
+var goMajorSuffix = regexp.MustCompile(`(/|^gopkg\.in/.*\.)v[0-9]+(/|$)`)
+
+// GoProxyClient gets licenses by identifying the license files in module zips of a Go module proxy, such as
+// proxy.golang.org, Athens or a directory in GOPROXY layout.
+type GoProxyClient struct {
+	proxyURL string
+	dir      string
+	client   *http.Client
+	private  []string
+}
+
+var _ Provider = (*GoProxyClient)(nil)
+
+// GoProxyOptions are the options for the GoProxyClient.
+type GoProxyOptions struct {
+	// ProxyURL is the URL of the module proxy. A "file://" URL reads a directory in GOPROXY layout, such as
+	// the module download cache in $GOMODCACHE/cache/download.
+	// If empty, defaults to proxy.golang.org.
+	ProxyURL string
+	// Client is the HTTP client to use for the module proxy.
+	// If nil, defaults to an HTTP client with timeout.
+	Client *http.Client
+	// Private is a comma-separated list of module path prefix patterns in GOPRIVATE syntax. Matching modules
+	// are not requested from the proxy.
+	Private string
+}
+
+// NewGoProxyClient creates a new GoProxyClient.
+func NewGoProxyClient(opts GoProxyOptions) *GoProxyClient {
+	proxyURL := goProxyURL
+	if opts.ProxyURL != "" {
+		proxyURL = strings.TrimSuffix(opts.ProxyURL, "/")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
+	var private []string
+	for pattern := range strings.SplitSeq(opts.Private, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			private = append(private, pattern)
+		}
+	}
+
+	return &GoProxyClient{
+		proxyURL: proxyURL,
+		dir:      fileDir(proxyURL),
+		client:   client,
+		private:  private,
+	}
+}
+
+// Get gets the license of a module from the license files in the root of its zip. Purls without version get
+// the latest version.
+//
+// Purls of packages are mapped to the module that contains them, by trying the longest path prefix first. The
+// licenses of several license files, or of several licenses in one file, are combined with AND.
+func (c *GoProxyClient) Get(ctx context.Context, rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type != "golang" {
+		return "", fmt.Errorf("%w: purl type %q is not supported by the Go module proxy",
+			ErrLicenseNotFound, parsed.Type)
+	}
+	importPath := parsed.FullName()
+	if goMatchPrefixPatterns(c.private, importPath) {
+		return "", fmt.Errorf("%w: module %s is private", ErrLicenseNotFound, importPath)
+	}
+
+	for _, modulePath := range goModuleCandidates(importPath, parsed.Version) {
+		var texts []string
+		texts, err = c.licenseTexts(ctx, modulePath, parsed.Version)
+		if errors.Is(err, ErrLicenseNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get module %s: %w", modulePath, err)
+		}
+
+		if l := classifyLicenses(texts); l != "" {
+			return l, nil
+		}
+		return "", fmt.Errorf("%w: no license file identified in module %s", ErrLicenseNotFound, modulePath)
+	}
+	return "", fmt.Errorf("%w: no module found for %s", ErrLicenseNotFound, rawPurl)
+}
+
+// licenseTexts returns the texts of the license files in the root of the module zip. It returns
+// ErrLicenseNotFound if the proxy does not have the module.
+func (c *GoProxyClient) licenseTexts(ctx context.Context, modulePath, version string) ([]string, error) {
+	escapedPath := goProxyEscape(modulePath)
+	if version == "" {
+		var info struct {
+			Version string `json:"Version"`
+		}
+		if err := c.fetch(ctx, escapedPath+"/@latest", "application/json", func(body io.Reader) error {
+			return json.NewDecoder(body).Decode(&info)
+		}); err != nil {
+			return nil, err
+		}
+		if info.Version == "" {
+			return nil, fmt.Errorf("%w: no latest version of module %s", ErrInvalidResponse, modulePath)
+		}
+		version = info.Version
+	}
+
+	root := modulePath + "@" + version + "/"
+	var texts []string
+	if err := c.fetch(ctx, escapedPath+"/@v/"+goProxyEscape(version)+".zip", "application/zip",
+		func(body io.Reader) error {
+			var err error
+			texts, err = zipLicenseTexts(body, root)
+			return err
+		}); err != nil {
+		return nil, err
+	}
+	return texts, nil
+}
+
+// zipLicenseTexts returns the texts of the license files in the root directory of a module zip. Downloaded zips
+// are spooled to a temporary file instead of memory, and files of a local proxy are read in place.
+func zipLicenseTexts(body io.Reader, root string) ([]string, error) {
+	file, ok := body.(*os.File)
+	if !ok {
+		spool, err := os.CreateTemp("", "sbomlicense-*.zip")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		if _, err = io.Copy(spool, io.LimitReader(body, goProxyMaxZipSize+1)); err != nil {
+			return nil, err
+		}
+		file = spool
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > goProxyMaxZipSize {
+		return nil, fmt.Errorf("module zip too large: exceeds %d MiB", goProxyMaxZipSize>>20)
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	var texts []string
+	for _, zipFile := range archive.File {
+		name, ok := strings.CutPrefix(zipFile.Name, root)
+		if !ok || strings.Contains(name, "/") || !licenseFileName.MatchString(name) {
+			continue
+		}
+		text, err := readZipFile(zipFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", zipFile.Name, err)
+		}
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
+
+// fetch gets a file of the module proxy, relative to its URL, and decodes it with decode.
+func (c *GoProxyClient) fetch(ctx context.Context, name, accept string, decode func(io.Reader) error) error {
+	if c.dir != "" {
+		return getFile(c.dir, name, decode)
+	}
+	return get(ctx, c.client, c.proxyURL+"/"+name, accept, nil, decode)
+}
+
+// readZipFile returns the contents of a file in a zip, up to goProxyMaxLicenseSize.
+func readZipFile(file *zip.File) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, goProxyMaxLicenseSize))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// goModuleCandidates returns the module paths that may contain the package, longest first. A major version
+// suffix is added for versions from v2 on if the path has none, since purls often omit it.
+func goModuleCandidates(importPath, version string) []string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	var suffix string
+	if major != "" && major != "0" && major != "1" && !strings.HasSuffix(version, "+incompatible") &&
+		!goMajorSuffix.MatchString(importPath) {
+		suffix = "/v" + major
+	}
+
+	elements := strings.Split(importPath, "/")
+	var candidates []string
+	for n := len(elements); n >= goProxyMinModuleElements; n-- {
+		candidates = append(candidates, strings.Join(elements[:n], "/")+suffix)
+	}
+	return candidates
+}
+
+// goMatchPrefixPatterns reports whether a path prefix of the module path matches one of the patterns, as the
+// go command does for GOPRIVATE.
+func goMatchPrefixPatterns(patterns []string, modulePath string) bool {
+	for _, pattern := range patterns {
+		n := strings.Count(pattern, "/") + 1
+		elements := strings.SplitN(modulePath, "/", n+1)
+		if len(elements) < n {
+			continue
+		}
+		if matched, err := path.Match(pattern, strings.Join(elements[:n], "/")); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// goProxyEscape escapes a module path or version for the module proxy, by replacing upper case letters with
+// "!" and the lower case letter.
+func goProxyEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package provider_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// goProxyTestZip returns a module zip with the files, by path relative to the module root.
+func goProxyTestZip(t *testing.T, modulePath, version string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(modulePath + "@" + version + "/" + name)
+		if err != nil {
+			t.Fatalf("failed to create zip entry: %v", err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write zip entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// goProxyTestFiles returns the files of a stub module proxy, by path.
+func goProxyTestFiles(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	mit, _ := license.Text("MIT")
+	apache, _ := license.Text("Apache-2.0")
+
+	return map[string][]byte{
+		"github.com/!burnt!sushi/toml/@v/v1.3.2.zip": goProxyTestZip(t, "github.com/BurntSushi/toml", "v1.3.2",
+			map[string]string{"COPYING": "The MIT License (MIT)\n\nCopyright (c) 2013 TOML authors\n\n" + mit}),
+		"github.com/example/mod/v2/@v/v2.1.0.zip": goProxyTestZip(t, "github.com/example/mod/v2", "v2.1.0",
+			map[string]string{
+				"LICENSE-APACHE":  apache,
+				"LICENSE-MIT.txt": mit,
+				"go.mod":          "module github.com/example/mod/v2\n",
+				"sub/LICENSE":     "not the module license",
+			}),
+		"github.com/example/mod/v2/@latest": []byte(`{"Version": "v2.1.0"}`),
+		"github.com/example/unlicensed/@v/v1.0.0.zip": goProxyTestZip(t, "github.com/example/unlicensed", "v1.0.0",
+			map[string]string{"README.md": "# unlicensed"}),
+	}
+}
+
+// TestGoProxyClient_Get tests getting licenses from stub HTTP and file module proxies.
+func TestGoProxyClient_Get(t *testing.T) {
+	t.Parallel()
+
+	files := goProxyTestFiles(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path[1:]]
+		if !ok {
+			http.Error(w, "not found", http.StatusGone)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		purl      string
+		want      string
+		wantErrIs error
+	}{
+		{purl: "pkg:golang/github.com/BurntSushi/toml@v1.3.2", want: "MIT"},
+		{purl: "pkg:golang/github.com/example/mod/v2@v2.1.0", want: "Apache-2.0 AND MIT"},
+		{purl: "pkg:golang/github.com/example/mod/v2/internal/pkg@v2.1.0", want: "Apache-2.0 AND MIT"},
+		{purl: "pkg:golang/github.com/example/mod@v2.1.0", want: "Apache-2.0 AND MIT"},
+		{purl: "pkg:golang/github.com/example/mod/v2", want: "Apache-2.0 AND MIT"},
+		{purl: "pkg:golang/github.com/example/unlicensed@v1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:golang/github.com/example/missing@v1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:golang/git.corp.example.com/team/mod@v1.0.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:npm/lodash@4.17.21", wantErrIs: provider.ErrLicenseNotFound},
+	}
+
+	for _, proxyURL := range []string{server.URL, "file://" + filepath.ToSlash(dir)} {
+		client := provider.NewGoProxyClient(provider.GoProxyOptions{
+			ProxyURL: proxyURL,
+			Private:  "*.corp.example.com, github.com/private",
+		})
+
+		for _, tt := range tests {
+			got, err := client.Get(context.Background(), tt.purl)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("%s: Get(%q) error = %v, want %v", proxyURL, tt.purl, err, tt.wantErrIs)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: Get(%q) unexpected error = %v", proxyURL, tt.purl, err)
+				continue
+			}
+			if got != tt.want {
+				t.Errorf("%s: Get(%q) = %q, want %q", proxyURL, tt.purl, got, tt.want)
+			}
+		}
+	}
+}
+
+// TestGoProxyClient_Get_Private tests that private modules are not requested from the proxy.
+func TestGoProxyClient_Get_Private(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %s", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	client := provider.NewGoProxyClient(provider.GoProxyOptions{
+		ProxyURL: server.URL,
+		Private:  "*.corp.example.com,github.com/acme/*",
+	})
+
+	for _, purl := range []string{
+		"pkg:golang/git.corp.example.com/team/mod@v1.0.0",
+		"pkg:golang/github.com/acme/tools/cmd/tool@v1.0.0",
+	} {
+		if _, err := client.Get(context.Background(), purl); !errors.Is(err, provider.ErrLicenseNotFound) {
+			t.Errorf("Get(%q) error = %v, want %v", purl, err, provider.ErrLicenseNotFound)
+		}
+	}
+}
+
+// TestGoProxyClient_Get_ZipTooLarge tests that module zips over the size limit of the go command are rejected.
+func TestGoProxyClient_Get_ZipTooLarge(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "github.com", "example", "huge", "@v", "v1.0.0.zip")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	// A sparse file, so that the test does not write 500 MiB
+	if err := os.Truncate(path, 501<<20); err != nil {
+		t.Fatalf("failed to resize file: %v", err)
+	}
+
+	client := provider.NewGoProxyClient(provider.GoProxyOptions{ProxyURL: "file://" + filepath.ToSlash(dir)})
+	_, err := client.Get(context.Background(), "pkg:golang/github.com/example/huge@v1.0.0")
+	if !errors.Is(err, provider.ErrInvalidResponse) || !strings.Contains(err.Error(), "module zip too large") {
+		t.Errorf("Get() error = %v, want module zip too large", err)
+	}
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// ErrBlocked is returned when a purl must not be sent to an external provider.
+var ErrBlocked = errors.New("lookup blocked by privacy guard")
+
+// Guard is a Provider that refuses to send private purls to the provider it wraps.
+//
+// Wrap every provider that sends purls outside of the organisation (such as the Ecosystems API) in a Guard.
+// Private packages can then only be resolved by local providers or overrides.
+type Guard struct {
+	provider   Provider
+	purls      []string
+	qualifiers map[string][]string
+}
+
+var _ BatchProvider = (*Guard)(nil)
+
+// NewGuard creates a new Guard around provider.
+//
+// Each pattern is either a purl glob, e.g. "pkg:npm/@acme/*" or "pkg:maven/com.acme*", or a qualifier glob
+// of the form "<qualifier>=<glob>", e.g. "repository_url=https://artifactory.acme.com/*".
+// In globs, "*" matches any sequence of characters. Purls are matched in decoded form, so "@acme" matches
+// the encoded "%40acme".
+func NewGuard(provider Provider, patterns []string) (*Guard, error) {
+	g := &Guard{
+		provider:   provider,
+		qualifiers: map[string][]string{},
+	}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "":
+			continue
+		case strings.HasPrefix(pattern, "pkg:"):
+			g.purls = append(g.purls, pattern)
+		case strings.Contains(pattern, "="):
+			key, glob, _ := strings.Cut(pattern, "=")
+			g.qualifiers[strings.ToLower(key)] = append(g.qualifiers[strings.ToLower(key)], glob)
+		default:
+			return nil, fmt.Errorf("invalid private pattern %q: must start with \"pkg:\" or be <qualifier>=<glob>",
+				pattern)
+		}
+	}
+	return g, nil
+}
+
+// Blocks reports whether the purl matches one of the private patterns.
+func (g *Guard) Blocks(rawPurl string) bool {
+	for _, pattern := range g.purls {
+		if purl.Match(pattern, rawPurl) {
+			return true
+		}
+	}
+
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return false
+	}
+	for key, globs := range g.qualifiers {
+		value, ok := parsed.Qualifiers[key]
+		if !ok {
+			continue
+		}
+		for _, glob := range globs {
+			if purl.MatchGlob(glob, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Get gets the license from the wrapped provider, unless the purl is private.
+func (g *Guard) Get(ctx context.Context, purl string) (string, error) {
+	if g.Blocks(purl) {
+		return "", fmt.Errorf("%w: %s", ErrBlocked, purl)
+	}
+	return g.provider.Get(ctx, purl)
+}
+
+// GetMany gets the licenses of the purls that are not private from the wrapped provider in one batch. It fails
+// with errors.ErrUnsupported if the wrapped provider is not a BatchProvider.
+func (g *Guard) GetMany(ctx context.Context, purls []string) (map[string]BatchResult, error) {
+	batch, ok := g.provider.(BatchProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider does not support batch lookups", errors.ErrUnsupported)
+	}
+
+	results := make(map[string]BatchResult, len(purls))
+	allowed := make([]string, 0, len(purls))
+	for _, purl := range purls {
+		if g.Blocks(purl) {
+			results[purl] = BatchResult{Err: fmt.Errorf("%w: %s", ErrBlocked, purl)}
+			continue
+		}
+		allowed = append(allowed, purl)
+	}
+	if len(allowed) == 0 {
+		return results, nil
+	}
+
+	answers, err := batch.GetMany(ctx, allowed)
+	if err != nil {
+		return nil, err
+	}
+	for purl, answer := range answers {
+		results[purl] = answer
+	}
+	return results, nil
+}
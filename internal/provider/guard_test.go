@@ -0,0 +1,138 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestNewGuard_InvalidPattern tests that patterns must be purl or qualifier globs.
+func TestNewGuard_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	if _, err := provider.NewGuard(&mockProvider{}, []string{"npm/@acme/*"}); err == nil {
+		t.Error("NewGuard() error = nil, want invalid pattern error")
+	}
+}
+
+// TestGuard_Blocks tests which purls are considered private.
+func TestGuard_Blocks(t *testing.T) {
+	t.Parallel()
+
+	guard, err := provider.NewGuard(&mockProvider{}, []string{
+		"pkg:npm/@acme/*",
+		"pkg:maven/com.acme*",
+		"repository_url=https://artifactory.acme.com/*",
+		" ",
+	})
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+
+	tests := []struct {
+		purl string
+		want bool
+	}{
+		{purl: "pkg:npm/%40acme/ui@1.0.0", want: true},
+		{purl: "pkg:npm/@acme/ui@1.0.0", want: true},
+		{purl: "pkg:npm/%40types/node@18.0.0", want: false},
+		{purl: "pkg:maven/com.acme.internal/core@2.0", want: true},
+		{purl: "pkg:maven/com.acmeshop/core@2.0", want: true},
+		{purl: "pkg:maven/org.apache/commons@1.0", want: false},
+		{purl: "pkg:pypi/tool@1.0?repository_url=https://artifactory.acme.com/api/pypi", want: true},
+		{purl: "pkg:pypi/tool@1.0?repository_url=https://pypi.org/simple", want: false},
+		{purl: "not a purl", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := guard.Blocks(tt.purl); got != tt.want {
+			t.Errorf("Blocks(%q) = %v, want %v", tt.purl, got, tt.want)
+		}
+	}
+}
+
+// TestGuard_Get tests that private purls never reach the wrapped provider.
+func TestGuard_Get(t *testing.T) {
+	t.Parallel()
+
+	mockProv := &mockProvider{license: "MIT"}
+	guard, err := provider.NewGuard(mockProv, []string{"pkg:npm/@acme/*"})
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+
+	_, err = guard.Get(context.Background(), "pkg:npm/%40acme/ui@1.0.0")
+	if !errors.Is(err, provider.ErrBlocked) {
+		t.Errorf("Get() private error = %v, want ErrBlocked", err)
+	}
+	if mockProv.getCalls != 0 {
+		t.Errorf("wrapped provider called %d times for a private purl, want 0", mockProv.getCalls)
+	}
+
+	license, err := guard.Get(context.Background(), "pkg:npm/lodash@4.17.21")
+	if err != nil || license != "MIT" {
+		t.Errorf("Get() public = %q, %v, want MIT", license, err)
+	}
+	if mockProv.getCalls != 1 {
+		t.Errorf("wrapped provider called %d times, want 1", mockProv.getCalls)
+	}
+}
+
+// TestGet_BlockedServedFromCache tests that a blocked purl can still be served from the cache.
+func TestGet_BlockedServedFromCache(t *testing.T) {
+	t.Parallel()
+
+	mockCache := newMockCache()
+	mockCache.data["pkg:npm/%40acme/ui@1.0.0"] = "Apache-2.0"
+
+	guard, err := provider.NewGuard(&mockProvider{license: "MIT"}, []string{"pkg:npm/@acme/*"})
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+
+	license, err := provider.Get(context.Background(), provider.GetOptions{
+		Purl:     "pkg:npm/%40acme/ui@1.0.0",
+		Provider: guard,
+		Cache:    mockCache,
+	})
+	if err != nil || license != "Apache-2.0" {
+		t.Errorf("Get() = %q, %v, want Apache-2.0 from cache", license, err)
+	}
+}
+
+// TestGuard_GetMany tests that private purls of a batch never reach the wrapped provider.
+func TestGuard_GetMany(t *testing.T) {
+	t.Parallel()
+
+	mockProv := &mockBatchProvider{licenses: map[string]string{"pkg:npm/lodash@4.17.21": "MIT"}}
+	guard, err := provider.NewGuard(mockProv, []string{"pkg:npm/@acme/*"})
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+
+	results, err := guard.GetMany(context.Background(), []string{"pkg:npm/%40acme/ui@1.0.0", "pkg:npm/lodash@4.17.21"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if result := results["pkg:npm/%40acme/ui@1.0.0"]; !errors.Is(result.Err, provider.ErrBlocked) {
+		t.Errorf("GetMany() private = %+v, want ErrBlocked", result)
+	}
+	if result := results["pkg:npm/lodash@4.17.21"]; result.Err != nil || result.License != "MIT" {
+		t.Errorf("GetMany() public = %+v, want MIT", result)
+	}
+	if len(mockProv.batches) != 1 || len(mockProv.batches[0]) != 1 {
+		t.Errorf("wrapped provider batches = %v, want the public purl only", mockProv.batches)
+	}
+
+	// Providers without batch lookups are not supported
+	guard, err = provider.NewGuard(&mockProvider{}, nil)
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+	_, err = guard.GetMany(context.Background(), []string{"pkg:npm/lodash@4.17.21"})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("GetMany() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+}
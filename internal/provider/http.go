@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/version"
+)
+
+// fileScheme is the scheme of URLs of directories in the local file system, which some providers read instead
+// of a server.
+const fileScheme = "file://"
+
+// userAgent returns the User-Agent header sent to the APIs.
+func userAgent() string {
+	return fmt.Sprintf("sbomlicense/%s", version.Get())
+}
+
+// statusError returns the error for an unsuccessful HTTP response.
+func statusError(statusCode int) error {
+	switch statusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return fmt.Errorf("%w: HTTP %d", ErrLicenseNotFound, statusCode)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: HTTP 429", ErrRateLimited)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: HTTP %d", ErrUnavailable, statusCode)
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return fmt.Errorf("%w: HTTP %d", errors.ErrUnsupported, statusCode)
+	default:
+		return fmt.Errorf("API error: HTTP %d", statusCode)
+	}
+}
+
+// getJSON gets the URL and decodes the JSON response into v. The header is added to the request; the
+// User-Agent defaults to userAgent.
+func getJSON(ctx context.Context, client *http.Client, apiURL string, header http.Header, v any) error {
+	return get(ctx, client, apiURL, "application/json", header, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(v)
+	})
+}
+
+// getXML gets the URL and decodes the XML response into v. The header is added to the request; the
+// User-Agent defaults to userAgent.
+func getXML(ctx context.Context, client *http.Client, apiURL string, header http.Header, v any) error {
+	return get(ctx, client, apiURL, "application/xml", header, func(body io.Reader) error {
+		return newXMLDecoder(body).Decode(v)
+	})
+}
+
+// newXMLDecoder creates an xml.Decoder that also reads documents declared as ISO-8859-1 or windows-1252, as
+// many older POMs are.
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// windows1252 are the characters of the bytes 0x80 to 0x9F in windows-1252. The other bytes are the same as in
+// ISO-8859-1 and Unicode.
+//
+//nolint:gochecknoglobals // lookup table
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// charsetReader converts a document in a legacy encoding to UTF-8 for an xml.Decoder. ISO-8859-1 is read as
+// windows-1252, its superset, as browsers do.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "us-ascii", "ascii", "utf8":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		b.Grow(len(data))
+		for _, c := range data {
+			if c >= 0x80 && c < 0xa0 {
+				b.WriteRune(windows1252[c-0x80])
+				continue
+			}
+			b.WriteRune(rune(c))
+		}
+		return strings.NewReader(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", label)
+	}
+}
+
+// postJSON posts body as JSON to the URL and decodes the JSON response into v. The header is added to the
+// request; the User-Agent defaults to userAgent.
+func postJSON(ctx context.Context, client *http.Client, apiURL string, header http.Header, body, v any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode HTTP request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(ctx, client, req, "application/json", header, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(v)
+	})
+}
+
+// get gets the URL and decodes a successful response with decode.
+func get(
+	ctx context.Context,
+	client *http.Client,
+	apiURL, accept string,
+	header http.Header,
+	decode func(io.Reader) error,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	return do(ctx, client, req, accept, header, decode)
+}
+
+// do sends the request and decodes a successful response with decode.
+func do(
+	ctx context.Context,
+	client *http.Client,
+	req *http.Request,
+	accept string,
+	header http.Header,
+	decode func(io.Reader) error,
+) error {
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Accept", accept)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	response, err := client.Do(req)
+	var urlErr *url.Error
+	if err != nil && ctx.Err() == nil && !(errors.As(err, &urlErr) && urlErr.Timeout()) &&
+		!errors.Is(err, context.DeadlineExceeded) {
+		// Timeouts are not retried, as a hanging server would make every lookup wait for the timeout again, and
+		// neither are rate limiter waits past the deadline
+		return fmt.Errorf("%w: failed to make HTTP request: %w", ErrUnavailable, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err = statusError(response.StatusCode)
+		if delay, ok := parseRetryAfter(response.Header.Get("Retry-After"), time.Now()); ok {
+			return &retryAfterError{err: err, delay: delay}
+		}
+		return err
+	}
+	if err = decode(response.Body); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return nil
+}
+
+// fileDir returns the directory of a "file://" URL, or "" if the URL is not a file URL.
+func fileDir(rawURL string) string {
+	if !strings.HasPrefix(rawURL, fileScheme) {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return filepath.FromSlash(parsed.Path)
+}
+
+// getFile opens the file with the slash-separated name in dir and decodes it with decode. It returns
+// ErrLicenseNotFound if the file does not exist, like get for HTTP 404.
+func getFile(dir, name string, decode func(io.Reader) error) error {
+	file, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s not in %s", ErrLicenseNotFound, name, dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if err = decode(file); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return nil
+}
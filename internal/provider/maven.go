@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// mavenCentralURL is the URL of the Maven Central repository.
+	mavenCentralURL = "https://repo.maven.apache.org/maven2"
+	// mavenMaxParents is the maximum number of parent POMs followed, which guards against cycles.
+	mavenMaxParents = 16
+)
+
+// MavenClient gets licenses from the POMs of a Maven repository, such as Maven Central or a Nexus or
+// Artifactory repository. Licenses declared in parent POMs are inherited.
+//
+// Parsed POMs are kept in memory, since parent POMs are shared by many artifacts.
+type MavenClient struct {
+	repositoryURL string
+	dir           string
+	client        *http.Client
+
+	mu   sync.RWMutex
+	poms map[string]*mavenPOM
+}
+
+var _ Provider = (*MavenClient)(nil)
+
+// MavenOptions are the options for the MavenClient.
+type MavenOptions struct {
+	// RepositoryURL is the URL of the Maven repository. A "file://" URL reads a local repository, such as
+	// ~/.m2/repository.
+	// If empty, defaults to Maven Central.
+	RepositoryURL string
+	// Client is the HTTP client to use for the repository.
+	// If nil, defaults to an HTTP client with timeout.
+	Client *http.Client
+}
+
+// NewMavenClient creates a new MavenClient.
+func NewMavenClient(opts MavenOptions) *MavenClient {
+	repositoryURL := mavenCentralURL
+	if opts.RepositoryURL != "" {
+		repositoryURL = strings.TrimSuffix(opts.RepositoryURL, "/")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
+	return &MavenClient{
+		repositoryURL: repositoryURL,
+		dir:           fileDir(repositoryURL),
+		client:        client,
+		poms:          make(map[string]*mavenPOM),
+	}
+}
+
+// mavenCoordinates are the coordinates of a Maven artifact.
+type mavenCoordinates struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// String returns the coordinates as "group:artifact:version".
+func (c mavenCoordinates) String() string {
+	return c.GroupID + ":" + c.ArtifactID + ":" + c.Version
+}
+
+// mavenPOM is the part of a POM needed to find the licenses.
+type mavenPOM struct {
+	Parent   *mavenCoordinates `xml:"parent"`
+	Licenses []struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+	} `xml:"licenses>license"`
+}
+
+// Get gets the license of an artifact from its POM, or from the nearest parent POM that declares licenses.
+//
+// License names and URLs are mapped to SPDX identifiers. Several licenses are combined with OR, since they are
+// mostly used for dual licensing.
+func (c *MavenClient) Get(ctx context.Context, rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type != "maven" {
+		return "", fmt.Errorf("%w: purl type %q is not supported by Maven repositories",
+			ErrLicenseNotFound, parsed.Type)
+	}
+	if parsed.Namespace == "" || parsed.Version == "" {
+		return "", fmt.Errorf("%w: Maven purl %s needs a group and version", ErrLicenseNotFound, rawPurl)
+	}
+
+	coordinates := mavenCoordinates{GroupID: parsed.Namespace, ArtifactID: parsed.Name, Version: parsed.Version}
+	for range mavenMaxParents {
+		var pom *mavenPOM
+		if pom, err = c.pom(ctx, coordinates); err != nil {
+			return "", fmt.Errorf("failed to get POM %s: %w", coordinates, err)
+		}
+
+		// Licenses of a POM replace those of its parent
+		if len(pom.Licenses) > 0 {
+			var licenses []string
+			for _, l := range pom.Licenses {
+				if id, ok := mavenLicense(l.Name, l.URL); ok && !slices.Contains(licenses, id) {
+					licenses = append(licenses, id)
+				}
+			}
+			if len(licenses) == 0 {
+				return "", fmt.Errorf("%w: unknown licenses in POM %s", ErrLicenseNotFound, coordinates)
+			}
+			return license.Join(license.OperatorOr, licenses), nil
+		}
+
+		if pom.Parent == nil {
+			break
+		}
+		coordinates = *pom.Parent
+	}
+	return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, rawPurl)
+}
+
+// pom returns the parsed POM of the coordinates, from memory if it was parsed before.
+func (c *MavenClient) pom(ctx context.Context, coordinates mavenCoordinates) (*mavenPOM, error) {
+	key := coordinates.String()
+	c.mu.RLock()
+	pom, ok := c.poms[key]
+	c.mu.RUnlock()
+	if ok {
+		return pom, nil
+	}
+
+	pom = &mavenPOM{}
+	name := strings.ReplaceAll(coordinates.GroupID, ".", "/") + "/" + coordinates.ArtifactID + "/" +
+		coordinates.Version + "/" + coordinates.ArtifactID + "-" + coordinates.Version + ".pom"
+	var err error
+	if c.dir != "" {
+		err = getFile(c.dir, name, func(body io.Reader) error {
+			return newXMLDecoder(body).Decode(pom)
+		})
+	} else {
+		err = getXML(ctx, c.client, c.repositoryURL+"/"+name, nil, pom)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.poms[key] = pom
+	c.mu.Unlock()
+	return pom, nil
+}
+
+// mavenLicense maps the name or URL of a POM license to an SPDX identifier. The name is preferred, since
+// URLs often point to a generic page.
+func mavenLicense(name, rawURL string) (string, bool) {
+	if id, ok := license.FromName(name); ok {
+		return id, true
+	}
+	return license.FromURL(rawURL)
+}
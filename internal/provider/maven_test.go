@@ -0,0 +1,133 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// mavenTestPOMs are the POMs of the stub Maven repository, by path.
+//
+//nolint:gochecknoglobals // test fixtures
+var mavenTestPOMs = map[string]string{
+	"/maven2/org/example/parent/1/parent-1.pom": `<project>
+		<groupId>org.example</groupId><artifactId>parent</artifactId><version>1</version>
+		<licenses><license>
+			<name>The Apache Software License, Version 2.0</name>
+			<url>https://www.apache.org/licenses/LICENSE-2.0.txt</url>
+		</license></licenses>
+	</project>`,
+	"/maven2/org/example/core/1.0.0/core-1.0.0.pom": `<project>
+		<parent><groupId>org.example</groupId><artifactId>middle</artifactId><version>1</version></parent>
+		<artifactId>core</artifactId>
+	</project>`,
+	"/maven2/org/example/middle/1/middle-1.pom": `<project>
+		<parent><groupId>org.example</groupId><artifactId>parent</artifactId><version>1</version></parent>
+		<artifactId>middle</artifactId>
+	</project>`,
+	"/maven2/org/example/util/2.0/util-2.0.pom": `<project>
+		<parent><groupId>org.example</groupId><artifactId>parent</artifactId><version>1</version></parent>
+		<artifactId>util</artifactId>
+	</project>`,
+	"/maven2/org/example/dual/1.0/dual-1.0.pom": `<project>
+		<parent><groupId>org.example</groupId><artifactId>parent</artifactId><version>1</version></parent>
+		<licenses>
+			<license><name>Eclipse Public License - v 1.0</name></license>
+			<license><name>EDL</name><url>http://www.eclipse.org/org/documents/edl-v10.php</url></license>
+		</licenses>
+	</project>`,
+	"/maven2/org/example/custom/1.0/custom-1.0.pom": `<project>
+		<licenses><license><name>Example Commercial License</name></license></licenses>
+	</project>`,
+	"/maven2/org/example/none/1.0/none-1.0.pom": `<project><artifactId>none</artifactId></project>`,
+	"/maven2/org/example/orphan/1.0/orphan-1.0.pom": `<project>
+		<parent><groupId>org.example</groupId><artifactId>missing</artifactId><version>1</version></parent>
+	</project>`,
+	"/maven2/org/example/broken/1.0/broken-1.0.pom": `<project><licenses>`,
+	"/maven2/org/example/latin-parent/1/latin-parent-1.pom": `<?xml version="1.0" encoding="ISO-8859-1"?>
+	<project>
+		<organization><name>Soci` + "\xe9t\xe9" + ` Exemple</name></organization>
+		<licenses><license><name>MIT License</name></license></licenses>
+	</project>`,
+	"/maven2/org/example/latin/1.0/latin-1.0.pom": `<project>
+		<parent><groupId>org.example</groupId><artifactId>latin-parent</artifactId><version>1</version></parent>
+	</project>`,
+	"/maven2/org/example/quoted/1.0/quoted-1.0.pom": `<?xml version="1.0" encoding="windows-1252"?>
+	<project>
+		<description>` + "\x93Quoted\x94" + `</description>
+		<licenses><license><name>MIT License</name></license></licenses>
+	</project>`,
+	"/maven2/org/example/ebcdic/1.0/ebcdic-1.0.pom": `<?xml version="1.0" encoding="EBCDIC"?><project/>`,
+}
+
+// TestMavenClient_Get tests getting licenses from a stub Maven repository.
+func TestMavenClient_Get(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		pom, ok := mavenTestPOMs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(pom))
+	}))
+	t.Cleanup(server.Close)
+
+	client := provider.NewMavenClient(provider.MavenOptions{RepositoryURL: server.URL + "/maven2/"})
+
+	tests := []struct {
+		purl      string
+		want      string
+		wantErrIs error
+		wantErr   bool
+	}{
+		{purl: "pkg:maven/org.example/parent@1", want: "Apache-2.0"},
+		{purl: "pkg:maven/org.example/core@1.0.0?type=jar", want: "Apache-2.0"},
+		{purl: "pkg:maven/org.example/util@2.0", want: "Apache-2.0"},
+		{purl: "pkg:maven/org.example/dual@1.0", want: "EPL-1.0 OR BSD-3-Clause"},
+		{purl: "pkg:maven/org.example/custom@1.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:maven/org.example/none@1.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:maven/org.example/orphan@1.0", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:maven/org.example/broken@1.0", wantErrIs: provider.ErrInvalidResponse},
+		{purl: "pkg:maven/org.example/latin@1.0", want: "MIT"},
+		{purl: "pkg:maven/org.example/quoted@1.0", want: "MIT"},
+		{purl: "pkg:maven/org.example/ebcdic@1.0", wantErrIs: provider.ErrInvalidResponse},
+		{purl: "pkg:maven/org.example/core", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "pkg:npm/lodash@4.17.21", wantErrIs: provider.ErrLicenseNotFound},
+		{purl: "not-a-purl", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := client.Get(context.Background(), tt.purl)
+		if tt.wantErrIs != nil || tt.wantErr {
+			if err == nil || (tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs)) {
+				t.Errorf("Get(%q) error = %v, want %v", tt.purl, err, tt.wantErrIs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Get(%q) unexpected error = %v", tt.purl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Get(%q) = %q, want %q", tt.purl, got, tt.want)
+		}
+	}
+
+	// The parent POM is shared by several artifacts but parsed once
+	if n := requests["/maven2/org/example/parent/1/parent-1.pom"]; n != 1 {
+		t.Errorf("parent POM requested %d times, want 1", n)
+	}
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// npmRegistryURL is the URL of the public npm registry.
+	npmRegistryURL = "https://registry.npmjs.org"
+	// npmLatest is the dist-tag looked up for purls without version.
+	npmLatest = "latest"
+)
+
+// NPMClient gets licenses from package documents of an npm registry, such as the public registry, Verdaccio or
+// an Artifactory npm remote.
+type NPMClient struct {
+	registryURL string
+	client      *http.Client
+	token       string
+}
+
+var _ Provider = (*NPMClient)(nil)
+
+// NPMOptions are the options for the NPMClient.
+type NPMOptions struct {
+	// RegistryURL is the URL of the npm registry, including any path prefix of a mirror.
+	// If empty, defaults to the public npm registry.
+	RegistryURL string
+	// Client is the HTTP client to use for the registry.
+	// If nil, defaults to an HTTP client with timeout.
+	Client *http.Client
+	// Token is sent as bearer token to the registry.
+	// If empty, requests are not authenticated.
+	Token string
+}
+
+// NewNPMClient creates a new NPMClient.
+func NewNPMClient(opts NPMOptions) *NPMClient {
+	registryURL := npmRegistryURL
+	if opts.RegistryURL != "" {
+		registryURL = strings.TrimSuffix(opts.RegistryURL, "/")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
+	return &NPMClient{
+		registryURL: registryURL,
+		client:      client,
+		token:       opts.Token,
+	}
+}
+
+// npmPackage is a package version document of an npm registry.
+//
+// The license is an SPDX expression, or in legacy packages an object with a type; the legacy licenses field is
+// a list of either.
+type npmPackage struct {
+	Name     string            `json:"name"`
+	Version  string            `json:"version"`
+	License  json.RawMessage   `json:"license"`
+	Licenses []json.RawMessage `json:"licenses"`
+}
+
+// license returns the license of the package, or "" if it has none. Legacy lists of licenses are combined
+// with OR, since they were used for dual licensing.
+func (pkg npmPackage) license() string {
+	if l := npmLicense(pkg.License); l != "" {
+		return l
+	}
+	var licenses []string
+	for _, raw := range pkg.Licenses {
+		if l := npmLicense(raw); l != "" && !slices.Contains(licenses, l) {
+			licenses = append(licenses, l)
+		}
+	}
+	return license.Join(license.OperatorOr, licenses)
+}
+
+// Get gets the license of a package version from the registry. Purls without version get the latest version.
+func (c *NPMClient) Get(ctx context.Context, rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type != "npm" {
+		return "", fmt.Errorf("%w: purl type %q is not supported by the npm registry", ErrLicenseNotFound, parsed.Type)
+	}
+
+	version := parsed.Version
+	if version == "" {
+		version = npmLatest
+	}
+	// Scoped names are requested as "@scope%2Fname"
+	apiURL := c.registryURL + "/" + url.PathEscape(parsed.FullName()) + "/" + url.PathEscape(version)
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	var pkg npmPackage
+	if err = getJSON(ctx, c.client, apiURL, header, &pkg); err != nil {
+		return "", err
+	}
+
+	if l := pkg.license(); l != "" {
+		return l, nil
+	}
+	return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, rawPurl)
+}
+
+// npmLicense returns the license of a license field, which is either a string or an object with a type.
+// References to license files ("SEE LICENSE IN ...") and "UNLICENSED" are not licenses.
+func npmLicense(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		var object struct {
+			Type string `json:"type"`
+		}
+		if err = json.Unmarshal(raw, &object); err != nil {
+			return ""
+		}
+		value = object.Type
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "UNLICENSED" || strings.HasPrefix(strings.ToUpper(value), "SEE LICENSE IN") {
+		return ""
+	}
+	return value
+}
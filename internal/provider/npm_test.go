@@ -0,0 +1,131 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestNPMClient_Get tests getting licenses from a stub npm registry.
+func TestNPMClient_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		purl         string
+		wantPath     string
+		mockResponse string
+		want         string
+		wantErrIs    error
+	}{
+		{
+			name:         "license expression",
+			purl:         "pkg:npm/lodash@4.17.21",
+			wantPath:     "/npm/lodash/4.17.21",
+			mockResponse: `{"name": "lodash", "license": "MIT"}`,
+			want:         "MIT",
+		},
+		{
+			name:         "scoped package",
+			purl:         "pkg:npm/%40types/node@18.0.0",
+			wantPath:     "/npm/@types%2Fnode/18.0.0",
+			mockResponse: `{"license": "MIT"}`,
+			want:         "MIT",
+		},
+		{
+			name:         "latest version",
+			purl:         "pkg:npm/lodash",
+			wantPath:     "/npm/lodash/latest",
+			mockResponse: `{"license": "MIT"}`,
+			want:         "MIT",
+		},
+		{
+			name:         "legacy license object",
+			purl:         "pkg:npm/old@1.0.0",
+			wantPath:     "/npm/old/1.0.0",
+			mockResponse: `{"license": {"type": "ISC", "url": "https://opensource.org/licenses/ISC"}}`,
+			want:         "ISC",
+		},
+		{
+			name:     "legacy licenses array",
+			purl:     "pkg:npm/old@1.0.0",
+			wantPath: "/npm/old/1.0.0",
+			mockResponse: `{"licenses": [{"type": "MIT", "url": "https://example.com/MIT"},
+				{"type": "Apache-2.0"}, "GPL-2.0-only WITH Classpath-exception-2.0"]}`,
+			want: "MIT OR Apache-2.0 OR (GPL-2.0-only WITH Classpath-exception-2.0)",
+		},
+		{
+			name:         "license file reference",
+			purl:         "pkg:npm/private@1.0.0",
+			wantPath:     "/npm/private/1.0.0",
+			mockResponse: `{"license": "SEE LICENSE IN LICENSE.md"}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+		{
+			name:         "no license",
+			purl:         "pkg:npm/none@1.0.0",
+			wantPath:     "/npm/none/1.0.0",
+			mockResponse: `{"name": "none"}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != tt.wantPath {
+					t.Errorf("path = %q, want %q", r.URL.EscapedPath(), tt.wantPath)
+				}
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					t.Errorf("Authorization = %q, want bearer token", r.Header.Get("Authorization"))
+				}
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			t.Cleanup(server.Close)
+
+			// Mirrors serve the registry under a path prefix
+			client := provider.NewNPMClient(provider.NPMOptions{RegistryURL: server.URL + "/npm/", Token: "secret"})
+
+			got, err := client.Get(context.Background(), tt.purl)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNPMClient_Get_Errors tests unsupported purls and registry errors.
+func TestNPMClient_Get_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Authorization = %q, want none without token", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	client := provider.NewNPMClient(provider.NPMOptions{RegistryURL: server.URL})
+
+	for _, purl := range []string{"pkg:pypi/requests@2.28.0", "pkg:npm/missing@1.0.0"} {
+		if _, err := client.Get(context.Background(), purl); !errors.Is(err, provider.ErrLicenseNotFound) {
+			t.Errorf("Get(%q) error = %v, want %v", purl, err, provider.ErrLicenseNotFound)
+		}
+	}
+}
@@ -2,11 +2,15 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
+	"github.com/boringbin/sbomlicense/internal/curation"
 )
 
 var (
@@ -14,6 +18,11 @@ var (
 	ErrLicenseNotFound = errors.New("license not found")
 	// ErrInvalidResponse is returned when the API response is invalid.
 	ErrInvalidResponse = errors.New("invalid API response")
+	// ErrRateLimited is returned when the API rejects requests because too many were made (HTTP 429).
+	ErrRateLimited = errors.New("rate limited by API")
+	// ErrUnavailable is returned when the API cannot be reached or is temporarily unavailable (HTTP 502, 503
+	// and 504).
+	ErrUnavailable = errors.New("API service unavailable")
 )
 
 // Provider is the interface that each enrichment provider must implement.
@@ -24,6 +33,63 @@ type Provider interface {
 	Get(ctx context.Context, purl string) (string, error)
 }
 
+// BatchProvider is a Provider that can get the licenses of many packages at once.
+type BatchProvider interface {
+	Provider
+	// GetMany returns the license or error of each package by purl, as Get would. An error means the whole
+	// batch failed.
+	GetMany(ctx context.Context, purls []string) (map[string]BatchResult, error)
+}
+
+// BatchResult is the license or error of a package in a batch.
+type BatchResult struct {
+	// License is the license of the package.
+	License string
+	// Provider is the name of the provider that found the license, if the provider is a Resolver.
+	Provider string
+	// Disagreement are the licenses found by each provider of a Consensus, if they disagree.
+	Disagreement []Answer
+	// Err is the error of the lookup, e.g. ErrLicenseNotFound.
+	Err error
+}
+
+// SupportsBatch reports whether the provider looks up packages in batches. The Ecosystems client does unless the
+// API turned out to have no bulk lookup, guards and circuit breakers if the provider they wrap does, and chains if
+// one of their providers does.
+func SupportsBatch(p Provider) bool {
+	switch p := p.(type) {
+	case *Guard:
+		return SupportsBatch(p.provider)
+	case *Breaker:
+		return SupportsBatch(p.provider)
+	case *Client:
+		return !p.noBulkLookup.Load()
+	case *Chain:
+		return slices.ContainsFunc(p.links, func(link Link) bool { return SupportsBatch(link.Provider) })
+	default:
+		_, ok := p.(BatchProvider)
+		return ok
+	}
+}
+
+// Source is where a license was found.
+type Source string
+
+const (
+	// SourceCuration means the license was set by a manual curation.
+	SourceCuration Source = "curation"
+	// SourceCache means the license was found in the cache.
+	SourceCache Source = "cache"
+	// SourceProvider means the license was returned by the provider.
+	SourceProvider Source = "provider"
+)
+
+// Curator returns the manual license curation of a package, if any.
+type Curator interface {
+	// Find returns the curation matching the purl.
+	Find(purl string) (*curation.Curation, bool)
+}
+
 // GetOptions are the options for getting the license for a package.
 type GetOptions struct {
 	// Purl is the purl of the package.
@@ -34,35 +100,234 @@ type GetOptions struct {
 	Cache cache.Cache
 	// CacheTTL is the time-to-live duration for the cache.
 	CacheTTL time.Duration
+	// Curations are consulted before the cache and the provider.
+	//
+	// If nil, no curations are used.
+	Curations Curator
+}
+
+// Result is the license of a package and where it was found.
+type Result struct {
+	// License is the license of the package, or empty if it was not found.
+	License string
+	// Source is where the license was found.
+	Source Source
+	// Curation is the curation that set the license, if the source is SourceCuration.
+	Curation *curation.Curation
+	// Provider is the name of the provider that found the license, if the provider is a Resolver.
+	//
+	// Licenses found in the cache keep the name of the provider that found them originally.
+	Provider string
+	// Disagreement are the licenses found by each provider of a Consensus, if they disagree.
+	Disagreement []Answer
+}
+
+// cacheEntry is a license cached together with the name of the provider that found it.
+//
+// Licenses without a provider name are cached as plain strings, as before chains were introduced.
+type cacheEntry struct {
+	License      string   `json:"license"`
+	Provider     string   `json:"provider"`
+	Disagreement []Answer `json:"disagreement,omitempty"`
+}
+
+// encodeCacheEntry encodes a resolution as a cache value.
+func encodeCacheEntry(resolution Resolution) (string, error) {
+	if resolution.Provider == "" && len(resolution.Disagreement) == 0 {
+		return resolution.License, nil
+	}
+	data, err := json.Marshal(cacheEntry{
+		License:      resolution.License,
+		Provider:     resolution.Provider,
+		Disagreement: resolution.Disagreement,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeCacheEntry decodes a cache value written by encodeCacheEntry.
+//
+// Since SPDX expressions never start with a brace, anything else is a plain license.
+func decodeCacheEntry(value string) cacheEntry {
+	if strings.HasPrefix(value, "{") {
+		var entry cacheEntry
+		if err := json.Unmarshal([]byte(value), &entry); err == nil {
+			return entry
+		}
+	}
+	return cacheEntry{License: value}
 }
 
 // Get gets the license for a package from the provider or cache.
 //
 // This is basically a wrapper around the chosen provider with the cache.
 func Get(ctx context.Context, opts GetOptions) (string, error) {
+	result, err := Lookup(ctx, opts)
+	return result.License, err
+}
+
+// Lookup gets the license for a package and where it was found: a curation, the cache or the provider.
+func Lookup(ctx context.Context, opts GetOptions) (Result, error) {
+	// Curations override everything else and are never cached
+	if opts.Curations != nil {
+		if curated, ok := opts.Curations.Find(opts.Purl); ok {
+			return Result{License: curated.License, Source: SourceCuration, Curation: curated}, nil
+		}
+	}
+
 	// If we have a cache, try to get the license from it
 	if opts.Cache != nil {
 		license, err := opts.Cache.Get(opts.Purl)
 		if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
-			return "", fmt.Errorf("failed to get license from cache: %w", err)
+			return Result{}, fmt.Errorf("failed to get license from cache: %w", err)
 		}
 		if err == nil {
-			return license, nil
+			entry := decodeCacheEntry(license)
+			return Result{
+				License:      entry.License,
+				Source:       SourceCache,
+				Provider:     entry.Provider,
+				Disagreement: entry.Disagreement,
+			}, nil
 		}
 	}
 
 	// If we don't have a cache, or the license is not in the cache, get it from the service
-	license, err := opts.Provider.Get(ctx, opts.Purl)
+	resolution, err := resolve(ctx, opts.Provider, opts.Purl)
 	if err != nil {
-		return "", fmt.Errorf("failed to get license from provider: %w", err)
+		return Result{}, fmt.Errorf("failed to get license from provider: %w", err)
 	}
 
 	// If we have a license, add it to the cache with the TTL
-	if license != "" && opts.Cache != nil {
-		if setErr := opts.Cache.SetWithTTL(opts.Purl, license, opts.CacheTTL); setErr != nil {
-			return "", fmt.Errorf("failed to set license in cache: %w", setErr)
+	if resolution.License != "" && opts.Cache != nil {
+		value, encodeErr := encodeCacheEntry(resolution)
+		if encodeErr != nil {
+			return Result{}, fmt.Errorf("failed to encode cache entry: %w", encodeErr)
+		}
+		if setErr := opts.Cache.SetWithTTL(opts.Purl, value, opts.CacheTTL); setErr != nil {
+			return Result{}, fmt.Errorf("failed to set license in cache: %w", setErr)
+		}
+	}
+
+	return Result{
+		License:      resolution.License,
+		Source:       SourceProvider,
+		Provider:     resolution.Provider,
+		Disagreement: resolution.Disagreement,
+	}, nil
+}
+
+// LookupMany gets the licenses of many packages like Lookup, using opts for all but the purl. The provider must
+// support batch lookups, see SupportsBatch: curated and cached packages are answered first, and the others are
+// looked up in one batch.
+//
+// The returned results have the result or error of each purl. If the batch fails, the error is returned with the
+// results of the curated and cached packages only, and the others can be looked up with Lookup.
+func LookupMany(ctx context.Context, opts GetOptions, purls []string) (map[string]BatchLookup, error) {
+	results := make(map[string]BatchLookup, len(purls))
+	var remaining []string
+	for _, purl := range purls {
+		if _, ok := results[purl]; ok {
+			continue
+		}
+		if opts.Curations != nil {
+			if curated, ok := opts.Curations.Find(purl); ok {
+				results[purl] = BatchLookup{Result: Result{License: curated.License, Source: SourceCuration, Curation: curated}}
+				continue
+			}
+		}
+		if opts.Cache != nil {
+			value, err := opts.Cache.Get(purl)
+			if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+				results[purl] = BatchLookup{Err: fmt.Errorf("failed to get license from cache: %w", err)}
+				continue
+			}
+			if err == nil {
+				entry := decodeCacheEntry(value)
+				results[purl] = BatchLookup{Result: Result{
+					License:      entry.License,
+					Source:       SourceCache,
+					Provider:     entry.Provider,
+					Disagreement: entry.Disagreement,
+				}}
+				continue
+			}
+		}
+		// Mark the purl as seen until the batch answers it
+		results[purl] = BatchLookup{}
+		remaining = append(remaining, purl)
+	}
+	if len(remaining) == 0 {
+		return results, nil
+	}
+
+	batch, ok := opts.Provider.(BatchProvider)
+	if !ok || !SupportsBatch(opts.Provider) {
+		err := fmt.Errorf("%w: provider does not support batch lookups", errors.ErrUnsupported)
+		return withoutPurls(results, remaining), err
+	}
+	answers, err := batch.GetMany(ctx, remaining)
+	if err != nil {
+		return withoutPurls(results, remaining), fmt.Errorf("failed to get licenses from provider: %w", err)
+	}
+
+	for _, purl := range remaining {
+		answer, ok := answers[purl]
+		switch {
+		case !ok:
+			// Left to Lookup
+			delete(results, purl)
+		case answer.Err != nil:
+			results[purl] = BatchLookup{Err: fmt.Errorf("failed to get license from provider: %w", answer.Err)}
+		default:
+			if answer.License != "" && opts.Cache != nil {
+				value, encodeErr := encodeCacheEntry(Resolution{
+					License:      answer.License,
+					Provider:     answer.Provider,
+					Disagreement: answer.Disagreement,
+				})
+				if encodeErr == nil {
+					encodeErr = opts.Cache.SetWithTTL(purl, value, opts.CacheTTL)
+				}
+				if encodeErr != nil {
+					results[purl] = BatchLookup{Err: fmt.Errorf("failed to set license in cache: %w", encodeErr)}
+					continue
+				}
+			}
+			results[purl] = BatchLookup{Result: Result{
+				License:      answer.License,
+				Source:       SourceProvider,
+				Provider:     answer.Provider,
+				Disagreement: answer.Disagreement,
+			}}
 		}
 	}
+	return results, nil
+}
+
+// BatchLookup is the result or error of a package in LookupMany.
+type BatchLookup struct {
+	// Result is the license of the package and where it was found.
+	Result Result
+	// Err is the error of the lookup, as returned by Lookup.
+	Err error
+}
 
-	return license, nil
+// withoutPurls removes the purls from the results.
+func withoutPurls(results map[string]BatchLookup, purls []string) map[string]BatchLookup {
+	for _, purl := range purls {
+		delete(results, purl)
+	}
+	return results
+}
+
+// resolve gets the license from the provider, and the name of the provider that found it if it is a Resolver.
+func resolve(ctx context.Context, p Provider, purl string) (Resolution, error) {
+	if resolver, ok := p.(Resolver); ok {
+		return resolver.Resolve(ctx, purl)
+	}
+	license, err := p.Get(ctx, purl)
+	return Resolution{License: license}, err
 }
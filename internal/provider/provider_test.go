@@ -0,0 +1,233 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestLookup_Sources tests that curations are consulted before the cache and the provider.
+func TestLookup_Sources(t *testing.T) {
+	t.Parallel()
+
+	curations, err := curation.Parse([]byte(`curations:
+  - purl: pkg:npm/curated
+    license: BSD-3-Clause
+    reviewer: jane@example.com
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		purl        string
+		wantLicense string
+		wantSource  provider.Source
+	}{
+		{name: "curation", purl: "pkg:npm/curated@1.0.0", wantLicense: "BSD-3-Clause", wantSource: provider.SourceCuration},
+		{name: "cache", purl: "pkg:npm/cached@1.0.0", wantLicense: "MIT", wantSource: provider.SourceCache},
+		{name: "provider", purl: "pkg:npm/other@1.0.0", wantLicense: "Apache-2.0", wantSource: provider.SourceProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCache := newMockCache()
+			mockCache.data["pkg:npm/cached@1.0.0"] = "MIT"
+			mockCache.data["pkg:npm/curated@1.0.0"] = "GPL-3.0-only"
+			mockProv := &mockProvider{license: "Apache-2.0"}
+
+			result, lookupErr := provider.Lookup(context.Background(), provider.GetOptions{
+				Purl:      tt.purl,
+				Provider:  mockProv,
+				Cache:     mockCache,
+				CacheTTL:  time.Hour,
+				Curations: curations,
+			})
+			if lookupErr != nil {
+				t.Fatalf("Lookup() error = %v", lookupErr)
+			}
+			if result.License != tt.wantLicense || result.Source != tt.wantSource {
+				t.Errorf("Lookup() = %+v, want %s from %s", result, tt.wantLicense, tt.wantSource)
+			}
+			if (result.Curation != nil) != (tt.wantSource == provider.SourceCuration) {
+				t.Errorf("Lookup() Curation = %+v", result.Curation)
+			}
+			if tt.wantSource == provider.SourceCuration && mockCache.setCalls != 0 {
+				t.Errorf("Cache.Set() called %d times, want curated licenses not to be cached", mockCache.setCalls)
+			}
+		})
+	}
+}
+
+// TestLookup_Provider tests that the provider of a chain that found a license is cached with it.
+func TestLookup_Provider(t *testing.T) {
+	t.Parallel()
+
+	mockCache := newMockCache()
+	mockCache.data["pkg:npm/legacy@1.0.0"] = "ISC"
+	depsDev := &mockProvider{license: "MIT"}
+	opts := provider.GetOptions{
+		Provider: provider.NewChain([]provider.Link{
+			{Name: "local", Provider: &mockProvider{err: provider.ErrLicenseNotFound}},
+			{Name: "depsdev", Provider: depsDev},
+		}),
+		Cache:    mockCache,
+		CacheTTL: time.Hour,
+	}
+
+	tests := []struct {
+		purl string
+		want provider.Result
+	}{
+		{
+			purl: "pkg:npm/lodash@4.17.21",
+			want: provider.Result{License: "MIT", Source: provider.SourceProvider, Provider: "depsdev"},
+		},
+		{
+			purl: "pkg:npm/lodash@4.17.21",
+			want: provider.Result{License: "MIT", Source: provider.SourceCache, Provider: "depsdev"},
+		},
+		// Licenses cached without a provider name are plain strings
+		{purl: "pkg:npm/legacy@1.0.0", want: provider.Result{License: "ISC", Source: provider.SourceCache}},
+	}
+
+	for _, tt := range tests {
+		opts.Purl = tt.purl
+		got, err := provider.Lookup(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", tt.purl, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%q) = %+v, want %+v", tt.purl, got, tt.want)
+		}
+	}
+	if depsDev.getCalls != 1 {
+		t.Errorf("provider called %d times, want 1", depsDev.getCalls)
+	}
+}
+
+// TestLookup_Disagreement tests that disagreeing licenses of a consensus are cached with the license.
+func TestLookup_Disagreement(t *testing.T) {
+	t.Parallel()
+
+	answers := []provider.Answer{
+		{Provider: "ecosystems", License: "MIT"},
+		{Provider: "clearlydefined", License: "MIT AND BSD-3-Clause"},
+	}
+	opts := provider.GetOptions{
+		Purl: "pkg:npm/lodash@4.17.21",
+		Provider: provider.NewConsensus([]provider.Link{
+			{Name: answers[0].Provider, Provider: &mockProvider{license: answers[0].License}},
+			{Name: answers[1].Provider, Provider: &mockProvider{license: answers[1].License}},
+		}, provider.StrategyUnion),
+		Cache:    newMockCache(),
+		CacheTTL: time.Hour,
+	}
+
+	for _, source := range []provider.Source{provider.SourceProvider, provider.SourceCache} {
+		got, err := provider.Lookup(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Lookup() error = %v", err)
+		}
+		want := provider.Result{
+			License:      "MIT AND BSD-3-Clause",
+			Source:       source,
+			Provider:     "ecosystems,clearlydefined",
+			Disagreement: answers,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Lookup() = %+v, want %+v", got, want)
+		}
+	}
+}
+
+// mockBatchProvider implements the provider.BatchProvider interface for testing.
+type mockBatchProvider struct {
+	mockProvider
+
+	licenses map[string]string
+	batches  [][]string
+}
+
+func (m *mockBatchProvider) GetMany(_ context.Context, purls []string) (map[string]provider.BatchResult, error) {
+	m.batches = append(m.batches, purls)
+	if m.err != nil {
+		return nil, m.err
+	}
+	results := make(map[string]provider.BatchResult, len(purls))
+	for _, purl := range purls {
+		if license, ok := m.licenses[purl]; ok {
+			results[purl] = provider.BatchResult{License: license}
+		}
+	}
+	return results, nil
+}
+
+// TestLookupMany tests that only packages that are not curated or cached are looked up in the batch.
+func TestLookupMany(t *testing.T) {
+	t.Parallel()
+
+	curations, err := curation.Parse([]byte(`curations:
+  - purl: pkg:npm/curated
+    license: BSD-3-Clause
+    reviewer: jane@example.com
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	mockCache := newMockCache()
+	mockCache.data["pkg:npm/cached@1.0.0"] = "MIT"
+	mockProv := &mockBatchProvider{licenses: map[string]string{"pkg:npm/other@1.0.0": "Apache-2.0"}}
+	opts := provider.GetOptions{Provider: mockProv, Cache: mockCache, CacheTTL: time.Hour, Curations: curations}
+
+	results, err := provider.LookupMany(context.Background(), opts, []string{
+		"pkg:npm/curated@1.0.0",
+		"pkg:npm/cached@1.0.0",
+		"pkg:npm/other@1.0.0",
+		"pkg:npm/other@1.0.0",
+		"pkg:npm/unanswered@1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("LookupMany() error = %v", err)
+	}
+
+	want := map[string]provider.Source{
+		"pkg:npm/curated@1.0.0": provider.SourceCuration,
+		"pkg:npm/cached@1.0.0":  provider.SourceCache,
+		"pkg:npm/other@1.0.0":   provider.SourceProvider,
+	}
+	if len(results) != len(want) {
+		t.Errorf("LookupMany() = %+v, want results for %v", results, want)
+	}
+	for purl, source := range want {
+		if result := results[purl]; result.Err != nil || result.Result.Source != source {
+			t.Errorf("LookupMany()[%s] = %+v, want source %s", purl, result, source)
+		}
+	}
+	wantBatches := [][]string{{"pkg:npm/other@1.0.0", "pkg:npm/unanswered@1.0.0"}}
+	if !reflect.DeepEqual(mockProv.batches, wantBatches) {
+		t.Errorf("batches = %v, want %v", mockProv.batches, wantBatches)
+	}
+	if mockCache.data["pkg:npm/other@1.0.0"] != "Apache-2.0" {
+		t.Errorf("cache = %v, want the license of the batch cached", mockCache.data)
+	}
+
+	// A failed batch leaves the curated and cached results
+	mockProv.err = provider.ErrUnavailable
+	results, err = provider.LookupMany(context.Background(), opts, []string{"pkg:npm/curated@1.0.0", "pkg:npm/new@1.0.0"})
+	if !errors.Is(err, provider.ErrUnavailable) {
+		t.Errorf("LookupMany() error = %v, want %v", err, provider.ErrUnavailable)
+	}
+	if _, ok := results["pkg:npm/curated@1.0.0"]; !ok || len(results) != 1 {
+		t.Errorf("LookupMany() = %+v, want the curated result only", results)
+	}
+}
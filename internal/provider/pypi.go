@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+const (
+	// pypiIndexURL is the URL of the public Python Package Index.
+	//
+	// See https://docs.pypi.org/api/json/
+	pypiIndexURL = "https://pypi.org"
+	// pypiClassifierPrefix is the prefix of trove classifiers that declare a license.
+	pypiClassifierPrefix = "License :: "
+	// pypiUnknown is the license of packages built by old tools without license metadata.
+	pypiUnknown = "UNKNOWN"
+)
+
+// PyPIClient gets licenses from the JSON API of a Python package index, such as PyPI or a devpi or Artifactory
+// mirror.
+type PyPIClient struct {
+	indexURL string
+	client   *http.Client
+}
+
+var _ Provider = (*PyPIClient)(nil)
+
+// PyPIOptions are the options for the PyPIClient.
+type PyPIOptions struct {
+	// IndexURL is the URL of the package index serving the "/pypi/<name>/<version>/json" API.
+	// If empty, defaults to the public PyPI.
+	IndexURL string
+	// Client is the HTTP client to use for the package index.
+	// If nil, defaults to an HTTP client with timeout.
+	Client *http.Client
+}
+
+// NewPyPIClient creates a new PyPIClient.
+func NewPyPIClient(opts PyPIOptions) *PyPIClient {
+	indexURL := pypiIndexURL
+	if opts.IndexURL != "" {
+		indexURL = strings.TrimSuffix(opts.IndexURL, "/")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultHTTPTimeout,
+		}
+	}
+
+	return &PyPIClient{
+		indexURL: indexURL,
+		client:   client,
+	}
+}
+
+// pypiRelease is a release document of the PyPI JSON API.
+type pypiRelease struct {
+	Info struct {
+		// LicenseExpression is the PEP 639 License-Expression core metadata field
+		LicenseExpression string   `json:"license_expression"`
+		Classifiers       []string `json:"classifiers"`
+		License           string   `json:"license"`
+	} `json:"info"`
+}
+
+// Get gets the license of a release from the package index. Purls without version get the latest release.
+//
+// The PEP 639 license expression is preferred. Otherwise the license classifiers are mapped to SPDX identifiers
+// and combined with OR, and as a last resort the free-text license field is used if it names a known license.
+func (c *PyPIClient) Get(ctx context.Context, rawPurl string) (string, error) {
+	parsed, err := purl.Parse(rawPurl)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type != "pypi" {
+		return "", fmt.Errorf("%w: purl type %q is not supported by the Python package index",
+			ErrLicenseNotFound, parsed.Type)
+	}
+
+	apiURL := c.indexURL + "/pypi/" + url.PathEscape(parsed.Name)
+	if parsed.Version != "" {
+		apiURL += "/" + url.PathEscape(parsed.Version)
+	}
+	apiURL += "/json"
+
+	var release pypiRelease
+	if err = getJSON(ctx, c.client, apiURL, nil, &release); err != nil {
+		return "", err
+	}
+
+	if l := pypiLicense(release.Info.LicenseExpression, release.Info.Classifiers, release.Info.License); l != "" {
+		return l, nil
+	}
+	return "", fmt.Errorf("%w: no licenses found for %s", ErrLicenseNotFound, rawPurl)
+}
+
+// pypiLicense returns the license of core metadata, from the license expression, the license classifiers or
+// the free-text license field in this order, or "" if none is known.
+func pypiLicense(expression string, classifiers []string, text string) string {
+	if expression = strings.TrimSpace(expression); expression != "" {
+		return expression
+	}
+	if l := pypiClassifierLicense(classifiers); l != "" {
+		return l
+	}
+	return pypiFreeTextLicense(text)
+}
+
+// pypiClassifierLicense maps license classifiers, such as "License :: OSI Approved :: MIT License", to SPDX
+// identifiers combined with OR. Classifiers without a specific license, such as "License :: OSI Approved :: BSD
+// License", are ignored.
+func pypiClassifierLicense(classifiers []string) string {
+	var licenses []string
+	for _, classifier := range classifiers {
+		if !strings.HasPrefix(classifier, pypiClassifierPrefix) {
+			continue
+		}
+		segments := strings.Split(classifier, "::")
+		id, ok := license.FromName(segments[len(segments)-1])
+		if ok && !slices.Contains(licenses, id) {
+			licenses = append(licenses, id)
+		}
+	}
+	return license.Join(license.OperatorOr, licenses)
+}
+
+// pypiFreeTextLicense maps the free-text license field to an SPDX expression. The field often holds a license
+// name, an SPDX expression or the full license text; only names and expressions of known licenses are mapped.
+func pypiFreeTextLicense(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" || text == pypiUnknown || strings.Contains(text, "\n") {
+		return ""
+	}
+	if id, ok := license.FromName(text); ok {
+		return id
+	}
+
+	expression, err := license.Parse(text)
+	if err != nil {
+		return ""
+	}
+	for _, leaf := range expression.Leaves() {
+		if _, ok := license.FromName(leaf.License); !ok {
+			return ""
+		}
+	}
+	return expression.Normalize().String()
+}
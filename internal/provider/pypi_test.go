@@ -0,0 +1,136 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestPyPIClient_Get tests getting licenses from a stub PyPI JSON API.
+func TestPyPIClient_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		purl         string
+		wantPath     string
+		mockResponse string
+		want         string
+		wantErrIs    error
+	}{
+		{
+			name:     "license expression preferred",
+			purl:     "pkg:pypi/attrs@24.1.0",
+			wantPath: "/simple/pypi/attrs/24.1.0/json",
+			mockResponse: `{"info": {"license_expression": "MIT", "license": "BSD",
+				"classifiers": ["License :: OSI Approved :: Apache Software License"]}}`,
+			want: "MIT",
+		},
+		{
+			name:     "classifiers",
+			purl:     "pkg:pypi/requests@2.28.0",
+			wantPath: "/simple/pypi/requests/2.28.0/json",
+			mockResponse: `{"info": {"license": "Apache 2.0", "classifiers": [
+				"Programming Language :: Python :: 3",
+				"License :: OSI Approved :: Apache Software License"]}}`,
+			want: "Apache-2.0",
+		},
+		{
+			name:     "several classifiers",
+			purl:     "pkg:pypi/dual@1.0.0",
+			wantPath: "/simple/pypi/dual/1.0.0/json",
+			mockResponse: `{"info": {"classifiers": [
+				"License :: OSI Approved :: GNU General Public License v2 or later (GPLv2+)",
+				"License :: OSI Approved :: BSD License",
+				"License :: OSI Approved :: MIT License"]}}`,
+			want: "GPL-2.0-or-later OR MIT",
+		},
+		{
+			name:         "free-text license name",
+			purl:         "pkg:pypi/old@1.0.0",
+			wantPath:     "/simple/pypi/old/1.0.0/json",
+			mockResponse: `{"info": {"license": "new BSD license", "classifiers": ["License :: OSI Approved"]}}`,
+			want:         "BSD-3-Clause",
+		},
+		{
+			name:         "free-text license expression",
+			purl:         "pkg:pypi/old@1.0.0",
+			wantPath:     "/simple/pypi/old/1.0.0/json",
+			mockResponse: `{"info": {"license": "Apache-2.0 OR GPL-2.0"}}`,
+			want:         "Apache-2.0 OR GPL-2.0-only",
+		},
+		{
+			name:         "latest release",
+			purl:         "pkg:pypi/requests",
+			wantPath:     "/simple/pypi/requests/json",
+			mockResponse: `{"info": {"license_expression": "Apache-2.0"}}`,
+			want:         "Apache-2.0",
+		},
+		{
+			name:         "license text",
+			purl:         "pkg:pypi/text@1.0.0",
+			wantPath:     "/simple/pypi/text/1.0.0/json",
+			mockResponse: `{"info": {"license": "Copyright (c) 2020\n\nPermission is hereby granted"}}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+		{
+			name:         "unknown license",
+			purl:         "pkg:pypi/unknown@1.0.0",
+			wantPath:     "/simple/pypi/unknown/1.0.0/json",
+			mockResponse: `{"info": {"license": "UNKNOWN", "classifiers": []}}`,
+			wantErrIs:    provider.ErrLicenseNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != tt.wantPath {
+					t.Errorf("path = %q, want %q", r.URL.EscapedPath(), tt.wantPath)
+				}
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			t.Cleanup(server.Close)
+
+			client := provider.NewPyPIClient(provider.PyPIOptions{IndexURL: server.URL + "/simple/"})
+
+			got, err := client.Get(context.Background(), tt.purl)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPyPIClient_Get_Errors tests unsupported purls and index errors.
+func TestPyPIClient_Get_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	client := provider.NewPyPIClient(provider.PyPIOptions{IndexURL: server.URL})
+
+	for _, purl := range []string{"pkg:npm/lodash@4.17.21", "pkg:pypi/missing@1.0.0"} {
+		if _, err := client.Get(context.Background(), purl); !errors.Is(err, provider.ErrLicenseNotFound) {
+			t.Errorf("Get(%q) error = %v, want %v", purl, err, provider.ErrLicenseNotFound)
+		}
+	}
+}
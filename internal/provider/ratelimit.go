@@ -0,0 +1,308 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitResetEpoch separates reset headers in seconds until the reset from those in Unix time.
+const rateLimitResetEpoch = 1_000_000_000
+
+// errLimiterDeadline is returned, along with context.DeadlineExceeded, when a request would have to wait for the
+// rate limiter past the deadline. The API is throttling rather than failing, so it is neither retried nor counted
+// by circuit breakers.
+var errLimiterDeadline = errors.New("rate limiter wait exceeds deadline")
+
+// RateLimiterOptions are the options for the RateLimiter.
+type RateLimiterOptions struct {
+	// Name identifies the limiter in logs and metrics, e.g. "ecosystems".
+	Name string
+	// Rate is the number of requests per second.
+	// If zero, requests are only limited by the rate limit headers of the API.
+	Rate float64
+	// Burst is the number of requests that can be made at once.
+	// If zero, defaults to 1.
+	Burst int
+	// Logger logs waits and adaptations at debug level.
+	// If nil, nothing is logged.
+	Logger *slog.Logger
+}
+
+// RateLimiterStats are the counters of a RateLimiter.
+type RateLimiterStats struct {
+	// Requests is the number of requests made through the limiter.
+	Requests uint64
+	// Waits is the number of requests that had to wait.
+	Waits uint64
+	// WaitTime is the total time requests waited.
+	WaitTime time.Duration
+	// Throttled is the number of responses that lowered the rate or paused requests.
+	Throttled uint64
+}
+
+// RateLimiter is a token bucket limiting the requests of HTTP clients. Use one limiter per API and share it
+// between all clients of the API in a process, so that concurrent workers and requests stay within the
+// limits together.
+//
+// The limiter adapts to the rate limit headers of the API: when "RateLimit-Remaining" or
+// "X-RateLimit-Remaining" says few requests are left until "RateLimit-Reset" or "X-RateLimit-Reset", the
+// remaining requests are spread until the reset, and a "Retry-After" of a 429 or 503 response pauses all
+// requests.
+//
+// It is safe for concurrent use.
+type RateLimiter struct {
+	name   string
+	rate   float64
+	burst  float64
+	logger *slog.Logger
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	adaptedRate  float64
+	adaptedUntil time.Time
+	pausedUntil  time.Time
+	stats        RateLimiterStats
+}
+
+// NewRateLimiter creates a new RateLimiter with a full bucket.
+func NewRateLimiter(opts RateLimiterOptions) *RateLimiter {
+	burst := float64(max(opts.Burst, 1))
+	return &RateLimiter{
+		name:   opts.Name,
+		rate:   opts.Rate,
+		burst:  burst,
+		logger: opts.Logger,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be made or the context is done. It fails right away if the request would have
+// to wait past the deadline of the context.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		// Fail right away instead of waiting for a deadline that comes first, e.g. during a long pause
+		l.cancel()
+		return fmt.Errorf("%w: %w (wait of %s)", context.DeadlineExceeded, errLimiterDeadline, delay)
+	}
+	if l.logger != nil {
+		l.logger.DebugContext(ctx, "waiting for rate limiter", "limiter", l.name, "delay", delay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token and returns how long the request has to wait for it.
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Requests++
+	var delay time.Duration
+	if rate := l.currentRate(now); rate > 0 {
+		if elapsed := now.Sub(l.last); elapsed > 0 {
+			l.tokens = min(l.burst, l.tokens+elapsed.Seconds()*rate)
+			l.last = now
+		}
+		l.tokens--
+		if l.tokens < 0 {
+			delay = time.Duration(-l.tokens / rate * float64(time.Second))
+		}
+	}
+	delay = max(delay, l.pausedUntil.Sub(now))
+
+	if delay > 0 {
+		l.stats.Waits++
+		l.stats.WaitTime += delay
+	}
+	return delay
+}
+
+// cancel returns the token of a request that stopped waiting.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.currentRate(time.Now()) > 0 {
+		l.tokens = min(l.burst, l.tokens+1)
+	}
+}
+
+// currentRate returns the rate in requests per second, lowered by the rate limit headers until the reset.
+func (l *RateLimiter) currentRate(now time.Time) float64 {
+	if now.Before(l.adaptedUntil) && (l.rate <= 0 || l.adaptedRate < l.rate) {
+		return l.adaptedRate
+	}
+	return l.rate
+}
+
+// Observe adapts the limiter to the rate limit headers of a response.
+func (l *RateLimiter) Observe(response *http.Response) {
+	now := time.Now()
+	header := response.Header
+
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := parseRetryAfter(header.Get("Retry-After"), now); ok && delay > 0 {
+			l.pause(now.Add(delay), "retry after")
+			return
+		}
+	}
+
+	remaining, err := strconv.Atoi(firstHeader(header, "RateLimit-Remaining", "X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, ok := parseRateLimitReset(firstHeader(header, "RateLimit-Reset", "X-RateLimit-Reset"), now)
+	if !ok || !reset.After(now) {
+		return
+	}
+	if remaining <= 0 {
+		l.pause(reset, "no requests remaining")
+		return
+	}
+
+	rate := float64(remaining) / reset.Sub(now).Seconds()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate > 0 && rate >= l.rate {
+		return
+	}
+	adapted := now.Before(l.adaptedUntil)
+	l.adaptedRate, l.adaptedUntil = rate, reset
+	if adapted {
+		return
+	}
+	// Spread the remaining requests evenly until the reset, instead of spending the burst at once
+	l.tokens, l.last = min(l.tokens, 0), now
+	l.stats.Throttled++
+	if l.logger != nil {
+		l.logger.Debug("rate limiter adapted to API limits",
+			"limiter", l.name, "remaining", remaining, "reset", reset, "rate", rate)
+	}
+}
+
+// pause blocks all requests until the time.
+func (l *RateLimiter) pause(until time.Time, reason string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !until.After(l.pausedUntil) {
+		return
+	}
+	l.pausedUntil = until
+	l.stats.Throttled++
+	if l.logger != nil {
+		l.logger.Debug("rate limiter paused", "limiter", l.name, "until", until, "reason", reason)
+	}
+}
+
+// Stats returns the counters of the limiter.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// WriteMetrics writes the counters of the limiter in the Prometheus text format.
+func (l *RateLimiter) WriteMetrics(w io.Writer) error {
+	stats := l.Stats()
+	metrics := []struct {
+		name, help string
+		value      float64
+	}{
+		{"requests_total", "Requests made through the rate limiter.", float64(stats.Requests)},
+		{"waits_total", "Requests that waited for the rate limiter.", float64(stats.Waits)},
+		{"wait_seconds_total", "Time requests waited for the rate limiter.", stats.WaitTime.Seconds()},
+		{"throttled_total", "Responses that lowered the rate or paused requests.", float64(stats.Throttled)},
+	}
+	for _, metric := range metrics {
+		name := "sbomlicense_ratelimit_" + metric.name
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s{limiter=%q} %g\n",
+			name, metric.help, name, name, l.name, metric.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transport returns an http.RoundTripper that waits for the limiter before each request of base and observes
+// the responses. If base is nil, http.DefaultTransport is used.
+func (l *RateLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{limiter: l, base: base}
+}
+
+// rateLimitTransport is an http.RoundTripper limited by a RateLimiter.
+type rateLimitTransport struct {
+	limiter *RateLimiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	response, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.limiter.Observe(response)
+	return response, nil
+}
+
+// limitClient returns a copy of the client whose requests are limited by the limiter, or the client itself
+// if the limiter is nil.
+func limitClient(client *http.Client, limiter *RateLimiter) *http.Client {
+	if limiter == nil {
+		return client
+	}
+	limited := *client
+	limited.Transport = limiter.Transport(client.Transport)
+	return &limited
+}
+
+// firstHeader returns the first non-empty value of the header keys.
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseRateLimitReset parses a rate limit reset header, in seconds until the reset or in Unix time.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds >= rateLimitResetEpoch {
+		return time.Unix(seconds, 0), true
+	}
+	return now.Add(time.Duration(seconds) * time.Second), true
+}
@@ -0,0 +1,210 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boringbin/sbomlicense/internal/provider"
+)
+
+// TestRateLimiter_Wait tests that requests beyond the burst wait for the rate.
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	limiter := provider.NewRateLimiter(provider.RateLimiterOptions{Rate: 50, Burst: 2})
+
+	start := time.Now()
+	for range 5 {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	// 2 requests of the burst, then 3 requests at 20ms each
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Wait() took %s for 5 requests, want at least 50ms", elapsed)
+	}
+	// Requests that were slow to arrive may find a refilled token, so only check that some waited
+	stats := limiter.Stats()
+	if stats.Requests != 5 || stats.Waits == 0 || stats.Waits > 3 || stats.WaitTime <= 0 {
+		t.Errorf("Stats() = %+v, want 5 requests and up to 3 waits", stats)
+	}
+}
+
+// TestRateLimiter_Observe tests adapting to the rate limit headers of responses.
+func TestRateLimiter_Observe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		rate      float64
+		status    int
+		header    map[string]string
+		wantWait  bool
+		throttled uint64
+	}{
+		{name: "no headers", status: http.StatusOK},
+		{
+			name:      "no requests remaining",
+			status:    http.StatusOK,
+			header:    map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "60"},
+			wantWait:  true,
+			throttled: 1,
+		},
+		{
+			name:   "no requests remaining until unix time",
+			status: http.StatusOK,
+			header: map[string]string{
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10),
+			},
+			wantWait:  true,
+			throttled: 1,
+		},
+		{
+			name:      "few requests remaining",
+			status:    http.StatusOK,
+			header:    map[string]string{"RateLimit-Remaining": "1", "RateLimit-Reset": "100"},
+			wantWait:  true,
+			throttled: 1,
+		},
+		{
+			name:   "more requests remaining than the rate",
+			rate:   1000,
+			status: http.StatusOK,
+			header: map[string]string{"RateLimit-Remaining": "1000", "RateLimit-Reset": "1"},
+		},
+		{
+			name:      "retry after",
+			status:    http.StatusTooManyRequests,
+			header:    map[string]string{"Retry-After": "60"},
+			wantWait:  true,
+			throttled: 1,
+		},
+		{
+			name:   "retry after of a successful response",
+			status: http.StatusOK,
+			header: map[string]string{"Retry-After": "60"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := provider.NewRateLimiter(provider.RateLimiterOptions{Rate: tt.rate})
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait() error = %v", err)
+			}
+
+			response := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for key, value := range tt.header {
+				response.Header.Set(key, value)
+			}
+			limiter.Observe(response)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := limiter.Wait(ctx)
+			if tt.wantWait && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if !tt.wantWait && err != nil {
+				t.Errorf("Wait() unexpected error = %v", err)
+			}
+			if throttled := limiter.Stats().Throttled; throttled != tt.throttled {
+				t.Errorf("Stats().Throttled = %d, want %d", throttled, tt.throttled)
+			}
+		})
+	}
+}
+
+// TestClient_Get_RateLimiter tests that the requests of clients sharing a limiter are limited together.
+func TestClient_Get_RateLimiter(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"normalized_licenses": ["MIT"]}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	// The burst is shared by both clients, and the next token takes far longer than the test
+	limiter := provider.NewRateLimiter(provider.RateLimiterOptions{Name: "ecosystems", Rate: 0.001, Burst: 2})
+	clients := []*provider.Client{
+		provider.NewClient(provider.ClientOptions{BaseURL: server.URL, RateLimiter: limiter}),
+		provider.NewClient(provider.ClientOptions{BaseURL: server.URL, RateLimiter: limiter}),
+	}
+	for _, client := range clients {
+		if _, err := client.Get(context.Background(), "pkg:npm/test@1.0.0"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := clients[0].Get(ctx, "pkg:npm/test@1.0.0"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if stats := limiter.Stats(); stats.Requests != 3 || stats.Waits != 1 {
+		t.Errorf("Stats() = %+v, want 3 requests and 1 wait", stats)
+	}
+
+	var metrics strings.Builder
+	if err := limiter.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE sbomlicense_ratelimit_requests_total counter\n",
+		`sbomlicense_ratelimit_requests_total{limiter="ecosystems"} 3` + "\n",
+		`sbomlicense_ratelimit_waits_total{limiter="ecosystems"} 1` + "\n",
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("WriteMetrics() = %q, want it to contain %q", metrics.String(), want)
+		}
+	}
+}
+
+// TestClient_Get_RateLimiterDeadline tests that a lookup refused by the rate limiter because it would wait past the
+// deadline is neither retried nor counted as a failure of the provider.
+func TestClient_Get_RateLimiterDeadline(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`[{"normalized_licenses": ["MIT"]}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	limiter := provider.NewRateLimiter(provider.RateLimiterOptions{Rate: 0.001})
+	breaker := provider.NewBreaker(
+		provider.NewClient(provider.ClientOptions{BaseURL: server.URL, RateLimiter: limiter}),
+		provider.BreakerOptions{Threshold: 1, Cooldown: time.Hour},
+	)
+	if _, err := breaker.Get(context.Background(), "pkg:npm/test@1.0.0"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := breaker.Get(ctx, "pkg:npm/test@1.0.0")
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, provider.ErrUnavailable) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server called %d times, want 1", n)
+	}
+	if stats := limiter.Stats(); stats.Requests != 2 {
+		t.Errorf("Stats() = %+v, want 2 requests without retries", stats)
+	}
+	if status := breaker.Status(); status.State != provider.BreakerClosed || status.Failures != 0 {
+		t.Errorf("Status() = %+v, want %s without failures", status, provider.BreakerClosed)
+	}
+}
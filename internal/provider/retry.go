@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is the default number of retries of a request.
+	defaultMaxRetries = 3
+	// defaultInitialBackoff is the default delay before the first retry.
+	defaultInitialBackoff = time.Second
+	// defaultMaxBackoff is the default maximum delay between retries.
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryOptions are the options for retrying requests that were rate limited or failed because the service was
+// unavailable.
+//
+// The delay doubles after each attempt, with jitter, unless the server sends a Retry-After header. Retries stop
+// when the context is done, and are not attempted if the delay would exceed MaxBackoff or the deadline of the
+// context.
+type RetryOptions struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	// If zero, defaults to 3. If negative, requests are not retried.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry.
+	// If zero, defaults to 1 second.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries. If the Retry-After of the server is longer, the request
+	// is not retried.
+	// If zero, defaults to 30 seconds.
+	MaxBackoff time.Duration
+}
+
+// withDefaults returns the options with defaults for unset fields.
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.MaxRetries == 0 {
+		o.MaxRetries = defaultMaxRetries
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = defaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultMaxBackoff
+	}
+	return o
+}
+
+// retryAfterError is an error with the delay the server asked for in a Retry-After header.
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retry calls fn until it succeeds, fails with an error other than ErrRateLimited or ErrUnavailable, or the
+// retries are exhausted.
+func retry(ctx context.Context, opts RetryOptions, fn func() error) error {
+	opts = opts.withDefaults()
+	backoff := opts.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= opts.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+		if !errors.Is(err, ErrRateLimited) && !errors.Is(err, ErrUnavailable) {
+			return err
+		}
+
+		// Wait between half and all of the backoff, so that concurrent clients spread out
+		delay := backoff/2 + rand.N(backoff/2+1) //nolint:gosec // jitter does not need a secure random source
+		var retryAfter *retryAfterError
+		if errors.As(err, &retryAfter) {
+			delay = retryAfter.delay
+		}
+		if delay > opts.MaxBackoff {
+			return fmt.Errorf("%w (retry in %s exceeds maximum backoff)", err, delay)
+		}
+		backoff = min(2*backoff, opts.MaxBackoff)
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return fmt.Errorf("%w (retry in %s exceeds deadline)", err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
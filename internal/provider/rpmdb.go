@@ -0,0 +1,529 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The rpm database stores the header of each installed package. Since rpm 4.16 the database is SQLite; older
+// releases use a BerkeleyDB hash database. Both are read without the native libraries. The ndb format of SUSE
+// is not supported.
+
+const (
+	// rpmTagName etc. are the header tags read from rpm headers.
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagEpoch   = 1003
+	rpmTagLicense = 1014
+	// rpmTypeInt32 etc. are the types of header tags.
+	rpmTypeInt32      = 4
+	rpmTypeString     = 6
+	rpmTypeI18NString = 9
+	// rpmIndexEntrySize is the size of an entry in the index of a header.
+	rpmIndexEntrySize = 16
+
+	// sqliteHeader is the magic string at the start of SQLite databases.
+	sqliteHeader = "SQLite format 3\x00"
+	// sqliteHeaderSize is the size of the database header on the first page.
+	sqliteHeaderSize = 100
+	// sqliteInteriorTable and sqliteLeafTable are the types of table B-tree pages.
+	sqliteInteriorTable = 0x05
+	sqliteLeafTable     = 0x0d
+	// sqliteMaxDepth is the maximum depth of a table B-tree.
+	sqliteMaxDepth = 32
+
+	// bdbHashMagic is the magic number of BerkeleyDB hash databases.
+	bdbHashMagic = 0x061561
+	// bdbPageHeaderSize is the size of the header of BerkeleyDB pages.
+	bdbPageHeaderSize = 26
+	// bdbPageHashUnsorted etc. are the types of BerkeleyDB pages.
+	bdbPageHashUnsorted = 2
+	bdbPageOverflow     = 7
+	bdbPageHash         = 13
+	// bdbKeyData and bdbOffPage are the types of items on hash pages.
+	bdbKeyData = 1
+	bdbOffPage = 3
+	// bdbOffPageSize is the size of an item that refers to overflow pages.
+	bdbOffPageSize = 12
+)
+
+// errInvalidDatabase is returned when a database file is corrupt or in an unknown format.
+var errInvalidDatabase = errors.New("invalid database")
+
+// rpmDatabases are the paths of the rpm database, relative to the root of the file system.
+//
+//nolint:gochecknoglobals // constant lookup table
+var rpmDatabases = []string{
+	"usr/lib/sysimage/rpm/rpmdb.sqlite",
+	"var/lib/rpm/rpmdb.sqlite",
+	"usr/lib/sysimage/rpm/Packages",
+	"var/lib/rpm/Packages",
+}
+
+// rpmPackage is a package in the rpm database.
+type rpmPackage struct {
+	name    string
+	version string
+	release string
+	epoch   string
+	license string
+}
+
+// readRPMDatabase returns the packages in the rpm database, by package name. It returns no packages if the file
+// system has no rpm database.
+func readRPMDatabase(root *os.Root) (map[string][]rpmPackage, error) {
+	packages := make(map[string][]rpmPackage)
+	for _, name := range rpmDatabases {
+		file, err := root.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open rpm database: %w", err)
+		}
+		defer file.Close()
+
+		add := func(blob []byte) {
+			// Skip damaged headers rather than the whole database
+			if pkg, err := parseRPMHeader(blob); err == nil {
+				packages[pkg.name] = append(packages[pkg.name], pkg)
+			}
+		}
+		if strings.HasSuffix(name, ".sqlite") {
+			err = readSQLiteBlobs(file, "Packages", add)
+		} else {
+			err = readBDBHashValues(file, add)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: rpm database %s: %w", ErrInvalidResponse, name, err)
+		}
+		return packages, nil
+	}
+	return packages, nil
+}
+
+// parseRPMHeader parses the name, version, release, epoch and license of an rpm header as stored in the rpm
+// database: the number of index entries and the size of the data, the index and the data.
+//
+// See https://rpm-software-management.github.io/rpm/manual/format_header.html
+func parseRPMHeader(blob []byte) (rpmPackage, error) {
+	const preambleSize = 8
+	if len(blob) < preambleSize {
+		return rpmPackage{}, fmt.Errorf("%w: rpm header too short", errInvalidDatabase)
+	}
+	entries := uint64(binary.BigEndian.Uint32(blob))
+	size := uint64(binary.BigEndian.Uint32(blob[4:]))
+	start := preambleSize + entries*rpmIndexEntrySize
+	if start+size > uint64(len(blob)) {
+		return rpmPackage{}, fmt.Errorf("%w: rpm header truncated", errInvalidDatabase)
+	}
+	data := blob[start : start+size]
+
+	var pkg rpmPackage
+	for i := range entries {
+		entry := blob[preambleSize+i*rpmIndexEntrySize:]
+		tag := binary.BigEndian.Uint32(entry)
+		typ := binary.BigEndian.Uint32(entry[4:])
+		offset := uint64(binary.BigEndian.Uint32(entry[8:]))
+		if offset >= size {
+			continue
+		}
+		if tag == rpmTagEpoch && typ == rpmTypeInt32 && offset+4 <= size {
+			pkg.epoch = strconv.FormatUint(uint64(binary.BigEndian.Uint32(data[offset:])), 10)
+			continue
+		}
+		if typ != rpmTypeString && typ != rpmTypeI18NString {
+			continue
+		}
+		value, _, _ := bytes.Cut(data[offset:], []byte{0})
+		switch tag {
+		case rpmTagName:
+			pkg.name = string(value)
+		case rpmTagVersion:
+			pkg.version = string(value)
+		case rpmTagRelease:
+			pkg.release = string(value)
+		case rpmTagLicense:
+			pkg.license = string(value)
+		}
+	}
+	if pkg.name == "" {
+		return rpmPackage{}, fmt.Errorf("%w: rpm header without name", errInvalidDatabase)
+	}
+	return pkg, nil
+}
+
+// sqliteFile reads the pages of a SQLite database.
+//
+// See https://www.sqlite.org/fileformat.html
+type sqliteFile struct {
+	file     *os.File
+	pageSize int
+	usable   int
+	pages    uint32
+}
+
+// readSQLiteBlobs calls fn with the first blob column of each row of a table in a SQLite database.
+func readSQLiteBlobs(file *os.File, table string, fn func([]byte)) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header := make([]byte, sqliteHeaderSize)
+	if _, err = file.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidDatabase, err)
+	}
+	if string(header[:len(sqliteHeader)]) != sqliteHeader {
+		return fmt.Errorf("%w: not a SQLite database", errInvalidDatabase)
+	}
+	pageSize := int(binary.BigEndian.Uint16(header[16:]))
+	if pageSize == 1 {
+		pageSize = math.MaxUint16 + 1
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 { //nolint:mnd // minimum page size
+		return fmt.Errorf("%w: invalid page size %d", errInvalidDatabase, pageSize)
+	}
+	usable := pageSize - int(header[20])
+	if usable < 480 { //nolint:mnd // minimum usable size
+		return fmt.Errorf("%w: invalid reserved space %d", errInvalidDatabase, header[20])
+	}
+	db := &sqliteFile{
+		file:     file,
+		pageSize: pageSize,
+		usable:   usable,
+		pages:    uint32(info.Size() / int64(pageSize)), //nolint:gosec // bounded by the file size
+	}
+
+	// The schema table on the first page has the columns type, name, tbl_name, rootpage and sql
+	var rootPage int64
+	if err = db.scan(1, 0, func(record []any) {
+		if len(record) >= 4 && record[0] == "table" && record[1] == table {
+			rootPage, _ = record[3].(int64)
+		}
+	}); err != nil {
+		return err
+	}
+	if rootPage <= 0 || rootPage > int64(db.pages) {
+		return fmt.Errorf("%w: no table %s", errInvalidDatabase, table)
+	}
+	return db.scan(uint32(rootPage), 0, func(record []any) {
+		for _, value := range record {
+			if blob, ok := value.([]byte); ok {
+				fn(blob)
+				return
+			}
+		}
+	})
+}
+
+// page reads a page by its number, starting at 1.
+func (db *sqliteFile) page(n uint32) ([]byte, error) {
+	if n == 0 || n > db.pages {
+		return nil, fmt.Errorf("%w: page %d out of range", errInvalidDatabase, n)
+	}
+	page := make([]byte, db.pageSize)
+	if _, err := db.file.ReadAt(page, int64(n-1)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("%w: page %d: %w", errInvalidDatabase, n, err)
+	}
+	return page, nil
+}
+
+// scan calls fn with the records of the table B-tree with the root page n, in rowid order.
+func (db *sqliteFile) scan(n uint32, depth int, fn func([]any)) error {
+	if depth > sqliteMaxDepth {
+		return fmt.Errorf("%w: table B-tree too deep", errInvalidDatabase)
+	}
+	page, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	header := page
+	if n == 1 {
+		header = page[sqliteHeaderSize:]
+	}
+
+	cells := int(binary.BigEndian.Uint16(header[3:]))
+	switch header[0] {
+	case sqliteInteriorTable:
+		// Interior cells are the page number of the left child and a rowid; the right-most child follows the
+		// cell count in the header
+		pointers := header[12:]
+		if len(pointers) < 2*cells {
+			return fmt.Errorf("%w: page %d has too many cells", errInvalidDatabase, n)
+		}
+		for i := range cells {
+			cell := int(binary.BigEndian.Uint16(pointers[2*i:]))
+			if cell+4 > len(page) {
+				return fmt.Errorf("%w: page %d has an invalid cell", errInvalidDatabase, n)
+			}
+			if err = db.scan(binary.BigEndian.Uint32(page[cell:]), depth+1, fn); err != nil {
+				return err
+			}
+		}
+		return db.scan(binary.BigEndian.Uint32(header[8:]), depth+1, fn)
+	case sqliteLeafTable:
+		pointers := header[8:]
+		if len(pointers) < 2*cells {
+			return fmt.Errorf("%w: page %d has too many cells", errInvalidDatabase, n)
+		}
+		for i := range cells {
+			payload, err := db.payload(page, int(binary.BigEndian.Uint16(pointers[2*i:])))
+			if err != nil {
+				return fmt.Errorf("page %d: %w", n, err)
+			}
+			record, err := sqliteRecord(payload)
+			if err != nil {
+				return fmt.Errorf("page %d: %w", n, err)
+			}
+			fn(record)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: page %d is not a table page", errInvalidDatabase, n)
+	}
+}
+
+// payload returns the payload of a cell on a table leaf page, following its overflow pages.
+func (db *sqliteFile) payload(page []byte, cell int) ([]byte, error) {
+	if cell >= len(page) {
+		return nil, fmt.Errorf("%w: invalid cell", errInvalidDatabase)
+	}
+	size, n := sqliteVarint(page[cell:])
+	_, m := sqliteVarint(page[cell+n:])
+	if n == 0 || m == 0 || size > uint64(db.pages)*uint64(db.pageSize) {
+		return nil, fmt.Errorf("%w: invalid cell", errInvalidDatabase)
+	}
+
+	total := int(size) //nolint:gosec // bounded by the file size
+	start := cell + n + m
+	local := db.localSize(total)
+	if start+local > len(page) {
+		return nil, fmt.Errorf("%w: invalid cell", errInvalidDatabase)
+	}
+	payload := make([]byte, local, total)
+	copy(payload, page[start:])
+	if local == total {
+		return payload, nil
+	}
+
+	if start+local+4 > len(page) {
+		return nil, fmt.Errorf("%w: invalid cell", errInvalidDatabase)
+	}
+	next := binary.BigEndian.Uint32(page[start+local:])
+	// Overflow pages start with the number of the next overflow page
+	for pages := uint32(0); len(payload) < total; pages++ {
+		if next == 0 || pages > db.pages {
+			return nil, fmt.Errorf("%w: overflow chain truncated", errInvalidDatabase)
+		}
+		overflow, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = binary.BigEndian.Uint32(overflow)
+		chunk := overflow[4:db.usable]
+		payload = append(payload, chunk[:min(len(chunk), total-len(payload))]...)
+	}
+	return payload, nil
+}
+
+// localSize returns the number of payload bytes stored on a table leaf page for a payload of the size.
+func (db *sqliteFile) localSize(size int) int {
+	//nolint:mnd // constants of the file format
+	maxLocal := db.usable - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (db.usable-12)*32/255 - 23 //nolint:mnd // constants of the file format
+	local := minLocal + (size-minLocal)%(db.usable-4)
+	if local <= maxLocal {
+		return local
+	}
+	return minLocal
+}
+
+// sqliteRecord decodes a record into nil, int64, float64, string and []byte values.
+func sqliteRecord(payload []byte) ([]any, error) {
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || headerSize < uint64(n) || headerSize > uint64(len(payload)) {
+		return nil, fmt.Errorf("%w: invalid record", errInvalidDatabase)
+	}
+	header, body := payload[n:headerSize], payload[headerSize:]
+
+	var values []any
+	for len(header) > 0 {
+		serialType, m := sqliteVarint(header)
+		if m == 0 {
+			return nil, fmt.Errorf("%w: invalid record", errInvalidDatabase)
+		}
+		header = header[m:]
+
+		size := sqliteSerialSize(serialType)
+		if size > uint64(len(body)) {
+			return nil, fmt.Errorf("%w: invalid record", errInvalidDatabase)
+		}
+		value := body[:size]
+		body = body[size:]
+
+		//nolint:mnd // serial types of the file format
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType <= 6:
+			var i int64
+			for _, b := range value {
+				i = i<<8 | int64(b)
+			}
+			// Sign-extend integers shorter than 8 bytes
+			if shift := 64 - 8*len(value); shift > 0 {
+				i = i << shift >> shift
+			}
+			values = append(values, i)
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(value)))
+		case serialType == 8 || serialType == 9:
+			values = append(values, int64(serialType-8)) //nolint:gosec // 0 or 1
+		case serialType >= 12 && serialType%2 == 0:
+			values = append(values, value)
+		case serialType >= 13:
+			values = append(values, string(value))
+		default:
+			return nil, fmt.Errorf("%w: invalid serial type %d", errInvalidDatabase, serialType)
+		}
+	}
+	return values, nil
+}
+
+// sqliteSerialSize returns the size of a value of the serial type.
+func sqliteSerialSize(serialType uint64) uint64 {
+	//nolint:mnd // serial types of the file format
+	switch {
+	case serialType <= 4:
+		return serialType
+	case serialType == 5:
+		return 6
+	case serialType == 6 || serialType == 7:
+		return 8
+	case serialType >= 12:
+		return (serialType - 12) / 2
+	default:
+		return 0
+	}
+}
+
+// sqliteVarint decodes a big-endian variable-length integer of up to 9 bytes. It returns the number of bytes
+// read, or 0 if the buffer is too short.
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := range min(len(b), 8) { //nolint:mnd // at most 8 bytes with continuation bit
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	if len(b) < 9 { //nolint:mnd // the ninth byte has 8 bits
+		return 0, 0
+	}
+	return v<<8 | uint64(b[8]), 9
+}
+
+// readBDBHashValues calls fn with each value of a BerkeleyDB hash database. Values larger than a page are stored
+// on a chain of overflow pages.
+//
+// See https://github.com/berkeleydb/libdb/blob/master/src/dbinc/db_page.h
+func readBDBHashValues(file *os.File, fn func([]byte)) error {
+	meta := make([]byte, 512) //nolint:mnd // minimum page size
+	if _, err := file.ReadAt(meta, 0); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidDatabase, err)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(meta[12:]) != bdbHashMagic {
+		order = binary.BigEndian
+		if order.Uint32(meta[12:]) != bdbHashMagic {
+			return fmt.Errorf("%w: not a BerkeleyDB hash database", errInvalidDatabase)
+		}
+	}
+	if meta[24] != 0 {
+		return fmt.Errorf("%w: encrypted database", errInvalidDatabase)
+	}
+	pageSize := order.Uint32(meta[20:])
+	if pageSize < 512 || pageSize > math.MaxUint16+1 || pageSize&(pageSize-1) != 0 { //nolint:mnd // page sizes
+		return fmt.Errorf("%w: invalid page size %d", errInvalidDatabase, pageSize)
+	}
+	lastPage := order.Uint32(meta[32:])
+
+	page := make([]byte, pageSize)
+	for n := uint32(1); n <= lastPage; n++ {
+		if _, err := file.ReadAt(page, int64(n)*int64(pageSize)); err != nil {
+			return fmt.Errorf("%w: page %d: %w", errInvalidDatabase, n, err)
+		}
+		if page[25] != bdbPageHash && page[25] != bdbPageHashUnsorted {
+			continue
+		}
+
+		// The index holds the offsets of the items from the end of the page downwards, alternating between keys
+		// and values
+		entries := int(order.Uint16(page[20:]))
+		if bdbPageHeaderSize+2*entries > len(page) {
+			return fmt.Errorf("%w: page %d has too many entries", errInvalidDatabase, n)
+		}
+		for i := 1; i < entries; i += 2 {
+			offset := int(order.Uint16(page[bdbPageHeaderSize+2*i:]))
+			end := int(order.Uint16(page[bdbPageHeaderSize+2*(i-1):]))
+			if offset >= end || end > len(page) {
+				return fmt.Errorf("%w: page %d has an invalid entry", errInvalidDatabase, n)
+			}
+			item := page[offset:end]
+			switch item[0] {
+			case bdbKeyData:
+				fn(bytes.Clone(item[1:]))
+			case bdbOffPage:
+				if len(item) < bdbOffPageSize {
+					return fmt.Errorf("%w: page %d has an invalid entry", errInvalidDatabase, n)
+				}
+				value, err := readBDBOverflow(file, order, pageSize, lastPage, order.Uint32(item[4:]),
+					order.Uint32(item[8:]))
+				if err != nil {
+					return err
+				}
+				fn(value)
+			}
+		}
+	}
+	return nil
+}
+
+// readBDBOverflow reads a value of the length from the chain of overflow pages starting at page n.
+func readBDBOverflow(file *os.File, order binary.ByteOrder, pageSize, lastPage, n, length uint32) ([]byte, error) {
+	if uint64(length) > uint64(lastPage)*uint64(pageSize) {
+		return nil, fmt.Errorf("%w: overflow value too large", errInvalidDatabase)
+	}
+	value := make([]byte, 0, length)
+	page := make([]byte, pageSize)
+	for pages := uint32(0); uint32(len(value)) < length; pages++ { //nolint:gosec // bounded by length
+		if n == 0 || n > lastPage || pages > lastPage {
+			return nil, fmt.Errorf("%w: overflow chain truncated", errInvalidDatabase)
+		}
+		if _, err := file.ReadAt(page, int64(n)*int64(pageSize)); err != nil {
+			return nil, fmt.Errorf("%w: page %d: %w", errInvalidDatabase, n, err)
+		}
+		if page[25] != bdbPageOverflow {
+			return nil, fmt.Errorf("%w: page %d is not an overflow page", errInvalidDatabase, n)
+		}
+		// Overflow pages store the number of bytes used in the offset of the high free space
+		used := int(order.Uint16(page[22:]))
+		if bdbPageHeaderSize+used > len(page) {
+			return nil, fmt.Errorf("%w: page %d has an invalid length", errInvalidDatabase, n)
+		}
+		value = append(value, page[bdbPageHeaderSize:bdbPageHeaderSize+used]...)
+		n = order.Uint32(page[16:])
+	}
+	return value[:length], nil
+}
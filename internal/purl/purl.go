@@ -0,0 +1,252 @@
+// Package purl parses and formats package URLs.
+//
+// See https://github.com/package-url/purl-spec
+package purl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// scheme is the scheme of every package URL.
+const scheme = "pkg:"
+
+// ErrInvalidPurl is returned when a string is not a valid package URL.
+var ErrInvalidPurl = errors.New("invalid purl")
+
+// PURL is a parsed package URL.
+//
+// All components are stored decoded, e.g. the npm namespace of pkg:npm/%40types/node is "@types".
+type PURL struct {
+	// Type is the package type, e.g. "npm" or "maven", always lowercase.
+	Type string
+	// Namespace is the optional name prefix, e.g. the Maven group ID or the npm scope.
+	Namespace string
+	// Name is the package name.
+	Name string
+	// Version is the optional package version.
+	Version string
+	// Qualifiers are the optional extra qualifying data, e.g. "repository_url".
+	Qualifiers map[string]string
+	// Subpath is the optional path inside the package.
+	Subpath string
+}
+
+// Parse parses a package URL.
+func Parse(s string) (PURL, error) {
+	if !strings.HasPrefix(strings.ToLower(s), scheme) {
+		return PURL{}, fmt.Errorf("%w: %q does not start with %q", ErrInvalidPurl, s, scheme)
+	}
+	rest := strings.TrimLeft(s[len(scheme):], "/")
+
+	var p PURL
+
+	// Subpath
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		subpath, err := unescapeSegments(strings.Trim(rest[i+1:], "/"))
+		if err != nil {
+			return PURL{}, fmt.Errorf("%w: subpath: %w", ErrInvalidPurl, err)
+		}
+		p.Subpath = subpath
+		rest = rest[:i]
+	}
+
+	// Qualifiers
+	if i := strings.LastIndex(rest, "?"); i >= 0 {
+		qualifiers, err := parseQualifiers(rest[i+1:])
+		if err != nil {
+			return PURL{}, err
+		}
+		p.Qualifiers = qualifiers
+		rest = rest[:i]
+	}
+
+	// Type
+	typ, rest, found := strings.Cut(rest, "/")
+	if !found || typ == "" {
+		return PURL{}, fmt.Errorf("%w: %q has no type", ErrInvalidPurl, s)
+	}
+	p.Type = strings.ToLower(typ)
+
+	// Version
+	rest = strings.TrimRight(rest, "/")
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		v, err := url.PathUnescape(rest[i+1:])
+		if err != nil {
+			return PURL{}, fmt.Errorf("%w: version: %w", ErrInvalidPurl, err)
+		}
+		p.Version = v
+		rest = rest[:i]
+	}
+
+	// Name and namespace
+	namespace, name := "", rest
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		namespace, name = rest[:i], rest[i+1:]
+	}
+	decodedName, err := url.PathUnescape(name)
+	if err != nil {
+		return PURL{}, fmt.Errorf("%w: name: %w", ErrInvalidPurl, err)
+	}
+	if decodedName == "" {
+		return PURL{}, fmt.Errorf("%w: %q has no name", ErrInvalidPurl, s)
+	}
+	p.Name = decodedName
+	if p.Namespace, err = unescapeSegments(namespace); err != nil {
+		return PURL{}, fmt.Errorf("%w: namespace: %w", ErrInvalidPurl, err)
+	}
+
+	return p, nil
+}
+
+// String formats the package URL in its canonical form.
+func (p PURL) String() string {
+	var b strings.Builder
+	b.WriteString(scheme)
+	b.WriteString(p.Type)
+	b.WriteString("/")
+	if p.Namespace != "" {
+		b.WriteString(escapeSegments(p.Namespace))
+		b.WriteString("/")
+	}
+	b.WriteString(escape(p.Name))
+	if p.Version != "" {
+		b.WriteString("@")
+		b.WriteString(escape(p.Version))
+	}
+	if len(p.Qualifiers) > 0 {
+		keys := make([]string, 0, len(p.Qualifiers))
+		for k := range p.Qualifiers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("?")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString("&")
+			}
+			b.WriteString(k)
+			b.WriteString("=")
+			b.WriteString(escape(p.Qualifiers[k]))
+		}
+	}
+	if p.Subpath != "" {
+		b.WriteString("#")
+		b.WriteString(escapeSegments(p.Subpath))
+	}
+	return b.String()
+}
+
+// FullName returns the namespace and name joined by "/", or just the name if there is no namespace.
+func (p PURL) FullName() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	return p.Namespace + "/" + p.Name
+}
+
+// parseQualifiers parses the "key=value&key=value" qualifier section.
+func parseQualifiers(s string) (map[string]string, error) {
+	qualifiers := map[string]string{}
+	for _, pair := range strings.Split(s, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: qualifier %q: %w", ErrInvalidPurl, key, err)
+		}
+		if decoded != "" {
+			qualifiers[strings.ToLower(key)] = decoded
+		}
+	}
+	return qualifiers, nil
+}
+
+// unescapeSegments decodes each "/"-separated segment.
+func unescapeSegments(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = decoded
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// escapeSegments encodes each "/"-separated segment.
+func escapeSegments(s string) string {
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape percent-encodes a single purl component.
+func escape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// MatchGlob reports whether s matches the glob pattern.
+//
+// "*" matches any sequence of characters (including "/") and "?" matches a single character.
+// Matching is case-sensitive.
+func MatchGlob(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	// star is the position after the last "*" in the pattern, and next the position in s it is retried from
+	var pi, si, star, next int
+	star = -1
+	for si < len(str) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, next = pi+1, si
+			pi++
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case star >= 0:
+			// Let the last "*" match one more character
+			next++
+			pi, si = star, next
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// Match reports whether a raw package URL matches the purl glob pattern, e.g. "pkg:npm/@acme/*".
+//
+// The purl is matched both as written and in decoded form without qualifiers and subpath, so patterns
+// don't have to be percent-encoded: "pkg:npm/@acme/*" matches "pkg:npm/%40acme/ui@1.0.0".
+func Match(pattern, rawPurl string) bool {
+	if rawPurl == "" {
+		return false
+	}
+	if MatchGlob(pattern, rawPurl) {
+		return true
+	}
+
+	parsed, err := Parse(rawPurl)
+	if err != nil {
+		return false
+	}
+	decoded := scheme + parsed.Type + "/" + parsed.FullName()
+	if parsed.Version != "" {
+		decoded += "@" + parsed.Version
+	}
+	return MatchGlob(pattern, decoded)
+}
@@ -0,0 +1,186 @@
+package purl_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// TestParse tests parsing valid package URLs.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  purl.PURL
+	}{
+		{
+			name:  "simple",
+			input: "pkg:npm/lodash@4.17.21",
+			want:  purl.PURL{Type: "npm", Name: "lodash", Version: "4.17.21"},
+		},
+		{
+			name:  "scoped npm",
+			input: "pkg:npm/%40types/node@18.0.0",
+			want:  purl.PURL{Type: "npm", Namespace: "@types", Name: "node", Version: "18.0.0"},
+		},
+		{
+			name:  "maven",
+			input: "pkg:maven/org.springframework/spring-core@6.0.11",
+			want: purl.PURL{
+				Type: "maven", Namespace: "org.springframework", Name: "spring-core", Version: "6.0.11",
+			},
+		},
+		{
+			name:  "golang multi-segment namespace",
+			input: "pkg:golang/github.com/gin-gonic/gin@v1.9.1",
+			want:  purl.PURL{Type: "golang", Namespace: "github.com/gin-gonic", Name: "gin", Version: "v1.9.1"},
+		},
+		{
+			name:  "qualifiers and subpath",
+			input: "pkg:deb/debian/curl@7.50.3-1?arch=i386&distro=jessie#usr/bin",
+			want: purl.PURL{
+				Type:       "deb",
+				Namespace:  "debian",
+				Name:       "curl",
+				Version:    "7.50.3-1",
+				Qualifiers: map[string]string{"arch": "i386", "distro": "jessie"},
+				Subpath:    "usr/bin",
+			},
+		},
+		{
+			name:  "no version",
+			input: "pkg:github/actions/checkout",
+			want:  purl.PURL{Type: "github", Namespace: "actions", Name: "checkout"},
+		},
+		{
+			name:  "uppercase type",
+			input: "pkg:PyPI/requests@2.31.0",
+			want:  purl.PURL{Type: "pypi", Name: "requests", Version: "2.31.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := purl.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParse_Invalid tests parsing invalid package URLs.
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"", "npm/lodash", "pkg:", "pkg:npm", "pkg:npm/", "pkg:npm/%zz"} {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := purl.Parse(input); !errors.Is(err, purl.ErrInvalidPurl) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidPurl", input, err)
+			}
+		})
+	}
+}
+
+// TestPURL_String tests that parsing and formatting round-trips.
+func TestPURL_String(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"pkg:npm/lodash@4.17.21",
+		"pkg:npm/%40types/node@18.0.0",
+		"pkg:golang/github.com/gin-gonic/gin@v1.9.1",
+		"pkg:deb/debian/curl@7.50.3-1?arch=i386&distro=jessie#usr/bin",
+	} {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := purl.Parse(input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := p.String(); got != input {
+				t.Errorf("String() = %q, want %q", got, input)
+			}
+		})
+	}
+}
+
+// TestPURL_FullName tests the FullName method.
+func TestPURL_FullName(t *testing.T) {
+	t.Parallel()
+
+	if got := (purl.PURL{Namespace: "@types", Name: "node"}).FullName(); got != "@types/node" {
+		t.Errorf("FullName() = %q, want @types/node", got)
+	}
+	if got := (purl.PURL{Name: "lodash"}).FullName(); got != "lodash" {
+		t.Errorf("FullName() = %q, want lodash", got)
+	}
+}
+
+// TestMatchGlob tests the MatchGlob function.
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{pattern: "actions", s: "actions", want: true},
+		{pattern: "actions", s: "actions-go", want: false},
+		{pattern: "com.acme*", s: "com.acme.internal", want: true},
+		{pattern: "github.com/acme/*", s: "github.com/acme/tools/sub", want: true},
+		{pattern: "@acme", s: "@other", want: false},
+		{pattern: "v?", s: "v2", want: true},
+		{pattern: "a.b", s: "axb", want: false},
+		{pattern: "*", s: "", want: true},
+		{pattern: "?", s: "", want: false},
+		{pattern: "a*b*c", s: "axbybzc", want: true},
+		{pattern: "a*b*c", s: "axbybz", want: false},
+		{pattern: "*-go", s: "actions-go-go", want: true},
+		{pattern: "pkg:npm/?ber", s: "pkg:npm/über", want: true},
+		{pattern: "Acme*", s: "acme", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := purl.MatchGlob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+// TestMatch tests the Match function.
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		purl    string
+		want    bool
+	}{
+		{pattern: "pkg:npm/@acme/*", purl: "pkg:npm/%40acme/ui@1.0.0", want: true},
+		{pattern: "pkg:npm/@acme/*", purl: "pkg:npm/@acme/ui@1.0.0", want: true},
+		{pattern: "pkg:npm/@acme/*", purl: "pkg:npm/%40types/node@18.0.0", want: false},
+		{pattern: "pkg:npm/left-pad@*", purl: "pkg:npm/left-pad@1.3.0?arch=x64", want: true},
+		{pattern: "pkg:npm/*", purl: "", want: false},
+		{pattern: "pkg:npm/*", purl: "not a purl", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := purl.Match(tt.pattern, tt.purl); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.purl, got, tt.want)
+		}
+	}
+}
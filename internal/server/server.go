@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/boringbin/sbomlicense/internal/cache"
 	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/policy"
 	"github.com/boringbin/sbomlicense/internal/provider"
 	"github.com/boringbin/sbomlicense/internal/sbom"
 )
@@ -22,6 +24,13 @@ const (
 	enrichmentTimeout = 10 * time.Minute
 )
 
+const (
+	// healthOK is the health status when all providers are available.
+	healthOK = "ok"
+	// healthDegraded is the health status when a provider is unavailable.
+	healthDegraded = "degraded"
+)
+
 // Server is the HTTP server for the SBOM enrichment daemon.
 type Server struct {
 	provider           provider.Provider
@@ -30,6 +39,15 @@ type Server struct {
 	defaultParallelism int
 	cacheTTL           time.Duration
 	version            string
+	policy             *policy.Policy
+	curations          provider.Curator
+	metrics            []MetricsSource
+}
+
+// MetricsSource is a component whose metrics are served on GET /metrics, such as a provider.RateLimiter.
+type MetricsSource interface {
+	// WriteMetrics writes the metrics in the Prometheus text format.
+	WriteMetrics(w io.Writer) error
 }
 
 // enrichRequest is the request body for POST /enrich.
@@ -40,12 +58,38 @@ type enrichRequest struct {
 	//
 	// If <= 0, defaults to 1 (sequential processing).
 	Parallelism int `json:"parallelism,omitempty"`
+	// OutputFormat is the format to convert the enriched SBOM to ("spdx-2.3" or "cyclonedx-1.6").
+	//
+	// If empty, the enriched SBOM keeps the format of the input.
+	OutputFormat string `json:"outputFormat,omitempty"`
+	// LicenseTexts is the encoding of the full license texts to embed ("plain" or "base64").
+	//
+	// If empty, no license texts are embedded.
+	LicenseTexts string `json:"licenseTexts,omitempty"`
+	// Filter selects the items that are enriched.
+	//
+	// If nil, all items are enriched.
+	Filter *enricher.Filter `json:"filter,omitempty"`
 }
 
 // enrichResponse is the response body for POST /enrich.
 type enrichResponse struct {
 	// SBOM is the enriched SBOM file.
 	SBOM json.RawMessage `json:"sbom"`
+	// Report is the per-item result of the enrichment.
+	Report *enricher.Report `json:"report"`
+	// Policy is the result of checking the enriched SBOM against the license policy.
+	//
+	// It is omitted if the server has no policy.
+	Policy *policy.Result `json:"policy,omitempty"`
+}
+
+// healthResponse is the response body for GET /health.
+type healthResponse struct {
+	// Status is "ok", or "degraded" if the circuit of a provider is open.
+	Status string `json:"status"`
+	// Providers are the states of the circuit breakers of the providers.
+	Providers []provider.BreakerStatus `json:"providers"`
 }
 
 // errorResponse is the error response body.
@@ -73,11 +117,31 @@ func NewServer(
 	}
 }
 
+// SetPolicy sets the license policy the enriched SBOMs are checked against.
+//
+// A nil policy disables the check.
+func (s *Server) SetPolicy(p *policy.Policy) {
+	s.policy = p
+}
+
+// SetCurations sets the manual license curations, consulted before the cache and the provider.
+//
+// A nil curator disables curations.
+func (s *Server) SetCurations(c provider.Curator) {
+	s.curations = c
+}
+
+// AddMetrics adds a source of the metrics served on GET /metrics.
+func (s *Server) AddMetrics(source MetricsSource) {
+	s.metrics = append(s.metrics, source)
+}
+
 // Handler returns an http.Handler for the server.
 func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/enrich", s.handleEnrich)
 	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/metrics", s.handleMetrics)
 	return mux
 }
 
@@ -118,7 +182,27 @@ func (s *Server) handleEnrich(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.logger.Info("processing SBOM", "format", format)
+	// Validate output format
+	outputFormat, err := enricher.ParseOutputFormat(req.OutputFormat)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Validate license text encoding
+	licenseTexts, err := enricher.ParseLicenseTextEncoding(req.LicenseTexts)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Validate filter
+	if filterErr := req.Filter.Validate(); filterErr != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid filter: %v", filterErr))
+		return
+	}
+
+	s.logger.Info("processing SBOM", "format", format, "outputFormat", string(outputFormat))
 
 	// Determine parallelism
 	parallelism := req.Parallelism
@@ -140,10 +224,16 @@ func (s *Server) handleEnrich(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Enrich the SBOM
+	report := &enricher.Report{}
 	enriched, err := licenseEnrichmentService.Enrich(ctx, enricher.Options{
-		SBOM:        req.SBOM,
-		Logger:      s.logger,
-		Parallelism: parallelism,
+		SBOM:         req.SBOM,
+		Logger:       s.logger,
+		Parallelism:  parallelism,
+		OutputFormat: outputFormat,
+		LicenseTexts: licenseTexts,
+		Filter:       req.Filter,
+		Curations:    s.curations,
+		Report:       report,
 	})
 	if err != nil {
 		s.logger.Error("failed to enrich SBOM", "error", err)
@@ -151,9 +241,26 @@ func (s *Server) handleEnrich(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response := enrichResponse{SBOM: enriched, Report: report}
+
+	// Check the enriched SBOM against the license policy
+	if s.policy != nil {
+		inventory, inventoryErr := enricher.ReadInventory(enriched)
+		if inventoryErr != nil {
+			s.logger.Error("failed to read enriched SBOM", "error", inventoryErr)
+			s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("policy check failed: %v", inventoryErr))
+			return
+		}
+		response.Policy = s.policy.Evaluate(inventory.Items)
+		s.logger.Info("checked license policy",
+			"passed", response.Policy.Passed,
+			"review", response.Policy.Review,
+			"denied", response.Policy.Denied,
+		)
+	}
+
 	// Write response
 	w.Header().Set("Content-Type", "application/json")
-	response := enrichResponse{SBOM: enriched}
 	if encodeErr := json.NewEncoder(w).Encode(response); encodeErr != nil {
 		s.logger.Error("failed to encode response", "error", encodeErr)
 	}
@@ -167,12 +274,39 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The daemon is degraded, but still serves cached licenses, while a provider circuit is not closed
+	response := healthResponse{Status: healthOK, Providers: []provider.BreakerStatus{}}
+	for _, breaker := range provider.Breakers(s.provider) {
+		status := breaker.Status()
+		if status.State != provider.BreakerClosed {
+			response.Status = healthDegraded
+		}
+		response.Providers = append(response.Providers, status)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode("OK"); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		s.logger.Error("failed to encode health response", "error", err)
 	}
 }
 
+// handleMetrics handles GET /metrics requests.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	// Only accept GET
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "only GET method is allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, source := range s.metrics {
+		if err := source.WriteMetrics(w); err != nil {
+			s.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+	}
+}
+
 // writeError writes an error response.
 func (s *Server) writeError(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/boringbin/sbomlicense/internal/cache"
+	"github.com/boringbin/sbomlicense/internal/policy"
 	"github.com/boringbin/sbomlicense/internal/provider"
 	"github.com/boringbin/sbomlicense/internal/server"
 )
@@ -182,13 +183,68 @@ func TestServer_HandleHealth_Success(t *testing.T) {
 		t.Errorf("HandleHealth() Content-Type = %q, want %q", contentType, "application/json")
 	}
 
-	// Verify response body is "OK"
-	var response string
+	// Verify response body is "ok" without circuit breakers
+	var response struct {
+		Status    string                   `json:"status"`
+		Providers []provider.BreakerStatus `json:"providers"`
+	}
 	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
 		t.Errorf("HandleHealth() response not valid JSON: %v", err)
 	}
-	if response != "OK" {
-		t.Errorf("HandleHealth() response = %q, want %q", response, "OK")
+	if response.Status != "ok" || len(response.Providers) != 0 {
+		t.Errorf("HandleHealth() response = %+v, want status %q", response, "ok")
+	}
+}
+
+// TestServer_HandleHealth_Breaker tests that the health check shows the circuit breakers of the providers.
+func TestServer_HandleHealth_Breaker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+		wantState  provider.BreakerState
+	}{
+		{name: "closed", err: provider.ErrLicenseNotFound, wantStatus: "ok", wantState: provider.BreakerClosed},
+		{name: "open", err: provider.ErrUnavailable, wantStatus: "degraded", wantState: provider.BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			breaker := provider.NewBreaker(&mockProvider{err: tt.err}, provider.BreakerOptions{
+				Name:      "ecosystems",
+				Threshold: 1,
+				Cooldown:  time.Hour,
+			})
+			_, _ = breaker.Get(context.Background(), "pkg:npm/test@1.0.0")
+			guard, err := provider.NewGuard(breaker, nil)
+			if err != nil {
+				t.Fatalf("NewGuard() error = %v", err)
+			}
+
+			srv := server.NewServer(guard, newMockCache(), testLogger(), 10, 0*time.Hour, "1.0.0")
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("HandleHealth() status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var response struct {
+				Status    string                   `json:"status"`
+				Providers []provider.BreakerStatus `json:"providers"`
+			}
+			if err = json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("HandleHealth() response not valid JSON: %v", err)
+			}
+			if response.Status != tt.wantStatus || len(response.Providers) != 1 ||
+				response.Providers[0].Name != "ecosystems" || response.Providers[0].State != tt.wantState {
+				t.Errorf("HandleHealth() response = %+v, want %s with %s circuit", response, tt.wantStatus, tt.wantState)
+			}
+		})
 	}
 }
 
@@ -226,6 +282,35 @@ func TestServer_HandleHealth_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+// TestServer_HandleMetrics tests that the metrics of the sources are served.
+func TestServer_HandleMetrics(t *testing.T) {
+	t.Parallel()
+
+	limiter := provider.NewRateLimiter(provider.RateLimiterOptions{Name: "ecosystems", Rate: 10})
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	srv := server.NewServer(&mockProvider{}, newMockCache(), testLogger(), 10, 0*time.Hour, "1.0.0")
+	srv.AddMetrics(limiter)
+	handler := srv.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("HandleMetrics() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	want := `sbomlicense_ratelimit_requests_total{limiter="ecosystems"} 1` + "\n"
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("HandleMetrics() body = %q, want it to contain %q", rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("HandleMetrics() with POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
 // TestServer_HandleEnrich_MethodNotAllowed tests non-POST methods return 405.
 func TestServer_HandleEnrich_MethodNotAllowed(t *testing.T) {
 	t.Parallel()
@@ -840,3 +925,234 @@ func TestServer_E2E_WithRealTestdata(t *testing.T) {
 		})
 	}
 }
+
+// TestServer_HandleEnrich_OutputFormat tests converting the enriched SBOM to another format.
+func TestServer_HandleEnrich_OutputFormat(t *testing.T) {
+	t.Parallel()
+
+	testdata, err := os.ReadFile("../../testdata/example-cyclonedx.json")
+	if err != nil {
+		t.Skipf("skipping test: testdata not available: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		outputFormat string
+		wantStatus   int
+		wantMarker   string
+	}{
+		{
+			name:         "convert to SPDX",
+			outputFormat: "spdx-2.3",
+			wantStatus:   http.StatusOK,
+			wantMarker:   `"spdxVersion":"SPDX-2.3"`,
+		},
+		{
+			name:         "unsupported format",
+			outputFormat: "xml",
+			wantStatus:   http.StatusBadRequest,
+			wantMarker:   "unsupported output format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := server.NewServer(&mockProvider{license: "MIT"}, newMockCache(), testLogger(), 1, 0, "1.0.0")
+
+			reqJSON, _ := json.Marshal(map[string]interface{}{
+				"sbom":         json.RawMessage(testdata),
+				"outputFormat": tt.outputFormat,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/enrich", bytes.NewReader(reqJSON))
+			rec := httptest.NewRecorder()
+
+			srv.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("HandleEnrich() status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantMarker) {
+				t.Errorf("HandleEnrich() body = %s, want to contain %s", rec.Body.String(), tt.wantMarker)
+			}
+		})
+	}
+}
+
+// TestServer_HandleEnrich_LicenseTexts tests embedding license texts into the enriched SBOM.
+func TestServer_HandleEnrich_LicenseTexts(t *testing.T) {
+	t.Parallel()
+
+	testdata, err := os.ReadFile("../../testdata/example-cyclonedx.json")
+	if err != nil {
+		t.Skipf("skipping test: testdata not available: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		licenseTexts string
+		wantStatus   int
+		wantMarker   string
+	}{
+		{
+			name:         "plain",
+			licenseTexts: "plain",
+			wantStatus:   http.StatusOK,
+			wantMarker:   `"contentType":"text/plain"`,
+		},
+		{
+			name:         "unsupported encoding",
+			licenseTexts: "gzip",
+			wantStatus:   http.StatusBadRequest,
+			wantMarker:   "unsupported license text encoding",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := server.NewServer(&mockProvider{license: "MIT"}, newMockCache(), testLogger(), 1, 0, "1.0.0")
+
+			reqJSON, _ := json.Marshal(map[string]interface{}{
+				"sbom":         json.RawMessage(testdata),
+				"licenseTexts": tt.licenseTexts,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/enrich", bytes.NewReader(reqJSON))
+			rec := httptest.NewRecorder()
+
+			srv.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("HandleEnrich() status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantMarker) {
+				t.Errorf("HandleEnrich() body = %s, want to contain %s", rec.Body.String(), tt.wantMarker)
+			}
+		})
+	}
+}
+
+// TestServer_HandleEnrich_FilterAndReport tests per-request filters and the report in the response.
+func TestServer_HandleEnrich_FilterAndReport(t *testing.T) {
+	t.Parallel()
+
+	testdata, err := os.ReadFile("../../testdata/example-cyclonedx.json")
+	if err != nil {
+		t.Skipf("skipping test: testdata not available: %v", err)
+	}
+
+	mockProv := &mockProvider{license: "MIT"}
+	srv := server.NewServer(mockProv, newMockCache(), testLogger(), 1, 0, "1.0.0")
+
+	reqJSON, _ := json.Marshal(map[string]interface{}{
+		"sbom":   json.RawMessage(testdata),
+		"filter": map[string]interface{}{"exclude": []map[string]interface{}{{"purlTypes": []string{"npm"}}}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/enrich", bytes.NewReader(reqJSON))
+	rec := httptest.NewRecorder()
+
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleEnrich() status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Report struct {
+			Items []struct {
+				Purl   string `json:"purl"`
+				Status string `json:"status"`
+			} `json:"items"`
+		} `json:"report"`
+	}
+	if unmarshalErr := json.Unmarshal(rec.Body.Bytes(), &response); unmarshalErr != nil {
+		t.Fatalf("HandleEnrich() response not valid JSON: %v", unmarshalErr)
+	}
+	if len(response.Report.Items) == 0 {
+		t.Fatal("HandleEnrich() response report has no items")
+	}
+	for _, item := range response.Report.Items {
+		wantFiltered := strings.HasPrefix(item.Purl, "pkg:npm/")
+		if (item.Status == "skipped (filtered)") != wantFiltered {
+			t.Errorf("item %s status = %q", item.Purl, item.Status)
+		}
+	}
+}
+
+// TestServer_HandleEnrich_InvalidFilter tests that an invalid filter is rejected.
+func TestServer_HandleEnrich_InvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	srv := server.NewServer(&mockProvider{}, newMockCache(), testLogger(), 1, 0, "1.0.0")
+
+	body := `{"sbom": {"bomFormat": "CycloneDX", "specVersion": "1.5"}, "filter": {"exclude": [{"name": "("}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("HandleEnrich() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// TestServer_HandleEnrich_Policy tests that the policy result is included in the response.
+func TestServer_HandleEnrich_Policy(t *testing.T) {
+	t.Parallel()
+
+	p, err := policy.Parse([]byte("allow: [MIT]\ndeny: [GPL-3.0-only]\n"))
+	if err != nil {
+		t.Fatalf("policy.Parse() error = %v", err)
+	}
+
+	srv := server.NewServer(&mockProvider{license: "GPL-3.0-only"}, newMockCache(), testLogger(), 1, 0, "1.0.0")
+	srv.SetPolicy(p)
+
+	body := `{"sbom": {"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+		{"bom-ref": "a", "name": "a", "purl": "pkg:npm/a@1.0.0"},
+		{"bom-ref": "b", "name": "b", "purl": "pkg:npm/b@1.0.0", "licenses": [{"license": {"id": "MIT"}}]}
+	]}}`
+	req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HandleEnrich() status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Policy *policy.Result `json:"policy"`
+	}
+	if unmarshalErr := json.Unmarshal(rec.Body.Bytes(), &response); unmarshalErr != nil {
+		t.Fatalf("HandleEnrich() response not valid JSON: %v", unmarshalErr)
+	}
+	if response.Policy == nil {
+		t.Fatal("HandleEnrich() response has no policy result")
+	}
+	if response.Policy.Passed || response.Policy.Denied != 1 || response.Policy.Allowed != 1 {
+		t.Errorf("HandleEnrich() policy = %+v, want one denied and one allowed", response.Policy)
+	}
+	if len(response.Policy.Findings) != 1 || response.Policy.Findings[0].ID != "a" {
+		t.Errorf("HandleEnrich() policy findings = %+v, want component a", response.Policy.Findings)
+	}
+}
+
+// TestServer_HandleEnrich_NoPolicy tests that the policy result is omitted without a policy.
+func TestServer_HandleEnrich_NoPolicy(t *testing.T) {
+	t.Parallel()
+
+	srv := server.NewServer(&mockProvider{license: "MIT"}, newMockCache(), testLogger(), 1, 0, "1.0.0")
+
+	body := `{"sbom": {"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}}`
+	req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	srv.Handler().ServeHTTP(rec, req)
+
+	if strings.Contains(rec.Body.String(), `"policy"`) {
+		t.Errorf("HandleEnrich() response = %s, want no policy result", rec.Body.String())
+	}
+}
@@ -0,0 +1,173 @@
+// Package worksheet exports the components of an SBOM without license information to a CSV worksheet and
+// imports the completed worksheet.
+//
+// The worksheet has the columns name, version, purl, homepage and license, with the license column left blank
+// for reviewers to fill in. Completed worksheets are turned into curations, which can be applied to the SBOM
+// or kept as a curations file.
+package worksheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/boringbin/sbomlicense/internal/curation"
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/license"
+	"github.com/boringbin/sbomlicense/internal/purl"
+)
+
+// ErrInvalidWorksheet is returned when a worksheet cannot be read or contains invalid licenses.
+var ErrInvalidWorksheet = errors.New("invalid worksheet")
+
+const (
+	columnName     = "name"
+	columnVersion  = "version"
+	columnPurl     = "purl"
+	columnHomepage = "homepage"
+	columnLicense  = "license"
+)
+
+// Row is a component of the worksheet.
+type Row struct {
+	// Name is the package name.
+	Name string
+	// Version is the package version.
+	Version string
+	// Purl is the package URL, if any.
+	Purl string
+	// Homepage is the package homepage, if any.
+	Homepage string
+	// License is the license expression filled in by the reviewer, or empty if not yet known.
+	License string
+}
+
+// Export returns a row for every component of the inventory without license information. The root component is
+// not included.
+func Export(inventory *enricher.Inventory) []Row {
+	var rows []Row
+	for _, item := range inventory.Items {
+		if item.License != "" {
+			continue
+		}
+		rows = append(rows, Row{
+			Name:     item.Name,
+			Version:  item.Version,
+			Purl:     item.Purl,
+			Homepage: item.Homepage,
+		})
+	}
+	return rows
+}
+
+// Write writes the rows as CSV with a header line.
+func Write(w io.Writer, rows []Row) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{columnName, columnVersion, columnPurl, columnHomepage, columnLicense}}
+	for _, row := range rows {
+		records = append(records, []string{row.Name, row.Version, row.Purl, row.Homepage, row.License})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("write worksheet: %w", err)
+	}
+	return nil
+}
+
+// Read reads a completed worksheet and validates the licenses filled in.
+//
+// Columns are identified by the header line, so they may be reordered and extra columns are ignored; the purl
+// and license columns are required. Rows with a blank license are returned as is. All invalid licenses are
+// reported together with their line numbers.
+func Read(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWorksheet, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header line", ErrInvalidWorksheet)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	for _, required := range []string{columnPurl, columnLicense} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", ErrInvalidWorksheet, required)
+		}
+	}
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var (
+		rows []Row
+		errs []error
+	)
+	for i, record := range records[1:] {
+		row := Row{
+			Name:     field(record, columnName),
+			Version:  field(record, columnVersion),
+			Purl:     field(record, columnPurl),
+			Homepage: field(record, columnHomepage),
+			License:  field(record, columnLicense),
+		}
+		if row.License != "" {
+			if _, parseErr := license.Parse(row.License); parseErr != nil {
+				line := i + 2 //nolint:mnd // line numbers start at 1 and the header is the first line
+				errs = append(errs, fmt.Errorf("line %d (%s): %w", line, row.Name, parseErr))
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWorksheet, errors.Join(errs...))
+	}
+	return rows, nil
+}
+
+// Curations converts the rows with a license into curations of the exact package versions, attributed to the
+// reviewer and documented with the comment. It also returns the rows with a license that cannot be curated
+// because they have no valid purl.
+func Curations(rows []Row, reviewer, comment string) (*curation.File, []Row) {
+	var (
+		file      curation.File
+		unmatched []Row
+		seenPurls = make(map[string]bool)
+	)
+	for _, row := range rows {
+		if row.License == "" {
+			continue
+		}
+		parsed, err := purl.Parse(row.Purl)
+		if err != nil {
+			unmatched = append(unmatched, row)
+			continue
+		}
+
+		// The decoded purl without qualifiers is easier to read and matches the purl as written in the SBOM
+		pattern := "pkg:" + parsed.Type + "/" + parsed.FullName()
+		if parsed.Version != "" {
+			pattern += "@" + parsed.Version
+		}
+		if seenPurls[pattern] {
+			continue
+		}
+		seenPurls[pattern] = true
+		file.Curations = append(file.Curations, curation.Curation{
+			Purl:     pattern,
+			License:  row.License,
+			Comment:  comment,
+			Reviewer: reviewer,
+		})
+	}
+	return &file, unmatched
+}
@@ -0,0 +1,138 @@
+package worksheet_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/boringbin/sbomlicense/internal/enricher"
+	"github.com/boringbin/sbomlicense/internal/worksheet"
+)
+
+// TestExport_RoundTrip tests that exported rows are written and read back unchanged.
+func TestExport_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inventory := &enricher.Inventory{
+		Root: &enricher.InventoryItem{Name: "app"},
+		Items: []enricher.InventoryItem{
+			{Name: "a", Version: "1.0.0", Purl: "pkg:npm/a@1.0.0", License: "MIT"},
+			{Name: "b", Version: "2.0.0", Purl: "pkg:npm/b@2.0.0", Homepage: "https://b.example.com"},
+			{Name: "c, the library", Version: "3.0.0"},
+		},
+	}
+
+	rows := worksheet.Export(inventory)
+	want := []worksheet.Row{
+		{Name: "b", Version: "2.0.0", Purl: "pkg:npm/b@2.0.0", Homepage: "https://b.example.com"},
+		{Name: "c, the library", Version: "3.0.0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("Export() = %+v, want %+v", rows, want)
+	}
+
+	var buf bytes.Buffer
+	if err := worksheet.Write(&buf, rows); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "name,version,purl,homepage,license\n") {
+		t.Errorf("Write() = %q, want header line", buf.String())
+	}
+
+	got, err := worksheet.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+}
+
+// TestRead tests reading completed worksheets.
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		input       string
+		wantLicense []string
+		wantErr     string
+	}{
+		{
+			name:        "reordered and extra columns",
+			input:       "License,notes,PURL\nMIT,checked,pkg:npm/a@1.0.0\n,,pkg:npm/b@2.0.0\n",
+			wantLicense: []string{"MIT", ""},
+		},
+		{
+			name:    "invalid licenses",
+			input:   "name,purl,license\na,pkg:npm/a,MIT AND\nb,pkg:npm/b,Apache-2.0\nc,pkg:npm/c,(MIT\n",
+			wantErr: "line 2 (a)",
+		},
+		{name: "missing license column", input: "name,purl\na,pkg:npm/a\n", wantErr: `missing "license" column`},
+		{name: "empty", input: "", wantErr: "missing header line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows, err := worksheet.Read(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if !errors.Is(err, worksheet.ErrInvalidWorksheet) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Read() error = %v, want %v containing %q", err, worksheet.ErrInvalidWorksheet, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			var licenses []string
+			for _, row := range rows {
+				licenses = append(licenses, row.License)
+			}
+			if !reflect.DeepEqual(licenses, tt.wantLicense) {
+				t.Errorf("Read() licenses = %q, want %q", licenses, tt.wantLicense)
+			}
+		})
+	}
+}
+
+// TestRead_ReportsAllErrors tests that every invalid license is reported at once.
+func TestRead_ReportsAllErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := worksheet.Read(strings.NewReader("name,purl,license\na,pkg:npm/a,MIT AND\nb,pkg:npm/b,(MIT\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2 (a)") || !strings.Contains(err.Error(), "line 3 (b)") {
+		t.Errorf("Read() error = %v, want errors for lines 2 and 3", err)
+	}
+}
+
+// TestCurations tests converting completed rows into curations.
+func TestCurations(t *testing.T) {
+	t.Parallel()
+
+	rows := []worksheet.Row{
+		{Name: "ui", Purl: "pkg:npm/%40acme/ui@1.0.0?repository_url=https://npm.acme.com", License: "MIT"},
+		{Name: "ui", Purl: "pkg:npm/%40acme/ui@1.0.0", License: "MIT"},
+		{Name: "b", Purl: "pkg:npm/b@2.0.0"},
+		{Name: "c", Version: "3.0.0", License: "Apache-2.0"},
+	}
+
+	file, unmatched := worksheet.Curations(rows, "jane@example.com", "Imported from worksheet")
+
+	if len(file.Curations) != 1 {
+		t.Fatalf("Curations() = %+v, want 1 curation", file.Curations)
+	}
+	c := file.Curations[0]
+	if c.Purl != "pkg:npm/@acme/ui@1.0.0" || c.License != "MIT" || c.Reviewer != "jane@example.com" {
+		t.Errorf("Curations() curation = %+v", c)
+	}
+	if _, ok := file.Find("pkg:npm/%40acme/ui@1.0.0?repository_url=https://npm.acme.com"); !ok {
+		t.Error("Curations() curation does not match the purl of the worksheet")
+	}
+	if len(unmatched) != 1 || unmatched[0].Name != "c" {
+		t.Errorf("Curations() unmatched = %+v, want row c", unmatched)
+	}
+}